@@ -0,0 +1,186 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// accessTokenPrefix marks personal access tokens, telling them apart from passwords
+// and the signed tokens of git-lfs-authenticate
+const accessTokenPrefix = "lfs_"
+
+// newAccessToken creates a personal access token for user. The token itself is only
+// returned here, the meta store keeps its hash.
+func newAccessToken(user, name, scope string, projects []string) (*MetaToken, string, error) {
+	if scope != "read" && scope != "write" {
+		return nil, "", errInvalidScope
+	}
+	b := make([]byte, 20)
+	if _, err := rand.Read(b); err != nil {
+		return nil, "", err
+	}
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return nil, "", err
+	}
+
+	token := accessTokenPrefix + hex.EncodeToString(b)
+	meta := &MetaToken{
+		Id:        hex.EncodeToString(id),
+		User:      user,
+		Name:      name,
+		Hash:      hashAccessToken(token),
+		Scope:     scope,
+		Projects:  projects,
+		CreatedAt: time.Now().UTC(),
+	}
+	return meta, token, nil
+}
+
+func hashAccessToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func isAccessToken(s string) bool {
+	return strings.HasPrefix(s, accessTokenPrefix)
+}
+
+// Allows reports whether the token may perform action on project
+func (t *MetaToken) Allows(project, action string) bool {
+	if action == "push" && t.Scope != "write" {
+		return false
+	}
+	if len(t.Projects) == 0 {
+		return true
+	}
+	for _, p := range t.Projects {
+		if p == project {
+			return true
+		}
+	}
+	return false
+}
+
+// splitProjects reads a comma separated list of namespace/repo
+func splitProjects(s string) []string {
+	var projects []string
+	for _, p := range strings.Split(s, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			projects = append(projects, p)
+		}
+	}
+	return projects
+}
+
+// addTokens lets users manage their own personal access tokens, authenticated with their
+// password
+func (a *App) addTokens(r *mux.Router) {
+	r.HandleFunc("/tokens", a.OwnTokensHandler).Methods("GET")
+	r.HandleFunc("/tokens", a.CreateOwnTokenHandler).Methods("POST")
+	r.HandleFunc("/tokens/{id}", a.DeleteOwnTokenHandler).Methods("DELETE")
+}
+
+// OwnTokensHandler lists the tokens of the authenticated user
+func (a *App) OwnTokensHandler(w http.ResponseWriter, r *http.Request) {
+	user, ok := a.tokenOwner(w, r)
+	if !ok {
+		return
+	}
+	tokens, err := a.metaStore.Tokens(user)
+	if err != nil {
+		writeStoreError(w, r, err)
+		return
+	}
+	if tokens == nil {
+		tokens = []*MetaToken{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tokens)
+	logRequest(r, 200)
+}
+
+// CreateOwnTokenHandler creates a token for the authenticated user from the name, scope
+// and projects form values
+func (a *App) CreateOwnTokenHandler(w http.ResponseWriter, r *http.Request) {
+	user, ok := a.tokenOwner(w, r)
+	if !ok {
+		return
+	}
+	token, secret, err := newAccessToken(user, r.FormValue("name"), r.FormValue("scope"), splitProjects(r.FormValue("projects")))
+	if err != nil {
+		writeStatusMessage(w, r, 422, err.Error())
+		return
+	}
+	if err := a.metaStore.AddToken(token); err != nil {
+		writeStoreError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(201)
+	json.NewEncoder(w).Encode(&createdToken{MetaToken: token, Token: secret})
+	logRequest(r, 201)
+}
+
+// DeleteOwnTokenHandler revokes a token of the authenticated user, the tokens of others
+// are not found
+func (a *App) DeleteOwnTokenHandler(w http.ResponseWriter, r *http.Request) {
+	user, ok := a.tokenOwner(w, r)
+	if !ok {
+		return
+	}
+	tokens, err := a.metaStore.Tokens(user)
+	if err != nil {
+		writeStoreError(w, r, err)
+		return
+	}
+	id := mux.Vars(r)["id"]
+	for _, t := range tokens {
+		if t.Id != id {
+			continue
+		}
+		if err := a.metaStore.DeleteToken(id); err != nil {
+			writeStoreError(w, r, err)
+			return
+		}
+		writeStatus(w, r, 200)
+		return
+	}
+	writeStatusMessage(w, r, 404, errTokenNotFound.Error())
+}
+
+// tokenOwner authenticates a request to manage tokens. Tokens can't manage tokens,
+// a read token could otherwise create a write token.
+// Writes a 401, 403 or 503 response and returns false when not allowed.
+func (a *App) tokenOwner(w http.ResponseWriter, r *http.Request) (string, bool) {
+	id, err := a.resolveIdentity(unpackVars(r))
+	if isUnavailable(err) {
+		writeUnavailable(w, r, err)
+		return "", false
+	}
+	if err != nil || id.User == "" {
+		requireAuth(w, r)
+		return "", false
+	}
+	if id.Token != nil || id.Claims != nil {
+		writeStatusMessage(w, r, 403, "Tokens are managed with a password")
+		return "", false
+	}
+	return id.User, true
+}
+
+// writeStoreError answers a failed meta store call, with a 503 when it was unavailable
+func writeStoreError(w http.ResponseWriter, r *http.Request, err error) {
+	if isUnavailable(err) {
+		writeUnavailable(w, r, err)
+		return
+	}
+	writeStatusMessage(w, r, 500, err.Error())
+}
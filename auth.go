@@ -0,0 +1,451 @@
+package main
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Identity is who a request was authenticated as. Anonymous requests to a public
+// server have an empty User.
+type Identity struct {
+	User   string
+	Token  *MetaToken   // set when a personal access token was used
+	Claims *TokenClaims // set when a git-lfs-authenticate token was used
+}
+
+// Authenticator checks a username and password. Providers return false for users
+// they don't know, errors are reserved for failing to reach the provider.
+type Authenticator interface {
+	Authenticate(user, password string) (bool, error)
+}
+
+// PasswordChecker is implemented by meta stores that keep local users
+type PasswordChecker interface {
+	CheckPassword(user, password string) (bool, error)
+	Users() ([]*MetaUser, error)
+}
+
+// UserFinder is implemented by providers that can look a user up without its password,
+// the user service can't
+type UserFinder interface {
+	FindUser(user string) (bool, error)
+}
+
+// authChain tries each provider in turn until one accepts the credentials
+type authChain []Authenticator
+
+func (c authChain) Authenticate(user, password string) (bool, error) {
+	var lastErr error
+	for _, a := range c {
+		ok, err := a.Authenticate(user, password)
+		if err != nil {
+			logger.Log(kv{"fn": "authChain.Authenticate", "provider": fmt.Sprintf("%T", a), "error": err.Error()})
+			lastErr = err
+			continue
+		}
+		if ok {
+			return true, nil
+		}
+	}
+	return false, lastErr
+}
+
+// FindUser reports whether any provider that can look users up knows user
+func (c authChain) FindUser(user string) (bool, error) {
+	var lastErr error
+	for _, a := range c {
+		finder, ok := a.(UserFinder)
+		if !ok {
+			continue
+		}
+		found, err := finder.FindUser(user)
+		if err != nil {
+			logger.Log(kv{"fn": "authChain.FindUser", "provider": fmt.Sprintf("%T", a), "error": err.Error()})
+			lastErr = err
+			continue
+		}
+		if found {
+			return true, nil
+		}
+	}
+	return false, lastErr
+}
+
+// newAuthenticator builds the chain of providers named in Config.AuthProviders, e.g.
+// "local, ldap". local checks the users in the meta store, htpasswd the users in
+// Config.HtpasswdFile and userservice asks the user service. Without a list, LDAP is
+// used when enabled, otherwise the local users of meta stores that have them.
+func newAuthenticator(names string, metaStore GenericMetaStore) (Authenticator, error) {
+	var chain authChain
+	if strings.TrimSpace(names) == "" {
+		if _, local := metaStore.(PasswordChecker); Config.Ldap.Enabled || !local {
+			names = "ldap"
+		} else {
+			names = "local"
+		}
+	}
+
+	for _, name := range strings.Split(names, ",") {
+		switch strings.TrimSpace(name) {
+		case "local":
+			store, ok := metaStore.(PasswordChecker)
+			if !ok {
+				return nil, fmt.Errorf("The %s meta store has no local users", Config.BackingStore)
+			}
+			chain = append(chain, &localAuthenticator{store})
+		case "ldap":
+			chain = append(chain, &ldapAuthenticator{})
+		case "htpasswd":
+			chain = append(chain, newHtpasswdAuthenticator(Config.HtpasswdFile))
+		case "userservice":
+			chain = append(chain, &userServiceAuthenticator{})
+		default:
+			return nil, fmt.Errorf("Unknown authenticator %q", name)
+		}
+	}
+	return chain, nil
+}
+
+// authCache remembers successful credential checks for ttl, so bcrypt and LDAP aren't
+// hit on every request. Entries are keyed on a hash of the credentials, failures are
+// never cached.
+type authCache struct {
+	next    Authenticator
+	ttl     time.Duration
+	mu      sync.Mutex
+	entries map[string]*authCacheEntry
+}
+
+type authCacheEntry struct {
+	user    string
+	expires time.Time
+}
+
+func newAuthCache(next Authenticator, ttl time.Duration) *authCache {
+	return &authCache{next: next, ttl: ttl, entries: make(map[string]*authCacheEntry)}
+}
+
+func (c *authCache) Authenticate(user, password string) (bool, error) {
+	key := authCacheKey(user, password)
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	if ok && time.Now().After(entry.expires) {
+		delete(c.entries, key)
+		ok = false
+	}
+	c.mu.Unlock()
+	if ok {
+		return true, nil
+	}
+
+	ok, err := c.next.Authenticate(user, password)
+	if ok && err == nil {
+		c.mu.Lock()
+		c.entries[key] = &authCacheEntry{user: user, expires: time.Now().Add(c.ttl)}
+		c.mu.Unlock()
+	}
+	return ok, err
+}
+
+func (c *authCache) FindUser(user string) (bool, error) {
+	if finder, ok := c.next.(UserFinder); ok {
+		return finder.FindUser(user)
+	}
+	return false, nil
+}
+
+// forget drops the cached credentials of user, after it was deleted or its password changed
+func (c *authCache) forget(user string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key, entry := range c.entries {
+		if entry.user == user {
+			delete(c.entries, key)
+		}
+	}
+}
+
+func authCacheKey(user, password string) string {
+	sum := sha256.Sum256([]byte(user + "\x00" + password))
+	return hex.EncodeToString(sum[:])
+}
+
+// localAuthenticator checks the users kept in the meta store
+type localAuthenticator struct {
+	store PasswordChecker
+}
+
+func (a *localAuthenticator) Authenticate(user, password string) (bool, error) {
+	return a.store.CheckPassword(user, password)
+}
+
+func (a *localAuthenticator) FindUser(user string) (bool, error) {
+	users, err := a.store.Users()
+	if err != nil {
+		return false, err
+	}
+	for _, u := range users {
+		if u.Name == user {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// ldapAuthenticator binds to LDAP as the user, rejecting everyone while LDAP is disabled
+type ldapAuthenticator struct{}
+
+func (a *ldapAuthenticator) Authenticate(user, password string) (bool, error) {
+	if !Config.Ldap.Enabled {
+		return false, nil
+	}
+	return authenticateLdap(user, password)
+}
+
+func (a *ldapAuthenticator) FindUser(user string) (bool, error) {
+	if !Config.Ldap.Enabled {
+		return false, nil
+	}
+	_, err := findUserDn(user)
+	if err == errLdapUserNotFound || err == errNoLdapSearchResults {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// userServiceAuthenticator asks the user service to check the credentials, see consumers_spec.md
+type userServiceAuthenticator struct{}
+
+func (a *userServiceAuthenticator) Authenticate(user, password string) (bool, error) {
+	return userAuthenticates(user, password)
+}
+
+// htpasswdAuthenticator checks users in an htpasswd file with bcrypt or {SHA} passwords.
+// The file is read again when it changes.
+type htpasswdAuthenticator struct {
+	path    string
+	mu      sync.Mutex
+	modTime time.Time
+	users   map[string]string
+}
+
+func newHtpasswdAuthenticator(path string) *htpasswdAuthenticator {
+	return &htpasswdAuthenticator{path: path}
+}
+
+func (a *htpasswdAuthenticator) Authenticate(user, password string) (bool, error) {
+	users, err := a.load()
+	if err != nil {
+		return false, err
+	}
+	hash, ok := users[user]
+	if !ok {
+		return false, nil
+	}
+
+	switch {
+	case strings.HasPrefix(hash, "{SHA}"):
+		sum := sha1.Sum([]byte(password))
+		expected := "{SHA}" + base64.StdEncoding.EncodeToString(sum[:])
+		return subtle.ConstantTimeCompare([]byte(hash), []byte(expected)) == 1, nil
+	case strings.HasPrefix(hash, "$2"):
+		// htpasswd -B writes $2y$ hashes, which are $2a$ hashes to Go's bcrypt
+		return checkPass([]byte(strings.Replace(hash, "$2y$", "$2a$", 1)), []byte(password))
+	}
+	return false, fmt.Errorf("Unsupported htpasswd hash for %s, use bcrypt or SHA", user)
+}
+
+func (a *htpasswdAuthenticator) FindUser(user string) (bool, error) {
+	users, err := a.load()
+	if err != nil {
+		return false, err
+	}
+	_, ok := users[user]
+	return ok, nil
+}
+
+// load returns the users of the htpasswd file, reading it when it changed
+func (a *htpasswdAuthenticator) load() (map[string]string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	fi, err := os.Stat(a.path)
+	if err != nil {
+		return nil, err
+	}
+	if a.users != nil && fi.ModTime().Equal(a.modTime) {
+		return a.users, nil
+	}
+
+	f, err := os.Open(a.path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	users := make(map[string]string)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if i := strings.IndexByte(line, ':'); i > 0 {
+			users[line[:i]] = line[i+1:]
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	a.users, a.modTime = users, fi.ModTime()
+	return users, nil
+}
+
+// forgetUser drops the cached credentials of user
+func (a *App) forgetUser(user string) {
+	if cache, ok := a.authenticator.(*authCache); ok {
+		cache.forget(user)
+	}
+	groupCache.forget(user)
+}
+
+// identify resolves who sent the request from its Authorization header, which holds
+// Basic credentials, a personal access token or a git-lfs-authenticate token.
+// Returns an auth error when the credentials are not accepted, a public server then lets
+// the request through anonymously, or a store error when they can't be checked.
+func (a *App) identify(rv *RequestVars) (*Identity, error) {
+	id, err := a.resolveIdentity(rv)
+	if isAuthError(err) && Config.IsPublic() {
+		return &Identity{}, nil
+	}
+	return id, err
+}
+
+func (a *App) resolveIdentity(rv *RequestVars) (*Identity, error) {
+	if token := bearerToken(rv.Authorization); token != "" {
+		if isAccessToken(token) {
+			t, err := a.metaStore.FindToken(hashAccessToken(token))
+			if err != nil {
+				return nil, rejected(err)
+			}
+			return &Identity{User: t.User, Token: t}, nil
+		}
+		claims, err := verifyToken(token)
+		if err != nil {
+			logger.Log(kv{"fn": "identify", "msg": err.Error()})
+			return nil, newAuthError()
+		}
+		return &Identity{User: claims.User, Claims: claims}, nil
+	}
+
+	if rv.User == "" {
+		return nil, newAuthError()
+	}
+	// a password can look like a token, it's checked as a password when no token of
+	// the user matches
+	if isAccessToken(rv.Password) {
+		t, err := a.metaStore.FindToken(hashAccessToken(rv.Password))
+		if isUnavailable(err) {
+			return nil, err
+		}
+		if err == nil && t.User == rv.User {
+			return &Identity{User: t.User, Token: t}, nil
+		}
+	}
+
+	ok, err := a.authenticator.Authenticate(rv.User, rv.Password)
+	if !ok {
+		return nil, rejected(err)
+	}
+	return &Identity{User: rv.User}, nil
+}
+
+// knowsUser reports whether the auth providers know user, so tokens are only created
+// for users that exist
+func (a *App) knowsUser(user string) (bool, error) {
+	if finder, ok := a.authenticator.(UserFinder); ok {
+		return finder.FindUser(user)
+	}
+	return false, nil
+}
+
+// rejected turns the error of a failed credential check into an auth error, unless the
+// meta store was unavailable
+func rejected(err error) error {
+	if isUnavailable(err) {
+		return err
+	}
+	return newAuthError()
+}
+
+// authorize authenticates the request, then checks the scope of tokens, the LDAP group
+// mapping and the user service, when enabled, for whether the user may perform action
+// on {namespace}/{repo}.
+// rv.User is set to the authenticated user.
+// Writes a 401 or 403 response and returns false when not.
+func (a *App) authorize(w http.ResponseWriter, r *http.Request, rv *RequestVars, action string) bool {
+	id, err := a.identify(rv)
+	if isUnavailable(err) {
+		writeUnavailable(w, r, err)
+		return false
+	}
+	if err != nil {
+		requireAuth(w, r)
+		return false
+	}
+	rv.User = id.User
+
+	if id.Token != nil && !id.Token.Allows(rv.Project(), action) {
+		logger.Log(kv{"fn": "authorize", "user": id.User, "project": rv.Project(), "action": action, "msg": "Token scope denied"})
+		writeStatusMessage(w, r, 403, "Token does not allow "+action+" on "+rv.Project())
+		return false
+	}
+	// tokens from git-lfs-authenticate are only good for the repo and operation they were issued for
+	if id.Claims != nil && !tokenAllows(id.Claims, rv.Project(), action) {
+		requireAuth(w, r)
+		return false
+	}
+
+	if ldapGroupsEnabled() {
+		if rv.User == "" {
+			requireAuth(w, r)
+			return false
+		}
+		if access, message := ldapGroupsCan(rv.User, rv.Project(), action); !access {
+			logger.Log(kv{"fn": "authorize", "user": rv.User, "project": rv.Project(), "action": action, "msg": message})
+			writeStatusMessage(w, r, 403, message)
+			return false
+		}
+	}
+
+	if !Config.UserService.Enabled {
+		return true
+	}
+
+	if rv.User == "" {
+		requireAuth(w, r)
+		return false
+	}
+
+	project := rv.Project()
+	access, message := userCan(rv.User, project, action)
+	if !access {
+		logger.Log(kv{"fn": "authorize", "user": rv.User, "project": project, "action": action, "msg": "Access denied"})
+		if message == "" {
+			message = http.StatusText(403)
+		}
+		writeStatusMessage(w, r, 403, message)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+)
+
+type stubAuthenticator struct {
+	user, password string
+	err            error
+	calls          int
+}
+
+func (s *stubAuthenticator) Authenticate(user, password string) (bool, error) {
+	s.calls++
+	return user == s.user && password == s.password, s.err
+}
+
+func TestAuthChain(t *testing.T) {
+	chain := authChain{
+		&stubAuthenticator{err: fmt.Errorf("unreachable")},
+		&stubAuthenticator{user: "alice", password: "one"},
+		&stubAuthenticator{user: "bob", password: "two"},
+	}
+
+	if ok, err := chain.Authenticate("bob", "two"); !ok || err != nil {
+		t.Errorf("expected bob to be accepted by the last provider, got: %t %v", ok, err)
+	}
+	if ok, _ := chain.Authenticate("alice", "two"); ok {
+		t.Errorf("expected a wrong password to be rejected")
+	}
+	if ok, err := (authChain{}).Authenticate("alice", "one"); ok || err != nil {
+		t.Errorf("expected an empty chain to reject, got: %t %v", ok, err)
+	}
+}
+
+func TestAuthCache(t *testing.T) {
+	stub := &stubAuthenticator{user: "alice", password: "one"}
+	cache := newAuthCache(stub, time.Minute)
+
+	cache.Authenticate("alice", "one")
+	if ok, _ := cache.Authenticate("alice", "one"); !ok || stub.calls != 1 {
+		t.Errorf("expected the second login to be served from the cache, got: %t after %d calls", ok, stub.calls)
+	}
+
+	cache.Authenticate("alice", "two")
+	if ok, _ := cache.Authenticate("alice", "two"); ok || stub.calls != 3 {
+		t.Errorf("expected failed logins not to be cached, got: %t after %d calls", ok, stub.calls)
+	}
+
+	cache.forget("alice")
+	cache.Authenticate("alice", "one")
+	if stub.calls != 4 {
+		t.Errorf("expected forgotten credentials to be checked again, got %d calls", stub.calls)
+	}
+
+	cache.ttl = -time.Second
+	cache.forget("alice")
+	cache.Authenticate("alice", "one")
+	cache.Authenticate("alice", "one")
+	if stub.calls != 6 {
+		t.Errorf("expected expired credentials to be checked again, got %d calls", stub.calls)
+	}
+}
+
+func TestNewAuthenticator(t *testing.T) {
+	setupMeta()
+	defer teardownMeta()
+
+	auth, err := newAuthenticator("local, htpasswd", metaStoreTest)
+	if err != nil {
+		t.Fatalf("expected a chain, got: %s", err)
+	}
+	if chain := auth.(authChain); len(chain) != 2 {
+		t.Errorf("expected 2 providers, got: %d", len(chain))
+	}
+
+	if ok, _ := auth.Authenticate(testUser, testPass); !ok {
+		t.Errorf("expected the local user to be accepted")
+	}
+
+	if _, err := newAuthenticator("kerberos", metaStoreTest); err == nil {
+		t.Errorf("expected an unknown provider to fail")
+	}
+}
+
+func TestHtpasswdAuthenticator(t *testing.T) {
+	hash, _ := encryptPass([]byte("bcrypted"))
+	f, err := ioutil.TempFile("", "htpasswd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	// "sha1ed" hashed by htpasswd -s
+	fmt.Fprintf(f, "# users\nalice:%s\nbob:{SHA}wRspCPjt69mUC0FcnpmYiIdqndk=\ncarol:$apr1$abc$def\n", hash)
+	f.Close()
+
+	auth := newHtpasswdAuthenticator(f.Name())
+	if ok, err := auth.Authenticate("alice", "bcrypted"); !ok || err != nil {
+		t.Errorf("expected the bcrypt password to match, got: %t %v", ok, err)
+	}
+	if ok, err := auth.Authenticate("bob", "sha1ed"); !ok || err != nil {
+		t.Errorf("expected the SHA password to match, got: %t %v", ok, err)
+	}
+	if ok, _ := auth.Authenticate("bob", "wrong"); ok {
+		t.Errorf("expected a wrong password not to match")
+	}
+	if _, err := auth.Authenticate("carol", "md5ed"); err == nil {
+		t.Errorf("expected an unsupported hash to fail")
+	}
+	chain := authChain{&userServiceAuthenticator{}, auth}
+	if found, err := chain.FindUser("carol"); !found || err != nil {
+		t.Errorf("expected carol to be found, got: %t %v", found, err)
+	}
+	if found, _ := chain.FindUser("mallory"); found {
+		t.Errorf("expected an unknown user not to be found")
+	}
+
+	// the file is read again when it changes
+	ioutil.WriteFile(f.Name(), []byte("dave:{SHA}wRspCPjt69mUC0FcnpmYiIdqndk=\n"), 0644)
+	os.Chtimes(f.Name(), time.Now(), time.Now().Add(time.Minute))
+	if ok, _ := auth.Authenticate("dave", "sha1ed"); !ok {
+		t.Errorf("expected users added to the file to be picked up")
+	}
+	if ok, _ := auth.Authenticate("alice", "bcrypted"); ok {
+		t.Errorf("expected users removed from the file to be rejected")
+	}
+}
+
+func TestUserServiceAuthenticator(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, password, _ := r.BasicAuth()
+		access := r.URL.Query().Get("action") == "login" && user == "alice" && password == "secret"
+		fmt.Fprintf(w, `{"access": %t}`, access)
+	}))
+	defer srv.Close()
+	defer setupUserServiceConfig(srv.URL)()
+
+	auth := &userServiceAuthenticator{}
+	if ok, err := auth.Authenticate("alice", "secret"); !ok || err != nil {
+		t.Errorf("expected the user service to accept the login, got: %t %v", ok, err)
+	}
+	if ok, _ := auth.Authenticate("alice", "wrong"); ok {
+		t.Errorf("expected the user service to reject a wrong password")
+	}
+}
+
+func TestIdentifyAccessToken(t *testing.T) {
+	setupMeta()
+	defer teardownMeta()
+	oldPublic := Config.Public
+	Config.Public = false
+	defer func() { Config.Public = oldPublic }()
+
+	app := &App{metaStore: metaStoreTest, authenticator: authChain{&localAuthenticator{metaStoreTest}}}
+	token, secret, _ := newAccessToken(testUser, "ci", "read", nil)
+	if err := metaStoreTest.AddToken(token); err != nil {
+		t.Fatalf("expected add token to succeed, got: %s", err)
+	}
+
+	if id, err := app.identify(&RequestVars{User: testUser, Password: secret}); err != nil || id.Token == nil {
+		t.Errorf("expected the token to be accepted as a password")
+	}
+	if id, err := app.identify(&RequestVars{Authorization: "Bearer " + secret}); err != nil || id.User != testUser {
+		t.Errorf("expected the token to be accepted as a Bearer token")
+	}
+	if _, err := app.identify(&RequestVars{User: "someoneelse", Password: secret}); !isAuthError(err) {
+		t.Errorf("expected the token to be rejected for another user")
+	}
+	if id, err := app.identify(&RequestVars{User: testUser, Password: testPass}); err != nil || id.User != testUser || id.Token != nil {
+		t.Errorf("expected the password to be checked by the authenticator")
+	}
+	if _, err := app.identify(&RequestVars{User: testUser, Password: "wrong"}); !isAuthError(err) {
+		t.Errorf("expected a wrong password to be rejected")
+	}
+}
+
+func TestIdentifyTokenLikePassword(t *testing.T) {
+	setupMeta()
+	defer teardownMeta()
+	oldPublic := Config.Public
+	Config.Public = false
+	defer func() { Config.Public = oldPublic }()
+
+	// a password which happens to start like an access token
+	password := accessTokenPrefix + "hunter2"
+	app := &App{metaStore: metaStoreTest, authenticator: &stubAuthenticator{user: "alice", password: password}}
+	if id, err := app.identify(&RequestVars{User: "alice", Password: password}); err != nil || id.User != "alice" || id.Token != nil {
+		t.Errorf("expected the password to be checked by the authenticator, got: %v %v", id, err)
+	}
+
+	// another user's token is checked as a password too, and rejected
+	token, secret, _ := newAccessToken(testUser, "ci", "read", nil)
+	if err := metaStoreTest.AddToken(token); err != nil {
+		t.Fatalf("expected add token to succeed, got: %s", err)
+	}
+	if _, err := app.identify(&RequestVars{User: "alice", Password: secret}); !isAuthError(err) {
+		t.Errorf("expected another user's token to be rejected, got: %v", err)
+	}
+}
@@ -2,12 +2,19 @@ package main
 
 import (
 	"bytes"
+	"crypto/hmac"
+	"crypto/sha1"
 	"crypto/sha256"
+	"encoding/base64"
 	"encoding/hex"
+	"fmt"
 	"io"
 	"io/ioutil"
+	"net/http"
+	"net/url"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/mitchellh/goamz/aws"
 	"github.com/mitchellh/goamz/s3"
@@ -15,12 +22,15 @@ import (
 
 const (
 	ContentType = "binary/octet-stream"
+	// S3 rejects multipart uploads with parts smaller than this, except for the last part
+	minPartSize = 5 * 1024 * 1024
 )
 
 // AwsContentStore provides a simple file system based storage.
 type AwsContentStore struct {
 	client  *s3.S3
 	bucket  *s3.Bucket
+	objects s3Objects
 	authId  string
 	authKey string
 	acl     s3.ACL
@@ -35,14 +45,26 @@ func NewAwsContentStore() (*AwsContentStore, error) {
 		logger.Log(kv{"fn": "AwsContentStore.NewAwsContentStore", "err": ": " + err.Error()})
 		return &AwsContentStore{}, err
 	}
-	client := s3.New(auth, aws.Regions[Config.Aws.Region])
+	client := s3.New(auth, awsRegion())
 	bucket := client.Bucket(Config.Aws.BucketName)
-	self := &AwsContentStore{bucket: bucket, client: client}
+	self := &AwsContentStore{bucket: bucket, objects: s3Bucket{bucket}, client: client, authId: auth.AccessKey, authKey: auth.SecretKey}
 	self.makeBucket()
 	self.setAcl()
 	return self, nil
 }
 
+// awsRegion returns the configured region, pointed at Config.Aws.Endpoint when
+// using an S3 compatible store
+func awsRegion() aws.Region {
+	region := aws.Regions[Config.Aws.Region]
+	if Config.Aws.Endpoint != "" {
+		region.Name = Config.Aws.Region
+		region.S3Endpoint = Config.Aws.Endpoint
+		region.S3BucketEndpoint = ""
+	}
+	return region
+}
+
 // Make the bucket if it does not exist
 func (s *AwsContentStore) makeBucket() error {
 	buckets, err := s.bucket.ListBuckets()
@@ -66,54 +88,312 @@ func (s *AwsContentStore) makeBucket() error {
 
 func (s *AwsContentStore) Get(meta *MetaObject) (io.Reader, error) {
 	path := transformKey(meta.Oid)
-	return s.bucket.GetReader(path)
+	return s.objects.GetReader(path)
+}
+
+// GetRange returns length bytes of the object starting at offset, using a ranged GET
+func (s *AwsContentStore) GetRange(meta *MetaObject, offset, length int64) (io.Reader, error) {
+	path := transformKey(meta.Oid)
+	headers := map[string][]string{"Range": {fmt.Sprintf("bytes=%d-%d", offset, offset+length-1)}}
+	resp, err := s.bucket.GetResponseWithHeaders(path, headers)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
 }
 
 func (s *AwsContentStore) getMetaData(meta *MetaObject) (*s3.Key, error) {
 	path := transformKey(meta.Oid)
-	return s.bucket.GetKey(path)
+	return s.objects.GetKey(path)
+}
+
+// multipartUpload is the part of *s3.Multi used to stream uploads
+type multipartUpload interface {
+	PutPart(n int, r io.ReadSeeker) (s3.Part, error)
+	Complete(parts []s3.Part) error
+	Abort() error
+}
+
+// resumableUpload is the part of *s3.Multi used to collect the chunks of resumable uploads
+type resumableUpload interface {
+	multipartUpload
+	ListParts() ([]s3.Part, error)
+}
+
+// s3Objects is the part of the bucket that reads, deletes and resumably uploads objects,
+// so tests can stand in for S3
+type s3Objects interface {
+	GetReader(path string) (io.ReadCloser, error)
+	GetKey(path string) (*s3.Key, error)
+	Del(path string) error
+	FindUpload(path string) (resumableUpload, error)
+	InitUpload(path string, perm s3.ACL) (resumableUpload, error)
+}
+
+// s3Bucket implements s3Objects on a bucket
+type s3Bucket struct {
+	*s3.Bucket
+}
+
+// FindUpload returns the pending multipart upload of path, or nil if there is none
+func (b s3Bucket) FindUpload(path string) (resumableUpload, error) {
+	multis, _, err := b.ListMulti(path, "")
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range multis {
+		if m.Key == path {
+			return m, nil
+		}
+	}
+	return nil, nil
+}
+
+// InitUpload starts a multipart upload of path
+func (b s3Bucket) InitUpload(path string, perm s3.ACL) (resumableUpload, error) {
+	multi, err := b.InitMulti(path, ContentType, perm)
+	if err != nil {
+		return nil, err
+	}
+	return multi, nil
 }
 
-// TODO: maybe take write errors into account and buffer/resend to amazon?
+// Put streams the content to S3 as a multipart upload, hashing it on the way.
+// Only one part is held in memory at a time. The upload is only completed when
+// the size and sha256 match the MetaObject, otherwise it is aborted.
 func (s *AwsContentStore) Put(meta *MetaObject, r io.Reader) error {
 	path := transformKey(meta.Oid)
-	/*
-		There is probably a better way to compute this but we need to write the file to memory to
-		 compute the sha256 value and make sure what we're writing is correct.
-		 If not, git wont be able to find it later
-	*/
-	hash := sha256.New()
-	buf, _ := ioutil.ReadAll(r)
-	hw := io.MultiWriter(hash)
-	written, err := io.Copy(hw, bytes.NewReader(buf))
+	if meta.Size == 0 {
+		// S3 does not accept multipart uploads without parts
+		if err := verifyContent(meta, r); err != nil {
+			return err
+		}
+		return s.bucket.PutReader(path, bytes.NewReader(nil), 0, ContentType, s.acl)
+	}
+
+	multi, err := s.bucket.InitMulti(path, ContentType, s.acl)
 	if err != nil {
 		logger.Log(kv{"fn": "AwsContentStore.Put", "err": ": " + err.Error()})
+		return errWriteS3
+	}
+	if err := streamParts(multi, meta, r, partSize()); err != nil {
 		return err
 	}
-	// Check that we've written out the entire file for computing the sha
+
+	k, kerr := s.getMetaData(meta)
+	if kerr != nil {
+		logger.Log(kv{"fn": "AwsContentStore.Put", "err": ": " + kerr.Error()})
+		return errWriteS3
+	}
+	if k.Size != meta.Size {
+		return errSizeMismatch
+	}
+	return nil
+}
+
+// streamParts uploads r in parts of partSize bytes, then completes the upload if
+// the content matches meta. The upload is aborted on any failure.
+func streamParts(multi multipartUpload, meta *MetaObject, r io.Reader, partSize int64) error {
+	hash := sha256.New()
+	// read one byte past the expected size so oversized content is caught
+	content := io.TeeReader(io.LimitReader(r, meta.Size+1), hash)
+
+	var parts []s3.Part
+	var written int64
+	buf := make([]byte, partSize)
+	for n := 1; ; n++ {
+		read, err := io.ReadFull(content, buf)
+		if read > 0 {
+			written += int64(read)
+			if written > meta.Size {
+				multi.Abort()
+				return errSizeMismatch
+			}
+			part, perr := multi.PutPart(n, bytes.NewReader(buf[:read]))
+			if perr != nil {
+				logger.Log(kv{"fn": "AwsContentStore.Put", "err": ": " + perr.Error()})
+				multi.Abort()
+				return errWriteS3
+			}
+			parts = append(parts, part)
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+		if err != nil {
+			logger.Log(kv{"fn": "AwsContentStore.Put", "err": ": " + err.Error()})
+			multi.Abort()
+			return err
+		}
+	}
+
 	if written != meta.Size {
+		multi.Abort()
 		return errSizeMismatch
 	}
-	shaStr := hex.EncodeToString(hash.Sum(nil))
-	if shaStr != meta.Oid {
+	if hex.EncodeToString(hash.Sum(nil)) != meta.Oid {
+		multi.Abort()
 		return errHashMismatch
 	}
-	retStat := s.bucket.PutReader(path, bytes.NewReader(buf), meta.Size, ContentType, s.acl)
-	k, kerr := s.getMetaData(meta)
-	if kerr != nil {
-		logger.Log(kv{"fn": "AwsContentStore.Put", "err": ": " + kerr.Error()})
+	if err := multi.Complete(parts); err != nil {
+		logger.Log(kv{"fn": "AwsContentStore.Put", "err": ": " + err.Error()})
+		multi.Abort()
 		return errWriteS3
 	}
-	if k.Size != meta.Size {
+	return nil
+}
+
+// verifyContent checks the size and sha256 of r against meta
+func verifyContent(meta *MetaObject, r io.Reader) error {
+	hash := sha256.New()
+	written, err := io.Copy(hash, io.LimitReader(r, meta.Size+1))
+	if err != nil {
+		return err
+	}
+	if written != meta.Size {
 		return errSizeMismatch
 	}
-	return retStat
+	if hex.EncodeToString(hash.Sum(nil)) != meta.Oid {
+		return errHashMismatch
+	}
+	return nil
+}
+
+// partSize returns the configured multipart part size, never less than S3 allows
+func partSize() int64 {
+	if Config.Aws.PartSize < minPartSize {
+		return minPartSize
+	}
+	return Config.Aws.PartSize
+}
+
+// UploadOffset returns how many bytes of a resumable upload have been stored as
+// parts of its pending multipart upload. Objects already in S3 report their full size.
+func (s *AwsContentStore) UploadOffset(meta *MetaObject) (int64, error) {
+	if s.Exists(meta) {
+		return meta.Size, nil
+	}
+	multi, err := s.objects.FindUpload(transformKey(meta.Oid))
+	if err != nil || multi == nil {
+		return 0, err
+	}
+	parts, err := multi.ListParts()
+	if err != nil {
+		return 0, err
+	}
+	return partsSize(parts), nil
+}
+
+// PutChunk stores the content of r as the next part of a resumable multipart
+// upload. Every chunk but the last must be at least minPartSize bytes. Once the
+// last chunk arrives the upload is completed and read back to verify the size
+// and sha256; content that does not match is deleted.
+// It returns the upload offset after the chunk was written.
+func (s *AwsContentStore) PutChunk(meta *MetaObject, offset int64, r io.Reader) (int64, error) {
+	defer uploadLocks.lock(meta.Oid)()
+
+	if s.Exists(meta) {
+		if offset != meta.Size {
+			return meta.Size, errOffsetMismatch
+		}
+		return meta.Size, nil
+	}
+
+	multi, err := s.objects.FindUpload(transformKey(meta.Oid))
+	if err != nil {
+		logger.Log(kv{"fn": "AwsContentStore.PutChunk", "err": ": " + err.Error()})
+		return 0, errWriteS3
+	}
+	var parts []s3.Part
+	if multi != nil {
+		if parts, err = multi.ListParts(); err != nil {
+			logger.Log(kv{"fn": "AwsContentStore.PutChunk", "err": ": " + err.Error()})
+			return 0, errWriteS3
+		}
+	}
+	current := partsSize(parts)
+	if offset != current {
+		return current, errOffsetMismatch
+	}
+
+	// parts need a ReadSeeker, so spool the chunk to disk rather than memory
+	tmp, err := ioutil.TempFile("", "lfs-chunk")
+	if err != nil {
+		return current, err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	written, err := io.Copy(tmp, io.LimitReader(r, meta.Size-offset+1))
+	if err != nil {
+		return current, err
+	}
+	if offset+written > meta.Size {
+		if multi != nil {
+			multi.Abort()
+		}
+		return 0, errSizeMismatch
+	}
+	if written == 0 {
+		return current, nil
+	}
+	if offset+written < meta.Size && written < minPartSize {
+		return current, errChunkTooSmall
+	}
+	if _, err := tmp.Seek(0, os.SEEK_SET); err != nil {
+		return current, err
+	}
+
+	if multi == nil {
+		if multi, err = s.objects.InitUpload(transformKey(meta.Oid), s.acl); err != nil {
+			logger.Log(kv{"fn": "AwsContentStore.PutChunk", "err": ": " + err.Error()})
+			return current, errWriteS3
+		}
+	}
+	part, err := multi.PutPart(len(parts)+1, tmp)
+	if err != nil {
+		logger.Log(kv{"fn": "AwsContentStore.PutChunk", "err": ": " + err.Error()})
+		return current, errWriteS3
+	}
+	parts = append(parts, part)
+	offset += written
+	if offset < meta.Size {
+		return offset, nil
+	}
+
+	if err := multi.Complete(parts); err != nil {
+		logger.Log(kv{"fn": "AwsContentStore.PutChunk", "err": ": " + err.Error()})
+		multi.Abort()
+		return 0, errWriteS3
+	}
+	content, err := s.Get(meta)
+	if err != nil {
+		return offset, err
+	}
+	err = verifyContent(meta, content)
+	if c, ok := content.(io.Closer); ok {
+		c.Close()
+	}
+	if err != nil {
+		s.Delete(meta)
+		return 0, err
+	}
+	return offset, nil
+}
+
+// partsSize returns the number of bytes held by parts
+func partsSize(parts []s3.Part) int64 {
+	var size int64
+	for _, p := range parts {
+		size += p.Size
+	}
+	return size
 }
 
 func (s *AwsContentStore) Exists(meta *MetaObject) bool {
 	path := transformKey(meta.Oid)
 	// returns a 404 error if its not there
-	_, err := s.bucket.GetKey(path)
+	_, err := s.objects.GetKey(path)
 	if err != nil {
 		if strings.Contains(err.Error(), "404") {
 			return false
@@ -126,12 +406,70 @@ func (s *AwsContentStore) Exists(meta *MetaObject) bool {
 	return true
 }
 
-/*
-AddProject (create a new project using POST)
-Only implemented on MySQL meta store
-*/
-func (s *AwsContentStore) AddProject(name string) error {
-	return errMySQLNotImplemented 
+// Size returns the size of the object as stored in S3.
+func (s *AwsContentStore) Size(meta *MetaObject) (int64, error) {
+	k, err := s.getMetaData(meta)
+	if err != nil {
+		return 0, err
+	}
+	return k.Size, nil
+}
+
+// ModTime returns when the object was last written to S3.
+func (s *AwsContentStore) ModTime(meta *MetaObject) (time.Time, error) {
+	k, err := s.getMetaData(meta)
+	if err != nil {
+		return time.Time{}, err
+	}
+	// HEAD requests report Last-Modified as an http date, listings as RFC3339
+	if t, err := http.ParseTime(k.LastModified); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, k.LastModified)
+}
+
+// Delete removes the object from S3, along with any pending resumable upload.
+func (s *AwsContentStore) Delete(meta *MetaObject) error {
+	path := transformKey(meta.Oid)
+	if multi, err := s.objects.FindUpload(path); err == nil && multi != nil {
+		multi.Abort()
+	}
+	return s.objects.Del(path)
+}
+
+// PresignGet returns a URL that downloads the object directly from S3 until expires
+func (s *AwsContentStore) PresignGet(meta *MetaObject, expires time.Time) (string, error) {
+	return s.presign("GET", "", meta, expires)
+}
+
+// PresignPut returns a URL that uploads the object directly to S3 until expires.
+// The upload must be sent with a Content-Type of ContentType.
+func (s *AwsContentStore) PresignPut(meta *MetaObject, expires time.Time) (string, error) {
+	return s.presign("PUT", ContentType, meta, expires)
+}
+
+// presign builds a query string authenticated URL, signed the same way goamz signs requests
+func (s *AwsContentStore) presign(method, contentType string, meta *MetaObject, expires time.Time) (string, error) {
+	path := transformKey(meta.Oid)
+	u, err := url.Parse(s.bucket.URL(path))
+	if err != nil {
+		return "", err
+	}
+	resource := fmt.Sprintf("/%s/%s", s.bucket.Name, path)
+	q := u.Query()
+	q.Set("AWSAccessKeyId", s.authId)
+	q.Set("Expires", fmt.Sprintf("%d", expires.Unix()))
+	q.Set("Signature", signV2(s.authKey, method, contentType, resource, expires))
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+// signV2 signs a request for S3 query string authentication
+func signV2(secret, method, contentType, resource string, expires time.Time) string {
+	toSign := fmt.Sprintf("%s\n\n%s\n%d\n%s", method, contentType, expires.Unix(), resource)
+	mac := hmac.New(sha1.New, []byte(secret))
+	mac.Write([]byte(toSign))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
 }
 
 func (s *AwsContentStore) setAcl() {
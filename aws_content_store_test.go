@@ -2,11 +2,16 @@ package main
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/mitchellh/goamz/aws"
 	"github.com/mitchellh/goamz/s3"
@@ -139,6 +144,214 @@ func TestAwsContentStoreExists(t *testing.T) {
 	}
 }
 
+// fakeMultipart records the parts of a multipart upload in memory
+type fakeMultipart struct {
+	parts     [][]byte
+	completed bool
+	aborted   bool
+}
+
+func (m *fakeMultipart) PutPart(n int, r io.ReadSeeker) (s3.Part, error) {
+	b, _ := ioutil.ReadAll(r)
+	m.parts = append(m.parts, b)
+	return s3.Part{N: n, Size: int64(len(b))}, nil
+}
+
+func (m *fakeMultipart) Complete(parts []s3.Part) error {
+	m.completed = true
+	return nil
+}
+
+func (m *fakeMultipart) Abort() error {
+	m.aborted = true
+	return nil
+}
+
+func TestAwsStreamParts(t *testing.T) {
+	m := &MetaObject{
+		Oid:  "6ae8a75555209fd6c44157c0aed8016e763ff435a19cf186f76863140143ff72",
+		Size: 12,
+	}
+
+	multi := &fakeMultipart{}
+	if err := streamParts(multi, m, bytes.NewBufferString("test content"), 5); err != nil {
+		t.Fatalf("expected stream to succeed, got: %s", err)
+	}
+
+	if !multi.completed || multi.aborted {
+		t.Fatalf("expected upload to be completed, got: %+v", multi)
+	}
+
+	if len(multi.parts) != 3 || string(bytes.Join(multi.parts, nil)) != "test content" {
+		t.Fatalf("expected content in 3 parts, got: %q", multi.parts)
+	}
+}
+
+func TestAwsStreamPartsMismatch(t *testing.T) {
+	for content, size := range map[string]int64{"bogus content": 13, "test content": 14, "test content and more": 12} {
+		m := &MetaObject{
+			Oid:  "6ae8a75555209fd6c44157c0aed8016e763ff435a19cf186f76863140143ff72",
+			Size: size,
+		}
+
+		multi := &fakeMultipart{}
+		if err := streamParts(multi, m, bytes.NewBufferString(content), 5); err == nil {
+			t.Errorf("expected stream of %q with size %d to fail", content, size)
+		}
+
+		if multi.completed || !multi.aborted {
+			t.Errorf("expected upload of %q to be aborted, got: %+v", content, multi)
+		}
+	}
+}
+
+// fakeBucket stands in for S3 in resumable upload tests, holding objects and
+// pending multipart uploads in memory
+type fakeBucket struct {
+	objects map[string][]byte
+	uploads map[string]*fakeUpload
+}
+
+func newFakeBucket() *fakeBucket {
+	return &fakeBucket{objects: make(map[string][]byte), uploads: make(map[string]*fakeUpload)}
+}
+
+func (b *fakeBucket) GetReader(path string) (io.ReadCloser, error) {
+	content, ok := b.objects[path]
+	if !ok {
+		return nil, errors.New("404 Not Found")
+	}
+	return ioutil.NopCloser(bytes.NewReader(content)), nil
+}
+
+func (b *fakeBucket) GetKey(path string) (*s3.Key, error) {
+	content, ok := b.objects[path]
+	if !ok {
+		return nil, errors.New("404 Not Found")
+	}
+	return &s3.Key{Key: path, Size: int64(len(content))}, nil
+}
+
+func (b *fakeBucket) Del(path string) error {
+	delete(b.objects, path)
+	return nil
+}
+
+func (b *fakeBucket) FindUpload(path string) (resumableUpload, error) {
+	if u, ok := b.uploads[path]; ok {
+		return u, nil
+	}
+	return nil, nil
+}
+
+func (b *fakeBucket) InitUpload(path string, perm s3.ACL) (resumableUpload, error) {
+	u := &fakeUpload{fakeMultipart: &fakeMultipart{}, bucket: b, path: path}
+	b.uploads[path] = u
+	return u, nil
+}
+
+// fakeUpload is a multipart upload of a fakeBucket, completing it stores the object
+type fakeUpload struct {
+	*fakeMultipart
+	bucket *fakeBucket
+	path   string
+}
+
+func (u *fakeUpload) ListParts() ([]s3.Part, error) {
+	var parts []s3.Part
+	for i, p := range u.parts {
+		parts = append(parts, s3.Part{N: i + 1, Size: int64(len(p))})
+	}
+	return parts, nil
+}
+
+func (u *fakeUpload) Complete(parts []s3.Part) error {
+	u.bucket.objects[u.path] = bytes.Join(u.parts, nil)
+	delete(u.bucket.uploads, u.path)
+	return u.fakeMultipart.Complete(parts)
+}
+
+func (u *fakeUpload) Abort() error {
+	delete(u.bucket.uploads, u.path)
+	return u.fakeMultipart.Abort()
+}
+
+// chunkedObject returns content of two minimum sized parts and a short last one, with its MetaObject
+func chunkedObject() ([]byte, *MetaObject) {
+	content := append(bytes.Repeat([]byte("a"), 2*minPartSize), []byte("last")...)
+	hash := sha256.Sum256(content)
+	return content, &MetaObject{Oid: hex.EncodeToString(hash[:]), Size: int64(len(content))}
+}
+
+func TestAwsPutChunkResume(t *testing.T) {
+	content, m := chunkedObject()
+	bucket := newFakeBucket()
+	store := &AwsContentStore{objects: bucket}
+
+	offset, err := store.PutChunk(m, 0, bytes.NewReader(content[:minPartSize]))
+	if err != nil || offset != minPartSize {
+		t.Fatalf("expected the first chunk to be stored, got offset %d: %v", offset, err)
+	}
+	// the offset to resume from comes from the parts of the pending upload
+	if offset, err := store.UploadOffset(m); err != nil || offset != minPartSize {
+		t.Fatalf("expected to resume at %d, got %d: %v", minPartSize, offset, err)
+	}
+	if offset, err := store.PutChunk(m, 0, bytes.NewReader(content[:minPartSize])); err != errOffsetMismatch || offset != minPartSize {
+		t.Fatalf("expected errOffsetMismatch at %d, got %d: %v", minPartSize, offset, err)
+	}
+
+	offset, err = store.PutChunk(m, minPartSize, bytes.NewReader(content[minPartSize:]))
+	if err != nil || offset != m.Size {
+		t.Fatalf("expected the upload to complete, got offset %d: %v", offset, err)
+	}
+	if !store.Exists(m) || !bytes.Equal(bucket.objects[transformKey(m.Oid)], content) {
+		t.Fatalf("expected the content to be stored")
+	}
+	if offset, err := store.UploadOffset(m); err != nil || offset != m.Size {
+		t.Errorf("expected a stored object to report its size, got %d: %v", offset, err)
+	}
+}
+
+func TestAwsPutChunkTooSmall(t *testing.T) {
+	content, m := chunkedObject()
+	bucket := newFakeBucket()
+	store := &AwsContentStore{objects: bucket}
+
+	offset, err := store.PutChunk(m, 0, bytes.NewReader(content[:minPartSize-1]))
+	if err != errChunkTooSmall || offset != 0 {
+		t.Fatalf("expected errChunkTooSmall at 0, got %d: %v", offset, err)
+	}
+	if offset, err := store.UploadOffset(m); err != nil || offset != 0 || len(bucket.uploads) != 0 {
+		t.Errorf("expected no upload to be started, got offset %d: %v", offset, err)
+	}
+}
+
+func TestAwsPutChunkHashMismatch(t *testing.T) {
+	content, m := chunkedObject()
+	bucket := newFakeBucket()
+	store := &AwsContentStore{objects: bucket}
+
+	if _, err := store.PutChunk(m, 0, bytes.NewReader(content[:minPartSize])); err != nil {
+		t.Fatalf("expected the first chunk to be stored, got: %v", err)
+	}
+	bogus := bytes.Repeat([]byte("b"), len(content)-minPartSize)
+	offset, err := store.PutChunk(m, minPartSize, bytes.NewReader(bogus))
+	if err != errHashMismatch || offset != 0 {
+		t.Fatalf("expected errHashMismatch at 0, got %d: %v", offset, err)
+	}
+	if store.Exists(m) || len(bucket.uploads) != 0 {
+		t.Errorf("expected the completed upload to be deleted")
+	}
+}
+
+func TestAwsSignV2(t *testing.T) {
+	// query string authentication example from the S3 developer guide
+	sig := signV2("wJalrXUtnFEMI/K7MDENG/bPxRfiCYEXAMPLEKEY", "GET", "", "/johnsmith/photos/puppy.jpg", time.Unix(1175139620, 0))
+	if sig != "NpgCjnDzrM+WFzoENXmpNDUsSn8=" {
+		t.Fatalf("expected signature to match, got: %s", sig)
+	}
+}
+
 func TestAwsSettings(t *testing.T) {
 	setupAwsTest()
 	defer teardownAwsTest()
@@ -179,7 +392,7 @@ func awsConnectForTest() *s3.Bucket {
 	os.Setenv("AWS_SECRET_ACCESS_KEY", Config.Aws.SecretAccessKey)
 	auth, err := aws.EnvAuth()
 	perror(err)
-	return s3.New(auth, aws.Regions[Config.Aws.Region]).Bucket(Config.Aws.BucketName)
+	return s3.New(auth, awsRegion()).Bucket(Config.Aws.BucketName)
 }
 
 func setupAwsTest() {
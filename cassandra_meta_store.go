@@ -1,13 +1,9 @@
 package main
 
 import (
-	"bytes"
-	"encoding/base64"
-	"encoding/gob"
-	"fmt"
 	"github.com/gocql/gocql"
 	"github.com/relops/cqlr"
-	"strings"
+	"time"
 )
 
 type CassandraMetaStore struct {
@@ -17,37 +13,60 @@ type CassandraMetaStore struct {
 
 func NewCassandraMetaStore(cassandraService ...*CassandraService) (*CassandraMetaStore, error) {
 	if len(cassandraService) == 0 {
-		cassandraService = append(cassandraService, NewCassandraSession())
+		session, err := NewCassandraSession()
+		if err != nil {
+			return nil, err
+		}
+		cassandraService = append(cassandraService, session)
 	}
 	cs := cassandraService[0]
 	return &CassandraMetaStore{cassandraService: cs, client: cs.Client}, nil
 }
 
+/*
+Wraps err in a store error, marking it unavailable when the cluster can't be reached, so
+requests get a 503
+*/
+func cassandraError(op string, err error) error {
+	if err == nil || isStoreError(err) {
+		return err
+	}
+	switch err.(type) {
+	case *gocql.RequestErrUnavailable, *gocql.RequestErrReadTimeout, *gocql.RequestErrWriteTimeout:
+		return newUnavailableError("cassandra", op, err)
+	}
+	if err == gocql.ErrNoConnections || err == gocql.ErrTimeoutNoResponse {
+		return newUnavailableError("cassandra", op, err)
+	}
+	return newStoreError("cassandra", op, err)
+}
+
 func (self *CassandraMetaStore) Close() {
 	defer self.client.Close()
 	return
 }
 
-func (self *CassandraMetaStore) createProject(project string) error {
+func (self *CassandraMetaStore) createProject(namespace, name string) error {
 	counter := make(map[string]interface{}, 1)
-	self.client.Query("select count(*) as count from projects where name = ?", project).MapScan(counter)
+	err := self.client.Query("select count(*) as count from projects where namespace = ? and name = ?", namespace, name).MapScan(counter)
+	if err != nil {
+		return cassandraError("create project", err)
+	}
 	if counter["count"].(int64) > 0 {
 		// already there
 		return nil
 	}
-	err := self.client.Query("insert into projects (name) values(?)", project).Exec()
-	return err
+	err = self.client.Query("insert into projects (namespace, name) values(?, ?)", namespace, name).Exec()
+	return cassandraError("create project", err)
 }
 
-func (self *CassandraMetaStore) addOidToProject(oid string, project string) error {
-	// Cannot bind on collections
-	q := fmt.Sprintf("update projects set oids = oids + {'%s'} where name = '%s'", oid, project)
-	err := self.client.Query(q).Exec()
-	return err
+func (self *CassandraMetaStore) addOidToProject(oid string, namespace, name string) error {
+	err := self.client.Query("update projects set oids = oids + ? where namespace = ? and name = ?", []string{oid}, namespace, name).Exec()
+	return cassandraError("add oid to project", err)
 }
 
 func (self *CassandraMetaStore) createOid(oid string, size int64) error {
-	return self.client.Query("insert into oids (oid, size) values (?, ?)", oid, size).Exec()
+	return cassandraError("create oid", self.client.Query("insert into oids (oid, size) values (?, ?)", oid, size).Exec())
 }
 
 func (self *CassandraMetaStore) removeOid(oid string) error {
@@ -57,32 +76,34 @@ func (self *CassandraMetaStore) removeOid(oid string) error {
 		2. If other projects are still using the OID, then do not delete it from the main OID listing
 	*/
 	//	return self.client.Query("update projects set oids = oids - {?} where oids contains ?", oid).Exec()
-	return self.client.Query("delete from oids where oid = ?", oid).Exec()
+	return cassandraError("remove oid", self.client.Query("delete from oids where oid = ?", oid).Exec())
 }
 
-func (self *CassandraMetaStore) removeOidFromProject(oid, project string) error {
+func (self *CassandraMetaStore) removeOidFromProject(oid, namespace, name string) error {
 	/*
 		Oids are shared amongst projects, so this will need to find out the following:
 		1. What projects (if any) have the requested OID.
 		2. If other projects are still using the OID, then do not delete it from the main OID listing
 	*/
-	q := fmt.Sprintf("update projects set oids = oids - {'%s'} where name = '%s'", oid, project)
-	return self.client.Query(q).Exec()
+	err := self.client.Query("update projects set oids = oids - ? where namespace = ? and name = ?", []string{oid}, namespace, name).Exec()
+	return cassandraError("remove oid from project", err)
 }
 
-func (self *CassandraMetaStore) removeProject(projectName string) error {
-	return self.client.Query("delete from projects where name = ?", projectName).Exec()
+func (self *CassandraMetaStore) removeProject(namespace, name string) error {
+	return cassandraError("remove project", self.client.Query("delete from projects where namespace = ? and name = ?", namespace, name).Exec())
 }
 
-func (self *CassandraMetaStore) findProject(projectName string) (*MetaProject, error) {
-	if projectName == "" {
+func (self *CassandraMetaStore) findProject(namespace, name string) (*MetaProject, error) {
+	if name == "" {
 		return nil, errProjectNotFound
 	}
-	q := self.client.Query("select * from projects where name = ?", projectName)
+	q := self.client.Query("select * from projects where namespace = ? and name = ?", namespace, name)
 	b := cqlr.BindQuery(q)
 	var ct MetaProject
 	b.Scan(&ct)
-	defer b.Close()
+	if err := b.Close(); err != nil {
+		return nil, cassandraError("find project", err)
+	}
 	if ct.Name == "" {
 		return nil, errProjectNotFound
 	}
@@ -94,7 +115,9 @@ func (self *CassandraMetaStore) findOid(oid string) (*MetaObject, error) {
 	b := cqlr.BindQuery(q)
 	var mo MetaObject
 	b.Scan(&mo)
-	defer b.Close()
+	if err := b.Close(); err != nil {
+		return nil, cassandraError("find oid", err)
+	}
 	if mo.Oid == "" {
 		return nil, errObjectNotFound
 	}
@@ -112,7 +135,9 @@ func (self *CassandraMetaStore) findAllOids() ([]*MetaObject, error) {
 	for itr.Scan(&oid, &size) {
 		oid_list = append(oid_list, &MetaObject{Oid: oid, Size: size})
 	}
-	itr.Close()
+	if err := itr.Close(); err != nil {
+		return nil, cassandraError("list oids", err)
+	}
 	return oid_list, nil
 }
 
@@ -120,15 +145,17 @@ func (self *CassandraMetaStore) findAllOids() ([]*MetaObject, error) {
 Project finder - returns a []*MetaProject
 */
 func (self *CassandraMetaStore) findAllProjects() ([]*MetaProject, error) {
-	itr := self.cassandraService.Client.Query("select name, oids from projects;").Iter()
+	itr := self.cassandraService.Client.Query("select namespace, name, oids from projects;").Iter()
 	var oids []string
-	var name string
+	var namespace, name string
 	project_list := make([]*MetaProject, 0)
 	//	var project_list []*MetaProject
-	for itr.Scan(&name, &oids) {
-		project_list = append(project_list, &MetaProject{Name: name, Oids: oids})
+	for itr.Scan(&namespace, &name, &oids) {
+		project_list = append(project_list, &MetaProject{Namespace: namespace, Name: name, Oids: oids})
+	}
+	if err := itr.Close(); err != nil {
+		return nil, cassandraError("list projects", err)
 	}
-	itr.Close()
 	if len(project_list) == 0 {
 		return nil, errProjectNotFound
 	}
@@ -146,35 +173,36 @@ Password: "admin",
 epo: "my-repo",
 Authorization: "Basic YWRtaW46YWRtaW4=",
 })
-
 */
 func (self *CassandraMetaStore) Put(v *RequestVars) (*MetaObject, error) {
-	if !self.authenticate(v.Authorization) {
-		logger.Log(kv{"fn": "cassandra_meta_store", "msg": "Unauthorized"})
-		return nil, newAuthError()
-	}
-	if meta, err := self.Get(v); err == nil {
+	meta, err := self.findOid(v.Oid)
+	if err == nil {
 		meta.Existing = true
-		return meta, nil
+	} else if err == errObjectNotFound {
+		meta = &MetaObject{Oid: v.Oid, Size: v.Size, Existing: false}
+		if err := self.createOid(v.Oid, v.Size); err != nil {
+			return nil, err
+		}
+	} else {
+		return nil, err
 	}
-	var buf bytes.Buffer
-	enc := gob.NewEncoder(&buf)
-	meta := MetaObject{Oid: v.Oid, Size: v.Size, Existing: false}
-	err := enc.Encode(meta)
-	perror(self.createOid(v.Oid, v.Size))
 	if v.Repo != "" {
 		// find or create project
-		_, ferr := self.findProject(v.Repo)
-		if ferr != nil {
+		_, ferr := self.findProject(v.Namespace, v.Repo)
+		if ferr == errProjectNotFound {
 			// project does not exist, create it
-			perror(self.createProject(v.Repo))
+			if err := self.createProject(v.Namespace, v.Repo); err != nil {
+				return nil, err
+			}
+		} else if ferr != nil {
+			return nil, ferr
+		}
+		// links existing oids into the project as well
+		if err := self.addOidToProject(v.Oid, v.Namespace, v.Repo); err != nil {
+			return nil, err
 		}
-		perror(self.addOidToProject(v.Oid, v.Repo))
-	}
-	if err != nil {
-		return nil, err
 	}
-	return &meta, nil
+	return meta, nil
 }
 
 /*
@@ -190,22 +218,32 @@ Authorization: "Basic YWRtaW46YWRtaW4=",
 })
 */
 func (self *CassandraMetaStore) Get(v *RequestVars) (*MetaObject, error) {
-	if !self.authenticate(v.Authorization) {
-		logger.Log(kv{"fn": "cassandra_meta_store", "msg": "Unauthorized"})
-		return nil, newAuthError()
-	}
 	r, err := self.findOid(v.Oid)
 	if err != nil {
 		return nil, err
 	}
-	var buf bytes.Buffer
-	enc := gob.NewEncoder(&buf)
-	meta := MetaObject{Oid: r.Oid, Size: r.Size}
-	err = enc.Encode(meta)
+	// oids are only visible through the projects they belong to
+	project, err := self.findProject(v.Namespace, v.Repo)
+	if err == errProjectNotFound {
+		return nil, errObjectNotFound
+	}
 	if err != nil {
 		return nil, err
 	}
-	return &meta, nil
+	for _, oid := range project.Oids {
+		if oid == r.Oid {
+			return &MetaObject{Oid: r.Oid, Size: r.Size, ProjectNames: []string{project.Key()}}, nil
+		}
+	}
+	return nil, errObjectNotFound
+}
+
+/*
+finds an oid, whatever projects it belongs to
+Usage: FindObject("oid string")
+*/
+func (self *CassandraMetaStore) FindObject(oid string) (*MetaObject, error) {
+	return self.findOid(oid)
 }
 
 /*
@@ -217,6 +255,9 @@ func (self *CassandraMetaStore) findUser(user string) (*MetaUser, error) {
 	q := self.client.Query("select * from users where username = ?", user)
 	b := cqlr.BindQuery(q)
 	b.Scan(&mu)
+	if err := b.Close(); err != nil {
+		return nil, cassandraError("find user", err)
+	}
 	if mu.Name == "" {
 		return nil, errUserNotFound
 	}
@@ -240,7 +281,7 @@ func (self *CassandraMetaStore) AddUser(user, pass string) error {
 		return err
 	}
 
-	return self.client.Query("insert into users (username, password) values(?, ?)", user, encryptedPass).Exec()
+	return cassandraError("add user", self.client.Query("insert into users (username, password) values(?, ?)", user, encryptedPass).Exec())
 }
 
 /*
@@ -251,7 +292,22 @@ func (self *CassandraMetaStore) DeleteUser(user string) error {
 	if Config.Ldap.Enabled {
 		return errNotImplemented
 	}
-	return self.client.Query("delete from users where username = ?", user).Exec()
+	// revoke the tokens first, so they stop working with the user gone
+	var hashes []string
+	var hash string
+	iter := self.client.Query("select hash from tokens where username = ?", user).Iter()
+	for iter.Scan(&hash) {
+		hashes = append(hashes, hash)
+	}
+	if err := iter.Close(); err != nil {
+		return cassandraError("delete user", err)
+	}
+	for _, hash := range hashes {
+		if err := self.client.Query("delete from tokens where hash = ?", hash).Exec(); err != nil {
+			return cassandraError("delete user", err)
+		}
+	}
+	return cassandraError("delete user", self.client.Query("delete from users where username = ?", user).Exec())
 }
 
 /*
@@ -294,54 +350,209 @@ func (self *CassandraMetaStore) Projects() ([]*MetaProject, error) {
 }
 
 /*
-AddProject (create a new project using POST)
-Only implemented on MySQL meta store
+Removes an oid and its membership in every project
 */
-func (self *CassandraMetaStore) AddProject(name string) error {
-	return errMySQLNotImplemented
+func (self *CassandraMetaStore) DeleteObject(oid string) error {
+	if _, err := self.findOid(oid); err != nil {
+		return err
+	}
+	itr := self.client.Query("select namespace, name from projects where oids contains ?", oid).Iter()
+	var namespace, name string
+	for itr.Scan(&namespace, &name) {
+		if err := self.removeOidFromProject(oid, namespace, name); err != nil {
+			itr.Close()
+			return err
+		}
+	}
+	if err := itr.Close(); err != nil {
+		return cassandraError("delete object", err)
+	}
+	return self.removeOid(oid)
 }
 
 /*
-Auth routine.  Requires an auth string like
-"Basic YWRtaW46YWRtaW4="
+Removes a project and its locks, the oids are left for garbage collection
 */
-func (self *CassandraMetaStore) authenticate(authorization string) bool {
-	if Config.IsPublic() {
-		return true
+func (self *CassandraMetaStore) DeleteProject(namespace, name string) error {
+	if _, err := self.findProject(namespace, name); err != nil {
+		return err
 	}
-
-	if authorization == "" {
-		return false
+	if err := self.client.Query("delete from locks where project = ?", projectKey(namespace, name)).Exec(); err != nil {
+		return cassandraError("delete project", err)
 	}
+	return self.removeProject(namespace, name)
+}
 
-	if !strings.HasPrefix(authorization, "Basic ") {
-		return false
+/*
+Creates an empty project, fails with errProjectExists when it is already there
+*/
+func (self *CassandraMetaStore) AddProject(namespace, name string) error {
+	applied, err := self.client.Query("insert into projects (namespace, name) values (?, ?) if not exists",
+		namespace, name).MapScanCAS(make(map[string]interface{}))
+	if err != nil {
+		return cassandraError("add project", err)
+	}
+	if !applied {
+		return errProjectExists
 	}
+	return nil
+}
 
-	c, err := base64.URLEncoding.DecodeString(strings.TrimPrefix(authorization, "Basic "))
+/*
+Moves a project, its oids and its locks to newNamespace/newName
+*/
+func (self *CassandraMetaStore) RenameProject(namespace, name, newNamespace, newName string) error {
+	project, err := self.findProject(namespace, name)
 	if err != nil {
-		logger.Log(kv{"fn": "cassandra_meta_store.authenticate", "msg": err.Error()})
-		return false
+		return err
 	}
-	cs := string(c)
-	i := strings.IndexByte(cs, ':')
-	if i < 0 {
-		return false
+	if err := self.AddProject(newNamespace, newName); err != nil {
+		return err
+	}
+	for _, oid := range project.Oids {
+		if err := self.addOidToProject(oid, newNamespace, newName); err != nil {
+			return err
+		}
 	}
-	user, password := cs[:i], cs[i+1:]
 
-	if Config.Ldap.Enabled {
-		return authenticateLdap(user, password)
+	oldKey, newKey := projectKey(namespace, name), projectKey(newNamespace, newName)
+	itr := self.client.Query("select id, path, owner, locked_at from locks where project = ?", oldKey).Iter()
+	var id, path, owner string
+	var lockedAt time.Time
+	for itr.Scan(&id, &path, &owner, &lockedAt) {
+		if err := self.client.Query("insert into locks (project, path, id, owner, locked_at) values (?, ?, ?, ?, ?)",
+			newKey, path, id, owner, lockedAt).Exec(); err != nil {
+			itr.Close()
+			return cassandraError("rename project", err)
+		}
 	}
-	mu, err := self.findUser(user)
+	if err := itr.Close(); err != nil {
+		return cassandraError("rename project", err)
+	}
+	if err := self.client.Query("delete from locks where project = ?", oldKey).Exec(); err != nil {
+		return cassandraError("rename project", err)
+	}
+	return self.removeProject(namespace, name)
+}
+
+/*
+Adds a lock to the project, fails with errLockExists when the path is already locked
+*/
+func (self *CassandraMetaStore) AddLock(v *RequestVars, lock *MetaLock) error {
+	lock.Project = v.Project()
+	applied, err := self.client.Query("insert into locks (project, path, id, owner, locked_at) values (?, ?, ?, ?, ?) if not exists",
+		lock.Project, lock.Path, lock.Id, lock.Owner, lock.LockedAt).MapScanCAS(make(map[string]interface{}))
 	if err != nil {
-		logger.Log(kv{"fn": "cassandra_meta_store", "msg": fmt.Sprintf("Auth error: %s", err.Error())})
-		return false
+		return cassandraError("add lock", err)
+	}
+	if !applied {
+		return errLockExists
 	}
+	return nil
+}
 
-	match, err := checkPass([]byte(mu.Password), []byte(password))
+/*
+Returns all locks of the project
+*/
+func (self *CassandraMetaStore) Locks(v *RequestVars) ([]*MetaLock, error) {
+	itr := self.client.Query("select id, path, owner, locked_at from locks where project = ?", v.Project()).Iter()
+	var id, path, owner string
+	var lockedAt time.Time
+	lock_list := make([]*MetaLock, 0)
+	for itr.Scan(&id, &path, &owner, &lockedAt) {
+		lock_list = append(lock_list, &MetaLock{Id: id, Path: path, Owner: owner, Project: v.Project(), LockedAt: lockedAt})
+	}
+	if err := itr.Close(); err != nil {
+		return nil, cassandraError("list locks", err)
+	}
+	return lock_list, nil
+}
+
+/*
+Removes a lock from the project
+*/
+func (self *CassandraMetaStore) DeleteLock(v *RequestVars, id string) error {
+	var path string
+	if err := self.client.Query("select path from locks where project = ? and id = ?", v.Project(), id).Scan(&path); err != nil {
+		if err == gocql.ErrNotFound {
+			return errLockNotFound
+		}
+		return cassandraError("delete lock", err)
+	}
+	return cassandraError("delete lock", self.client.Query("delete from locks where project = ? and path = ?", v.Project(), path).Exec())
+}
+
+/*
+Stores a personal access token, keyed by its hash
+*/
+func (self *CassandraMetaStore) AddToken(token *MetaToken) error {
+	return cassandraError("add token", self.client.Query("insert into tokens (hash, id, username, name, scope, projects, created_at) values (?, ?, ?, ?, ?, ?, ?)",
+		token.Hash, token.Id, token.User, token.Name, token.Scope, token.Projects, token.CreatedAt).Exec())
+}
+
+/*
+Returns the personal access tokens of user, or of all users when user is empty
+*/
+func (self *CassandraMetaStore) Tokens(user string) ([]*MetaToken, error) {
+	q := self.client.Query("select * from tokens")
+	if user != "" {
+		q = self.client.Query("select * from tokens where username = ?", user)
+	}
+	b := cqlr.BindQuery(q)
+	tokens := make([]*MetaToken, 0)
+	for {
+		var token MetaToken
+		if !b.Scan(&token) {
+			break
+		}
+		tokens = append(tokens, &token)
+	}
+	if err := b.Close(); err != nil {
+		return nil, cassandraError("list tokens", err)
+	}
+	return tokens, nil
+}
+
+/*
+Returns the personal access token with the given hash
+*/
+func (self *CassandraMetaStore) FindToken(hash string) (*MetaToken, error) {
+	var token MetaToken
+	b := cqlr.BindQuery(self.client.Query("select * from tokens where hash = ?", hash))
+	b.Scan(&token)
+	if err := b.Close(); err != nil {
+		return nil, cassandraError("find token", err)
+	}
+	if token.Hash == "" {
+		return nil, errTokenNotFound
+	}
+	return &token, nil
+}
+
+/*
+Revokes the personal access token with the given id
+*/
+func (self *CassandraMetaStore) DeleteToken(id string) error {
+	var hash string
+	if err := self.client.Query("select hash from tokens where id = ?", id).Scan(&hash); err != nil {
+		if err == gocql.ErrNotFound {
+			return errTokenNotFound
+		}
+		return cassandraError("delete token", err)
+	}
+	return cassandraError("delete token", self.client.Query("delete from tokens where hash = ?", hash).Exec())
+}
+
+/*
+Reports whether password is the password of the local user
+*/
+func (self *CassandraMetaStore) CheckPassword(user, password string) (bool, error) {
+	mu, err := self.findUser(user)
+	if err == errUserNotFound {
+		return false, nil
+	}
 	if err != nil {
-		logger.Log(kv{"fn": "cassandra_meta_store", "msg": fmt.Sprintf("Decrypt error: %s", err.Error())})
+		return false, err
 	}
-	return match
+	return checkPass([]byte(mu.Password), []byte(password))
 }
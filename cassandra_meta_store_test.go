@@ -17,12 +17,12 @@ func TestCassandraGetWithAuth(t *testing.T) {
 	}
 
 	defer teardownCassandraMeta()
-	metaFail, errA := metaStoreTestCassandra.Get(&RequestVars{Authorization: testAuth, Oid: noAuthOid})
+	metaFail, errA := metaStoreTestCassandra.Get(&RequestVars{Authorization: testAuth, Oid: noAuthOid, Namespace: testNamespace, Repo: testRepo})
 	if errA == nil {
 		t.Fatalf("Error Should not have access to OID: %s", metaFail.Oid)
 	}
 
-	meta, err := metaStoreTestCassandra.Get(&RequestVars{Authorization: testAuth, Oid: contentOid})
+	meta, err := metaStoreTestCassandra.Get(&RequestVars{Authorization: testAuth, Oid: contentOid, Namespace: testNamespace, Repo: testRepo})
 	if err != nil {
 		t.Fatalf("Error retreiving meta: %s", err)
 	}
@@ -36,7 +36,7 @@ func TestCassandraGetWithAuth(t *testing.T) {
 	}
 }
 
-func TestCassandraGetWithoutAuth(t *testing.T) {
+func TestCassandraCheckPassword(t *testing.T) {
 	serr := setupCassandraMeta()
 	if serr != nil {
 		t.Errorf(serr.Error())
@@ -44,9 +44,12 @@ func TestCassandraGetWithoutAuth(t *testing.T) {
 
 	defer teardownCassandraMeta()
 
-	_, err := metaStoreTestCassandra.Get(&RequestVars{Authorization: badAuth, Oid: contentOid})
-	if !isAuthError(err) {
-		t.Errorf("expected auth error, got: %s", err)
+	if ok, err := metaStoreTestCassandra.CheckPassword(testUser, testPass); !ok || err != nil {
+		t.Errorf("expected the password to match, got: %t %v", ok, err)
+	}
+
+	if ok, err := metaStoreTestCassandra.CheckPassword("nobody", testPass); ok || err != nil {
+		t.Errorf("expected an unknown user not to match, got: %t %v", ok, err)
 	}
 }
 
@@ -93,7 +96,7 @@ func TestCassandraPutWithAuth(t *testing.T) {
 
 	defer teardownCassandraMeta()
 
-	meta, err := metaStoreTestCassandra.Put(&RequestVars{Authorization: testAuth, Oid: nonexistingOid, Size: 42})
+	meta, err := metaStoreTestCassandra.Put(&RequestVars{Authorization: testAuth, Oid: nonexistingOid, Size: 42, Namespace: testNamespace, Repo: testRepo})
 	if err != nil {
 		t.Errorf("expected put to succeed, got : %s", err)
 	}
@@ -102,7 +105,7 @@ func TestCassandraPutWithAuth(t *testing.T) {
 		t.Errorf("expected meta to not have existed")
 	}
 
-	meta, err = metaStoreTestCassandra.Get(&RequestVars{Authorization: testAuth, Oid: nonexistingOid})
+	meta, err = metaStoreTestCassandra.Get(&RequestVars{Authorization: testAuth, Oid: nonexistingOid, Namespace: testNamespace, Repo: testRepo})
 	if err != nil {
 		t.Errorf("expected to be able to retreive new put, got : %s", err)
 	}
@@ -115,7 +118,7 @@ func TestCassandraPutWithAuth(t *testing.T) {
 		t.Errorf("expected sizes to match, got: %d", meta.Size)
 	}
 
-	meta, err = metaStoreTestCassandra.Put(&RequestVars{Authorization: testAuth, Oid: nonexistingOid, Size: 42})
+	meta, err = metaStoreTestCassandra.Put(&RequestVars{Authorization: testAuth, Oid: nonexistingOid, Size: 42, Namespace: testNamespace, Repo: testRepo})
 	if err != nil {
 		t.Errorf("expected put to succeed, got : %s", err)
 	}
@@ -125,21 +128,29 @@ func TestCassandraPutWithAuth(t *testing.T) {
 	}
 }
 
-func TestCassandraPutWithoutAuth(t *testing.T) {
+func TestCassandraGetFromOtherProject(t *testing.T) {
 	serr := setupCassandraMeta()
 	if serr != nil {
 		t.Errorf(serr.Error())
 	}
 	defer teardownCassandraMeta()
 
-	_, err := metaStoreTestCassandra.Put(&RequestVars{Authorization: badAuth, User: testUser, Oid: contentOid, Size: 42})
-	if !isAuthError(err) {
-		t.Errorf("expected auth error, got: %s", err)
+	_, err := metaStoreTestCassandra.Get(&RequestVars{Authorization: testAuth, Oid: contentOid, Namespace: testNamespace, Repo: extraRepo})
+	if err != errObjectNotFound {
+		t.Errorf("expected object not found in %s, got: %v", extraRepo, err)
+	}
+
+	meta, err := metaStoreTestCassandra.Put(&RequestVars{Authorization: testAuth, Oid: contentOid, Size: contentSize, Namespace: testNamespace, Repo: extraRepo})
+	if err != nil {
+		t.Fatalf("expected put to succeed, got : %s", err)
 	}
 
-	_, errPut := metaStoreTestCassandra.Put(&RequestVars{Authorization: testAuth, User: testUser, Oid: contentOid, Size: 42, Repo: testRepo})
-	if errPut != nil {
-		t.Errorf("Unexpected error in Put: %s", errPut)
+	if !meta.Existing {
+		t.Errorf("expected meta to exist")
+	}
+
+	if _, err := metaStoreTestCassandra.Get(&RequestVars{Authorization: testAuth, Oid: contentOid, Namespace: testNamespace, Repo: extraRepo}); err != nil {
+		t.Errorf("expected object to be linked into %s, got: %s", extraRepo, err)
 	}
 }
 
@@ -193,7 +204,7 @@ func TestCassandraProjects(t *testing.T) {
 	}
 	defer teardownCassandraMeta()
 
-	err = metaStoreTestCassandra.createProject(extraRepo)
+	err = metaStoreTestCassandra.createProject(testNamespace, extraRepo)
 	if err != nil {
 		t.Errorf("Failed to create project")
 	}
@@ -212,7 +223,7 @@ func TestCassandraProjects(t *testing.T) {
 		t.Errorf("Failed finding project %s", extraRepo)
 	}
 
-	proj, err := metaStoreTestCassandra.findProject(extraRepo)
+	proj, err := metaStoreTestCassandra.findProject(testNamespace, extraRepo)
 	if err != nil {
 		t.Errorf("Failed to find project")
 	}
@@ -221,7 +232,7 @@ func TestCassandraProjects(t *testing.T) {
 		t.Errorf("Failed to find project, got wrong name in response %s", proj.Name)
 	}
 
-	_, err = metaStoreTestCassandra.findProject("")
+	_, err = metaStoreTestCassandra.findProject(testNamespace, "")
 	if err == nil {
 		t.Errorf("Expected error but got none")
 	}
@@ -231,12 +242,12 @@ func TestCassandraProjects(t *testing.T) {
 		t.Errorf("Failed getting cassandra projects")
 	}
 
-	delErr := metaStoreTestCassandra.removeProject(extraRepo)
+	delErr := metaStoreTestCassandra.removeProject(testNamespace, extraRepo)
 	if delErr != nil {
 		t.Errorf("Failed to delete project")
 	}
 
-	_, findPErrEmpty := metaStoreTestCassandra.findProject(extraRepo)
+	_, findPErrEmpty := metaStoreTestCassandra.findProject(testNamespace, extraRepo)
 	if findPErrEmpty == nil {
 		t.Errorf("findProject should have raised an error")
 	}
@@ -250,22 +261,164 @@ func TestProjectOidRelationship(t *testing.T) {
 	}
 	defer teardownCassandraMeta()
 
-	err = metaStoreTestCassandra.createProject(testRepo)
+	err = metaStoreTestCassandra.createProject(testNamespace, testRepo)
 	if err != nil {
 		t.Errorf("Failed creating project")
 	}
-	err = metaStoreTestCassandra.addOidToProject(contentOid, testRepo)
+	err = metaStoreTestCassandra.addOidToProject(contentOid, testNamespace, testRepo)
 	if err != nil {
 		t.Errorf("Failed adding OID to project")
 	}
-	err = metaStoreTestCassandra.removeOidFromProject(contentOid, testRepo)
+	err = metaStoreTestCassandra.removeOidFromProject(contentOid, testNamespace, testRepo)
 	if err != nil {
 		t.Errorf("Failed removing OID from project %s", err.Error())
 	}
 }
 
+func TestCassandraDeleteProjectAndObject(t *testing.T) {
+	err := setupCassandraMeta()
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+	defer teardownCassandraMeta()
+
+	if err := metaStoreTestCassandra.DeleteProject(testNamespace, testRepo); err != nil {
+		t.Fatalf("Failed deleting project %s", err.Error())
+	}
+
+	if _, err := metaStoreTestCassandra.findProject(testNamespace, testRepo); err != errProjectNotFound {
+		t.Errorf("Expected errProjectNotFound but got %v", err)
+	}
+
+	if err := metaStoreTestCassandra.DeleteObject(contentOid); err != nil {
+		t.Fatalf("Failed deleting oid %s", err.Error())
+	}
+
+	if _, err := metaStoreTestCassandra.findOid(contentOid); err != errObjectNotFound {
+		t.Errorf("Expected errObjectNotFound but got %v", err)
+	}
+}
+
+func TestCassandraLocks(t *testing.T) {
+	err := setupCassandraMeta()
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+	defer teardownCassandraMeta()
+
+	rv := &RequestVars{Authorization: testAuth, Namespace: testNamespace, Repo: testRepo}
+	err = metaStoreTestCassandra.AddLock(rv, &MetaLock{Id: "lock1", Path: "a/b.bin", Owner: testUser})
+	if err != nil {
+		t.Errorf("Failed adding lock %s", err.Error())
+	}
+
+	err = metaStoreTestCassandra.AddLock(rv, &MetaLock{Id: "lock2", Path: "a/b.bin", Owner: testUser})
+	if err != errLockExists {
+		t.Errorf("Expected errLockExists but got %v", err)
+	}
+
+	locks, err := metaStoreTestCassandra.Locks(rv)
+	if err != nil || len(locks) != 1 {
+		t.Errorf("Expected 1 lock, got %d %v", len(locks), err)
+	}
+
+	err = metaStoreTestCassandra.DeleteLock(rv, "lock1")
+	if err != nil {
+		t.Errorf("Failed deleting lock %s", err.Error())
+	}
+
+	err = metaStoreTestCassandra.DeleteLock(rv, "lock1")
+	if err != errLockNotFound {
+		t.Errorf("Expected errLockNotFound but got %v", err)
+	}
+}
+
+func TestCassandraTokens(t *testing.T) {
+	err := setupCassandraMeta()
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+	defer teardownCassandraMeta()
+
+	testTokenStore(t, metaStoreTestCassandra)
+}
+
+func TestCassandraProjectConformance(t *testing.T) {
+	err := setupCassandraMeta()
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+	defer teardownCassandraMeta()
+
+	testProjectStore(t, metaStoreTestCassandra)
+}
+
+func TestCassandraUpgradeLegacyProjects(t *testing.T) {
+	serr := setupCassandraMeta()
+	if serr != nil {
+		t.Fatalf(serr.Error())
+	}
+	defer teardownCassandraMeta()
+	defer func(namespace string) { Config.LegacyNamespace = namespace }(Config.LegacyNamespace)
+	Config.LegacyNamespace = testNamespace
+
+	// the projects table from before namespaces, keyed by name alone
+	for _, q := range []string{
+		"drop table projects;",
+		"create table projects (name text PRIMARY KEY, oids SET<text>);",
+		"create index if not exists on projects(oids);",
+	} {
+		if err := metaStoreTestCassandra.client.Query(q).Exec(); err != nil {
+			t.Fatalf("expected %q to succeed, got: %s", q, err)
+		}
+	}
+	if err := metaStoreTestCassandra.client.Query("insert into projects (name, oids) values (?, ?);", testRepo, []string{contentOid}).Exec(); err != nil {
+		t.Fatalf("expected insert to succeed, got: %s", err)
+	}
+
+	store, err := NewCassandraMetaStore()
+	if err != nil {
+		t.Fatalf("expected the legacy projects to be upgraded, got: %s", err)
+	}
+	defer store.Close()
+
+	meta, err := store.Get(&RequestVars{Authorization: testAuth, Oid: contentOid, Namespace: testNamespace, Repo: testRepo})
+	if err != nil || meta.Size != contentSize {
+		t.Errorf("expected the object to be found in %s/%s, got: %v %v", testNamespace, testRepo, meta, err)
+	}
+	projects, err := store.Projects()
+	if err != nil || len(projects) != 1 || projects[0].Key() != projectKey(testNamespace, testRepo) {
+		t.Errorf("expected the project to be moved into %s, got: %v %v", testNamespace, projects, err)
+	}
+}
+
+func TestCassandraUpgradeKeepsNamespacedProjects(t *testing.T) {
+	serr := setupCassandraMeta()
+	if serr != nil {
+		t.Fatalf(serr.Error())
+	}
+	defer teardownCassandraMeta()
+
+	for _, namespace := range []string{"teamA", "teamB"} {
+		if _, err := metaStoreTestCassandra.Put(&RequestVars{Authorization: testAuth, Oid: contentOid, Size: contentSize, Namespace: namespace, Repo: "tools"}); err != nil {
+			t.Fatalf("expected put to succeed, got: %s", err)
+		}
+	}
+
+	store, err := NewCassandraMetaStore()
+	if err != nil {
+		t.Fatalf("expected to open the store again, got: %s", err)
+	}
+	defer store.Close()
+	for _, namespace := range []string{"teamA", "teamB"} {
+		if _, err := store.Get(&RequestVars{Authorization: testAuth, Oid: contentOid, Namespace: namespace, Repo: "tools"}); err != nil {
+			t.Errorf("expected %s/tools to keep its object, got: %s", namespace, err)
+		}
+	}
+}
+
 func setupCassandraMeta() error {
-	store, err := NewCassandraMetaStore(NewCassandraSession())
+	store, err := NewCassandraMetaStore()
 	if err != nil {
 		fmt.Printf("error initializing test meta store: %s\n", err)
 		return errors.New(fmt.Sprintf("error initializing test meta store: %s\n", err))
@@ -278,7 +431,7 @@ func setupCassandraMeta() error {
 		return errors.New(fmt.Sprintf("error adding test user to meta store: %s\n", err))
 	}
 
-	rv := &RequestVars{Authorization: testAuth, Oid: contentOid, Size: contentSize, Repo: testRepo}
+	rv := &RequestVars{Authorization: testAuth, Oid: contentOid, Size: contentSize, Namespace: testNamespace, Repo: testRepo}
 	if _, err := metaStoreTestCassandra.Put(rv); err != nil {
 		teardownCassandraMeta()
 		fmt.Printf("error seeding cassandra test meta store: %s\n", err)
@@ -288,5 +441,7 @@ func setupCassandraMeta() error {
 }
 
 func teardownCassandraMeta() {
-	DropCassandra(NewCassandraSession().Client)
+	if metaStoreTestCassandra != nil {
+		DropCassandra(metaStoreTestCassandra.client)
+	}
 }
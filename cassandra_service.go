@@ -2,6 +2,9 @@ package main
 
 import (
 	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
 
 	"github.com/gocql/gocql"
 )
@@ -10,28 +13,134 @@ type CassandraService struct {
 	Client *gocql.Session
 }
 
-// TODO: Add auth for cassandra
-func NewCassandraSession() *CassandraService {
-	cluster := gocql.NewCluster(Config.Cassandra.Hosts)
-	cluster.ProtoVersion = Config.Cassandra.ProtoVersion
-	q := fmt.Sprintf("create keyspace if not exists %s_%s with replication = { 'class' : 'SimpleStrategy', 'replication_factor' : 1 };", Config.Cassandra.Keyspace, GoEnv)
+func NewCassandraSession() (*CassandraService, error) {
+	cluster, err := newCassandraCluster()
+	if err != nil {
+		return nil, err
+	}
+	replication, err := cassandraReplication()
+	if err != nil {
+		return nil, err
+	}
+
+	keyspace := fmt.Sprintf("%s_%s", Config.Cassandra.Keyspace, GoEnv)
 	session, err := cluster.CreateSession()
+	if err != nil {
+		return nil, cassandraError("connect", err)
+	}
+	q := fmt.Sprintf("create keyspace if not exists %s with replication = %s;", keyspace, replication)
 	err = session.Query(q).Exec()
 	session.Close()
-	cluster.Keyspace = fmt.Sprintf("%s_%s", Config.Cassandra.Keyspace, GoEnv)
-	cluster.Consistency = gocql.Quorum
+	if err != nil {
+		return nil, cassandraError("create keyspace", err)
+	}
+
+	cluster.Keyspace = keyspace
 	session, err = cluster.CreateSession()
-	perror(initializeCassandra(session))
-	perror(err)
-	logger.Log(kv{"fn": "cassandra_service", "msg": fmt.Sprintf("Connecting to host '%s'\n", Config.Cassandra.Hosts)})
-	logger.Log(kv{"fn": "cassandra_service", "msg": fmt.Sprintf("Cassandra.namespace '%s_%s'\n", Config.Cassandra.Keyspace, GoEnv)})
-	return &CassandraService{Client: session}
+	if err != nil {
+		return nil, cassandraError("connect", err)
+	}
+	if err := initializeCassandra(session, keyspace); err != nil {
+		session.Close()
+		return nil, cassandraError("create tables", err)
+	}
+	logger.Log(kv{"fn": "cassandra_service", "msg": fmt.Sprintf("Connecting to hosts '%s'\n", strings.Join(cluster.Hosts, ", "))})
+	logger.Log(kv{"fn": "cassandra_service", "msg": fmt.Sprintf("Cassandra.namespace '%s'\n", keyspace)})
+	return &CassandraService{Client: session}, nil
+}
+
+// newCassandraCluster configures the hosts, credentials, TLS, consistency and data center
+// of the cluster from Config.Cassandra
+func newCassandraCluster() (*gocql.ClusterConfig, error) {
+	config := Config.Cassandra
+	hosts := cassandraHosts(config.Hosts)
+	if len(hosts) == 0 {
+		return nil, fmt.Errorf("No Cassandra hosts configured")
+	}
+	consistency, err := gocql.ParseConsistencyWrapper(config.Consistency)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid Cassandra consistency %q", config.Consistency)
+	}
+
+	cluster := gocql.NewCluster(hosts...)
+	cluster.ProtoVersion = config.ProtoVersion
+	cluster.Consistency = consistency
+	if config.Username != "" {
+		cluster.Authenticator = gocql.PasswordAuthenticator{Username: config.Username, Password: config.Password}
+	}
+	if config.TLS || config.CACert != "" {
+		cluster.SslOpts = &gocql.SslOptions{CaPath: config.CACert, EnableHostVerification: !config.SkipVerify}
+	}
+	if config.LocalDC != "" {
+		cluster.PoolConfig.HostSelectionPolicy = gocql.TokenAwareHostPolicy(gocql.DCAwareRoundRobinPolicy(config.LocalDC))
+	}
+	return cluster, nil
 }
 
-func initializeCassandra(session *gocql.Session) error {
+// cassandraHosts splits a comma separated list of host[:port]
+func cassandraHosts(s string) []string {
+	var hosts []string
+	for _, h := range strings.Split(s, ",") {
+		if h = strings.TrimSpace(h); h != "" {
+			hosts = append(hosts, h)
+		}
+	}
+	return hosts
+}
+
+var cassandraDCName = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+// cassandraReplication returns the replication map of the keyspace, Replicas copies with
+// SimpleStrategy, or the copies in each of DataCenters, e.g. "dc1:3, dc2:3", with
+// NetworkTopologyStrategy
+func cassandraReplication() (string, error) {
+	config := Config.Cassandra
+	switch strings.ToLower(strings.TrimSpace(config.Strategy)) {
+	case "", "simplestrategy":
+		if config.Replicas < 1 {
+			return "", fmt.Errorf("Invalid Cassandra replicas %d, expected at least 1", config.Replicas)
+		}
+		return fmt.Sprintf("{ 'class' : 'SimpleStrategy', 'replication_factor' : %d }", config.Replicas), nil
+	case "networktopologystrategy":
+		var dcs []string
+		for _, dc := range strings.Split(config.DataCenters, ",") {
+			if dc = strings.TrimSpace(dc); dc == "" {
+				continue
+			}
+			parts := strings.Split(dc, ":")
+			if len(parts) != 2 || !cassandraDCName.MatchString(strings.TrimSpace(parts[0])) {
+				return "", fmt.Errorf("Invalid Cassandra data center %q, expected name:replicas", dc)
+			}
+			replicas, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+			if err != nil || replicas < 1 {
+				return "", fmt.Errorf("Invalid replicas in Cassandra data center %q", dc)
+			}
+			dcs = append(dcs, fmt.Sprintf("'%s' : %d", strings.TrimSpace(parts[0]), replicas))
+		}
+		if len(dcs) == 0 {
+			return "", fmt.Errorf("NetworkTopologyStrategy needs DataCenters, e.g. dc1:3")
+		}
+		return fmt.Sprintf("{ 'class' : 'NetworkTopologyStrategy', %s }", strings.Join(dcs, ", ")), nil
+	}
+	return "", fmt.Errorf("Unknown Cassandra replication strategy %q", config.Strategy)
+}
+
+func initializeCassandra(session *gocql.Session, keyspace string) error {
+	// projects keyed by name alone, from before namespaces, are set aside until the
+	// table is created again with namespaces
+	err := setAsideLegacyProjects(session, keyspace)
+	if err != nil {
+		return err
+	}
+
 	// projects table
-	q := fmt.Sprintf("create table if not exists projects (name text PRIMARY KEY, oids SET<text>);")
-	err := session.Query(q).Exec()
+	q := fmt.Sprintf("create table if not exists projects (namespace text, name text, oids SET<text>, PRIMARY KEY (namespace, name));")
+	err = session.Query(q).Exec()
+	if err != nil {
+		return err
+	}
+
+	err = restoreLegacyProjects(session, keyspace)
 	if err != nil {
 		return err
 	}
@@ -45,20 +154,120 @@ func initializeCassandra(session *gocql.Session) error {
 
 	// Oids table
 	q = fmt.Sprintf("create table if not exists oids(oid text primary key, size bigint);")
-	session.Query(q).Exec()
+	err = session.Query(q).Exec()
 	if err != nil {
 		return err
 	}
 
 	// user management
 	q = fmt.Sprintf("create table if not exists users(username text primary key, password text);")
+	err = session.Query(q).Exec()
+	if err != nil {
+		return err
+	}
+
+	// file locks, one per path in a project
+	q = fmt.Sprintf("create table if not exists locks(project text, path text, id text, owner text, locked_at timestamp, primary key (project, path));")
+	err = session.Query(q).Exec()
+	if err != nil {
+		return err
+	}
+
+	// create an index so we can search on lock ids
+	q = fmt.Sprintf("create index if not exists on locks(id);")
+	err = session.Query(q).Exec()
+	if err != nil {
+		return err
+	}
+
+	// personal access tokens, looked up by the hash of the token
+	q = fmt.Sprintf("create table if not exists tokens(hash text primary key, id text, username text, name text, scope text, projects list<text>, created_at timestamp);")
+	err = session.Query(q).Exec()
+	if err != nil {
+		return err
+	}
+
+	q = fmt.Sprintf("create index if not exists on tokens(id);")
+	err = session.Query(q).Exec()
+	if err != nil {
+		return err
+	}
+
+	q = fmt.Sprintf("create index if not exists on tokens(username);")
 	return session.Query(q).Exec()
 }
 
+// cassandraColumns returns the columns of table in keyspace from the schema, none when
+// the table doesn't exist
+func cassandraColumns(session *gocql.Session, keyspace, table string) (map[string]bool, error) {
+	columns := make(map[string]bool)
+	var column string
+	iter := session.Query("select column_name from system_schema.columns where keyspace_name = ? and table_name = ?;", keyspace, table).Iter()
+	for iter.Scan(&column) {
+		columns[column] = true
+	}
+	return columns, iter.Close()
+}
+
+// setAsideLegacyProjects copies a projects table keyed by name alone to
+// legacy_projects and drops it, Cassandra can't change the primary key of a table
+func setAsideLegacyProjects(session *gocql.Session, keyspace string) error {
+	columns, err := cassandraColumns(session, keyspace, "projects")
+	if err != nil {
+		return err
+	}
+	if len(columns) == 0 || columns["namespace"] {
+		// no projects table yet, or namespaced already
+		return nil
+	}
+
+	q := fmt.Sprintf("create table if not exists legacy_projects (name text PRIMARY KEY, oids SET<text>);")
+	if err := session.Query(q).Exec(); err != nil {
+		return err
+	}
+	var name string
+	var oids []string
+	iter := session.Query("select name, oids from projects;").Iter()
+	for iter.Scan(&name, &oids) {
+		if err := session.Query("insert into legacy_projects (name, oids) values (?, ?);", name, oids).Exec(); err != nil {
+			iter.Close()
+			return err
+		}
+	}
+	if err := iter.Close(); err != nil {
+		return err
+	}
+	return session.Query("drop table projects;").Exec()
+}
+
+// restoreLegacyProjects moves the projects set aside by setAsideLegacyProjects into
+// Config.LegacyNamespace, finishing upgrades that were interrupted as well
+func restoreLegacyProjects(session *gocql.Session, keyspace string) error {
+	columns, err := cassandraColumns(session, keyspace, "legacy_projects")
+	if err != nil || len(columns) == 0 {
+		// nothing set aside
+		return err
+	}
+
+	var name string
+	var oids []string
+	iter := session.Query("select name, oids from legacy_projects;").Iter()
+	for iter.Scan(&name, &oids) {
+		if err := session.Query("insert into projects (namespace, name, oids) values (?, ?, ?);", Config.LegacyNamespace, name, oids).Exec(); err != nil {
+			iter.Close()
+			return err
+		}
+		logger.Log(kv{"fn": "restoreLegacyProjects", "project": projectKey(Config.LegacyNamespace, name)})
+	}
+	if err := iter.Close(); err != nil {
+		return err
+	}
+	return session.Query("drop table legacy_projects;").Exec()
+}
+
 func DropCassandra(session *gocql.Session) error {
 	config := Config.Cassandra
 	m := fmt.Sprintf("%s_%s", config.Keyspace, GoEnv)
 	q := fmt.Sprintf("drop keyspace %s;", m)
-	c := NewCassandraSession().Client
-	return c.Query(q).Exec()
+	return session.Query(q).Exec()
 }
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/gocql/gocql"
+)
+
+func withCassandraConfig(c CassandraConfig) func() {
+	old := Config.Cassandra
+	Config.Cassandra = &c
+	return func() { Config.Cassandra = old }
+}
+
+func TestCassandraHosts(t *testing.T) {
+	hosts := cassandraHosts(" one:9042, two ,,three:9142")
+	if len(hosts) != 3 || hosts[0] != "one:9042" || hosts[1] != "two" || hosts[2] != "three:9142" {
+		t.Errorf("expected three hosts, got: %q", hosts)
+	}
+}
+
+func TestCassandraReplication(t *testing.T) {
+	defer withCassandraConfig(CassandraConfig{Strategy: "SimpleStrategy", Replicas: 3})()
+	if r, err := cassandraReplication(); err != nil || r != "{ 'class' : 'SimpleStrategy', 'replication_factor' : 3 }" {
+		t.Errorf("expected SimpleStrategy with 3 replicas, got: %s %v", r, err)
+	}
+
+	Config.Cassandra.Strategy = "NetworkTopologyStrategy"
+	Config.Cassandra.DataCenters = "dc1:3, eu-west:2"
+	if r, err := cassandraReplication(); err != nil || r != "{ 'class' : 'NetworkTopologyStrategy', 'dc1' : 3, 'eu-west' : 2 }" {
+		t.Errorf("expected replicas per data center, got: %s %v", r, err)
+	}
+
+	for _, dcs := range []string{"", "dc1", "dc1:none", "dc1:0", "dc'1:3"} {
+		Config.Cassandra.DataCenters = dcs
+		if _, err := cassandraReplication(); err == nil {
+			t.Errorf("expected data centers %q to be rejected", dcs)
+		}
+	}
+
+	Config.Cassandra.Strategy = "EverywhereStrategy"
+	if _, err := cassandraReplication(); err == nil {
+		t.Errorf("expected an unknown strategy to be rejected")
+	}
+}
+
+func TestCassandraCluster(t *testing.T) {
+	defer withCassandraConfig(CassandraConfig{
+		Hosts:       "one:9042, two:9042",
+		Username:    "lfs",
+		Password:    "secret",
+		TLS:         true,
+		CACert:      "ca.pem",
+		Consistency: "local_quorum",
+		LocalDC:     "dc1",
+	})()
+
+	cluster, err := newCassandraCluster()
+	if err != nil {
+		t.Fatalf("expected a cluster, got: %s", err)
+	}
+	if len(cluster.Hosts) != 2 {
+		t.Errorf("expected two hosts, got: %q", cluster.Hosts)
+	}
+	if cluster.Consistency != gocql.LocalQuorum {
+		t.Errorf("expected LOCAL_QUORUM, got: %s", cluster.Consistency)
+	}
+	if auth, ok := cluster.Authenticator.(gocql.PasswordAuthenticator); !ok || auth.Username != "lfs" || auth.Password != "secret" {
+		t.Errorf("expected password authentication, got: %+v", cluster.Authenticator)
+	}
+	if cluster.SslOpts == nil || cluster.SslOpts.CaPath != "ca.pem" || !cluster.SslOpts.EnableHostVerification {
+		t.Errorf("expected TLS verified against ca.pem, got: %+v", cluster.SslOpts)
+	}
+	if cluster.PoolConfig.HostSelectionPolicy == nil {
+		t.Errorf("expected the hosts of dc1 to be preferred")
+	}
+
+	Config.Cassandra.Consistency = "most"
+	if _, err := newCassandraCluster(); err == nil {
+		t.Errorf("expected an invalid consistency to be rejected")
+	}
+	Config.Cassandra.Consistency = "quorum"
+	Config.Cassandra.Hosts = " , "
+	if _, err := newCassandraCluster(); err == nil {
+		t.Errorf("expected an empty host list to be rejected")
+	}
+}
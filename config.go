@@ -5,6 +5,7 @@ import (
 	"os"
 	"runtime"
 	"strings"
+	"time"
 
 	"github.com/fatih/structs"
 	"gopkg.in/ini.v1"
@@ -16,26 +17,49 @@ type CassandraConfig struct {
 	ProtoVersion int    `json:"ProtoVersion"`
 	Username     string `json:"username"`
 	Password     string `json:"password"`
+	TLS          bool   `json:"tls"`
+	CACert       string `json:"cacert"`
+	SkipVerify   bool   `json:"skipverify"`
+	Strategy     string `json:"strategy"`
+	Replicas     int    `json:"replicas"`
+	DataCenters  string `json:"datacenters"`
+	Consistency  string `json:"consistency"`
+	LocalDC      string `json:"localdc"`
 	Enabled      bool   `json:"enabled"`
 }
 
 type AwsConfig struct {
-	AccessKeyId     string `json:"accesskeyid"`
-	SecretAccessKey string `json:"secretaccesskey"`
-	Region          string `json:"region"`
-	BucketName      string `json:"bucketname"`
-	BucketAcl       string `json:"bucketacl"`
-	Enabled         bool   `json:"enabled"`
+	AccessKeyId     string        `json:"accesskeyid"`
+	SecretAccessKey string        `json:"secretaccesskey"`
+	Region          string        `json:"region"`
+	BucketName      string        `json:"bucketname"`
+	BucketAcl       string        `json:"bucketacl"`
+	Endpoint        string        `json:"endpoint"`
+	PartSize        int64         `json:"partsize"`
+	DirectLinks     bool          `json:"directlinks"`
+	LinkTTL         time.Duration `json:"linkttl"`
+	Enabled         bool          `json:"enabled"`
 }
 
 type LdapConfig struct {
-	Enabled         bool   `json:"enabled"`
-	Server          string `json:"server"`
-	Base            string `json:"base"`
-	UserObjectClass string `json:"userobjectclass"`
-	UserCn          string `json:"usercn"`
-	BindDn          string `json:"binddn"`
-	BindPass        string `json:"bindpass"`
+	Enabled         bool          `json:"enabled"`
+	Server          string        `json:"server"`
+	Base            string        `json:"base"`
+	UserObjectClass string        `json:"userobjectclass"`
+	UserCn          string        `json:"usercn"`
+	BindDn          string        `json:"binddn"`
+	BindPass        string        `json:"bindpass"`
+	StartTLS        bool          `json:"starttls"`
+	CACert          string        `json:"cacert"`
+	SkipVerify      bool          `json:"skipverify"`
+	Timeout         time.Duration `json:"timeout"`
+	PoolSize        int           `json:"poolsize"`
+	Groups          string        `json:"groups"`
+	GroupLookup     string        `json:"grouplookup"`
+	GroupBase       string        `json:"groupbase"`
+	GroupClass      string        `json:"groupclass"`
+	GroupMember     string        `json:"groupmember"`
+	GroupTTL        time.Duration `json:"groupttl"`
 }
 
 /*
@@ -53,29 +77,63 @@ type MySQLConfig struct {
 	Enabled  bool   `json:"enabled"`
 }
 
+type PostgresConfig struct {
+	Host     string `json:"host"`
+	Database string `json:"database"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+	SSLMode  string `json:"sslmode"`
+	Enabled  bool   `json:"enabled"`
+}
+
+/*
+UserServiceConfig (external authorization service, see consumers_spec.md)
+  => Url      :- user service endpoint e.g http://localhost:9090/access
+  => Timeout  :- how long to wait for the user service to respond
+  => CacheTTL :- how long to remember a response, 0 disables caching
+*/
+type UserServiceConfig struct {
+	Url      string        `json:"url"`
+	Timeout  time.Duration `json:"timeout"`
+	CacheTTL time.Duration `json:"cache_ttl"`
+	Enabled  bool          `json:"enabled"`
+}
+
 // Configuration holds application configuration. Values will be pulled from
 // environment variables, prefixed by keyPrefix. Default values can be added
 // via tags.
 type Configuration struct {
-	Listen       string           `json:"listen"`
-	Host         string           `json:"host"`
-	UrlContext   string           `json:"url_context"`
-	ContentPath  string           `json:"content_path"`
-	AdminUser    string           `json:"admin_user"`
-	AdminPass    string           `json:"admin_pass"`
-	Cert         string           `json:"cert"`
-	Key          string           `json:"key"`
-	Scheme       string           `json:"scheme"`
-	Public       bool             `json:"public"`
-	MetaDB       string           `json:"metadb"`
-	BackingStore string           `json:"backing_store"`
-	ContentStore string           `json:"content_store"`
-	LogFile      string           `json:"logfile"`
-	NumProcs     int              `json:"numprocs"`
-	Aws          *AwsConfig       `json:"aws"`
-	Cassandra    *CassandraConfig `json:"cassandra"`
-	Ldap         *LdapConfig      `json:"ldap"`
-	MySQL        *MySQLConfig     `json:"mysql"`
+	Listen          string             `json:"listen"`
+	Host            string             `json:"host"`
+	UrlContext      string             `json:"url_context"`
+	ContentPath     string             `json:"content_path"`
+	AdminUser       string             `json:"admin_user"`
+	AdminPass       string             `json:"admin_pass"`
+	Cert            string             `json:"cert"`
+	Key             string             `json:"key"`
+	Scheme          string             `json:"scheme"`
+	Public          bool               `json:"public"`
+	MetaDB          string             `json:"metadb"`
+	SQLiteDB        string             `json:"sqlite_db"`
+	BackingStore    string             `json:"backing_store"`
+	ContentStore    string             `json:"content_store"`
+	LogFile         string             `json:"logfile"`
+	NumProcs        int                `json:"numprocs"`
+	GCGracePeriod   time.Duration      `json:"gc_grace_period"`
+	TokenSecret     string             `json:"token_secret"`
+	TokenTTL        time.Duration      `json:"token_ttl"`
+	AuthProviders   string             `json:"auth_providers"`
+	HtpasswdFile    string             `json:"htpasswd_file"`
+	AuthCacheTTL    time.Duration      `json:"auth_cache_ttl"`
+	StoreRetries    int                `json:"store_retries"`
+	StoreBackoff    time.Duration      `json:"store_backoff"`
+	LegacyNamespace string             `json:"legacy_namespace"`
+	Aws             *AwsConfig         `json:"aws"`
+	Cassandra       *CassandraConfig   `json:"cassandra"`
+	Ldap            *LdapConfig        `json:"ldap"`
+	MySQL           *MySQLConfig       `json:"mysql"`
+	Postgres        *PostgresConfig    `json:"postgres"`
+	UserService     *UserServiceConfig `json:"user_service"`
 }
 
 func (c *Configuration) IsHTTPS() bool {
@@ -100,7 +158,8 @@ var Config = &Configuration{}
 func init() {
 	configFile := os.Getenv("LFS_SERVER_GO_CONFIG")
 	if configFile == "" {
-		fmt.Println("LFS_SERVER_GO_CONFIG is not set, Using default config.ini")
+		// stderr, git-lfs-authenticate answers git-lfs on stdout
+		fmt.Fprintln(os.Stderr, "LFS_SERVER_GO_CONFIG is not set, Using default config.ini")
 		configFile = "config.ini"
 	}
 
@@ -130,6 +189,10 @@ func init() {
 		Region:          "USWest",
 		BucketName:      "lfs-server-go-objects",
 		BucketAcl:       "bucket-owner-full-control",
+		Endpoint:        "",
+		PartSize:        minPartSize,
+		DirectLinks:     false,
+		LinkTTL:         15 * time.Minute,
 		Enabled:         false,
 	}
 	ldapConfig := &LdapConfig{
@@ -140,6 +203,17 @@ func init() {
 		UserCn:          "uid",
 		BindDn:          "",
 		BindPass:        "",
+		StartTLS:        false,
+		CACert:          "",
+		SkipVerify:      false,
+		Timeout:         10 * time.Second,
+		PoolSize:        4,
+		Groups:          "",
+		GroupLookup:     "memberOf",
+		GroupBase:       "",
+		GroupClass:      "groupOfNames",
+		GroupMember:     "member",
+		GroupTTL:        5 * time.Minute,
 	}
 	cassandraConfig := &CassandraConfig{
 		Hosts:        "localhost",
@@ -147,6 +221,14 @@ func init() {
 		ProtoVersion: 3,
 		Username:     "",
 		Password:     "",
+		TLS:          false,
+		CACert:       "",
+		SkipVerify:   false,
+		Strategy:     "SimpleStrategy",
+		Replicas:     1,
+		DataCenters:  "",
+		Consistency:  "QUORUM",
+		LocalDC:      "",
 		Enabled:      false,
 	}
 	mysqlConfig := &MySQLConfig{
@@ -156,31 +238,59 @@ func init() {
 		Password: "",
 		Enabled:  false,
 	}
+	postgresConfig := &PostgresConfig{
+		Host:     "",
+		Database: "lfs_server_go",
+		Username: "",
+		Password: "",
+		SSLMode:  "require",
+		Enabled:  false,
+	}
+	userServiceConfig := &UserServiceConfig{
+		Url:      "",
+		Timeout:  5 * time.Second,
+		CacheTTL: 60 * time.Second,
+		Enabled:  false,
+	}
 	configuration := &Configuration{
-		Listen:       "tcp://:8080",
-		Host:         "localhost:8080",
-		UrlContext:   "",
-		ContentPath:  "lfs-content",
-		AdminUser:    "admin",
-		AdminPass:    "admin",
-		Cert:         "",
-		Key:          "",
-		Scheme:       "http",
-		Public:       true,
-		MetaDB:       "lfs-test.db",
-		BackingStore: "bolt",
-		ContentStore: "filesystem",
-		NumProcs:     runtime.NumCPU(),
-		Ldap:         ldapConfig,
-		Aws:          awsConfig,
-		Cassandra:    cassandraConfig,
-		MySQL:        mysqlConfig,
+		Listen:          "tcp://:8080",
+		Host:            "localhost:8080",
+		UrlContext:      "",
+		ContentPath:     "lfs-content",
+		AdminUser:       "admin",
+		AdminPass:       "admin",
+		Cert:            "",
+		Key:             "",
+		Scheme:          "http",
+		Public:          true,
+		MetaDB:          "lfs-test.db",
+		SQLiteDB:        "lfs-test.sqlite",
+		BackingStore:    "bolt",
+		ContentStore:    "filesystem",
+		NumProcs:        runtime.NumCPU(),
+		GCGracePeriod:   24 * time.Hour,
+		TokenSecret:     "",
+		TokenTTL:        30 * time.Minute,
+		AuthProviders:   "",
+		HtpasswdFile:    "",
+		AuthCacheTTL:    60 * time.Second,
+		StoreRetries:    5,
+		StoreBackoff:    time.Second,
+		LegacyNamespace: "default",
+		Ldap:            ldapConfig,
+		Aws:             awsConfig,
+		Cassandra:       cassandraConfig,
+		MySQL:           mysqlConfig,
+		Postgres:        postgresConfig,
+		UserService:     userServiceConfig,
 	}
 	err = cfg.Section("Main").MapTo(configuration)
 	err = cfg.Section("Aws").MapTo(configuration.Aws)
 	err = cfg.Section("Ldap").MapTo(configuration.Ldap)
 	err = cfg.Section("Cassandra").MapTo(configuration.Cassandra)
 	err = cfg.Section("MySQL").MapTo(configuration.MySQL)
+	err = cfg.Section("Postgres").MapTo(configuration.Postgres)
+	err = cfg.Section("UserService").MapTo(configuration.UserService)
 	Config = configuration
 }
 
@@ -6,6 +6,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"time"
 )
 
 // ContentStore provides a simple file system based storage.
@@ -30,6 +31,27 @@ func (s *ContentStore) Get(meta *MetaObject) (io.Reader, error) {
 	return os.Open(path)
 }
 
+// GetRange returns length bytes of the content starting at offset.
+func (s *ContentStore) GetRange(meta *MetaObject, offset, length int64) (io.Reader, error) {
+	path := filepath.Join(s.basePath, transformKey(meta.Oid))
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := f.Seek(offset, os.SEEK_SET); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &limitedFile{io.LimitReader(f, length), f}, nil
+}
+
+// limitedFile reads part of a file, closing the file when done
+type limitedFile struct {
+	io.Reader
+	io.Closer
+}
+
 // Put takes a Meta object and an io.Reader and writes the content to the store.
 func (s *ContentStore) Put(meta *MetaObject, r io.Reader) error {
 	path := filepath.Join(s.basePath, transformKey(meta.Oid))
@@ -71,6 +93,91 @@ func (s *ContentStore) Put(meta *MetaObject, r io.Reader) error {
 	return nil
 }
 
+// UploadOffset returns how many bytes of a resumable upload the store holds.
+// Objects already in the store report their full size.
+func (s *ContentStore) UploadOffset(meta *MetaObject) (int64, error) {
+	if s.Exists(meta) {
+		return meta.Size, nil
+	}
+	fi, err := os.Stat(s.partialPath(meta))
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return fi.Size(), nil
+}
+
+// PutChunk appends the content of r to a resumable upload at offset, which must
+// match the bytes received so far. Chunks are kept in a .part file next to the
+// object. Once the last chunk arrives the content is verified against the
+// MetaObject and moved into place; content that does not match is discarded.
+// It returns the upload offset after the chunk was written.
+func (s *ContentStore) PutChunk(meta *MetaObject, offset int64, r io.Reader) (int64, error) {
+	defer uploadLocks.lock(meta.Oid)()
+
+	current, err := s.UploadOffset(meta)
+	if err != nil {
+		return 0, err
+	}
+	if offset != current {
+		return current, errOffsetMismatch
+	}
+	if current == meta.Size && s.Exists(meta) {
+		return current, nil
+	}
+
+	partPath := s.partialPath(meta)
+	if err := os.MkdirAll(filepath.Dir(partPath), 0750); err != nil {
+		return current, err
+	}
+	file, err := os.OpenFile(partPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0640)
+	if err != nil {
+		return current, err
+	}
+
+	// read one byte past the expected size so oversized content is caught
+	written, err := io.Copy(file, io.LimitReader(r, meta.Size-offset+1))
+	file.Close()
+	offset += written
+	if offset > meta.Size {
+		os.Remove(partPath)
+		return 0, errSizeMismatch
+	}
+	if err != nil {
+		// keep what arrived, the client resumes from the new offset
+		return s.UploadOffset(meta)
+	}
+	if offset < meta.Size {
+		return offset, nil
+	}
+
+	if err := verifyFile(meta, partPath); err != nil {
+		os.Remove(partPath)
+		return 0, err
+	}
+	if err := os.Rename(partPath, filepath.Join(s.basePath, transformKey(meta.Oid))); err != nil {
+		return offset, err
+	}
+	return offset, nil
+}
+
+// partialPath is where the chunks of a resumable upload are collected
+func (s *ContentStore) partialPath(meta *MetaObject) string {
+	return filepath.Join(s.basePath, transformKey(meta.Oid)) + ".part"
+}
+
+// verifyFile checks the size and sha256 of the file at path against meta
+func verifyFile(meta *MetaObject, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return verifyContent(meta, f)
+}
+
 // Exists returns true if the object exists in the content store.
 func (s *ContentStore) Exists(meta *MetaObject) bool {
 	path := filepath.Join(s.basePath, transformKey(meta.Oid))
@@ -79,3 +186,34 @@ func (s *ContentStore) Exists(meta *MetaObject) bool {
 	}
 	return true
 }
+
+// Size returns the size of the object as stored in the content store.
+func (s *ContentStore) Size(meta *MetaObject) (int64, error) {
+	path := filepath.Join(s.basePath, transformKey(meta.Oid))
+	fi, err := os.Stat(path)
+	if err != nil {
+		return 0, err
+	}
+	return fi.Size(), nil
+}
+
+// ModTime returns when the object was written to the content store.
+func (s *ContentStore) ModTime(meta *MetaObject) (time.Time, error) {
+	path := filepath.Join(s.basePath, transformKey(meta.Oid))
+	fi, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return fi.ModTime(), nil
+}
+
+// Delete removes the object from the content store.
+func (s *ContentStore) Delete(meta *MetaObject) error {
+	path := filepath.Join(s.basePath, transformKey(meta.Oid))
+	os.Remove(s.partialPath(meta))
+	err := os.Remove(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
@@ -133,6 +133,147 @@ func TestContentStoreExists(t *testing.T) {
 	}
 }
 
+func TestContentStoreSize(t *testing.T) {
+	setup()
+	defer teardown()
+
+	m := &MetaObject{
+		Oid:  "6ae8a75555209fd6c44157c0aed8016e763ff435a19cf186f76863140143ff72",
+		Size: 12,
+	}
+
+	if _, err := contentStore.Size(m); err == nil {
+		t.Fatalf("expected an error for content that does not exist")
+	}
+
+	b := bytes.NewBuffer([]byte("test content"))
+	if err := contentStore.Put(m, b); err != nil {
+		t.Fatalf("expected put to succeed, got: %s", err)
+	}
+
+	size, err := contentStore.Size(m)
+	if err != nil {
+		t.Fatalf("expected size to succeed, got: %s", err)
+	}
+	if size != 12 {
+		t.Fatalf("expected size to be 12, got: %d", size)
+	}
+}
+
+func TestContentStoreDelete(t *testing.T) {
+	setup()
+	defer teardown()
+
+	m := &MetaObject{
+		Oid:  "6ae8a75555209fd6c44157c0aed8016e763ff435a19cf186f76863140143ff72",
+		Size: 12,
+	}
+
+	b := bytes.NewBuffer([]byte("test content"))
+	if err := contentStore.Put(m, b); err != nil {
+		t.Fatalf("expected put to succeed, got: %s", err)
+	}
+
+	if err := contentStore.Delete(m); err != nil {
+		t.Fatalf("expected delete to succeed, got: %s", err)
+	}
+
+	if contentStore.Exists(m) {
+		t.Fatalf("expected content to not exist after deleting")
+	}
+
+	if err := contentStore.Delete(m); err != nil {
+		t.Fatalf("expected deleting missing content to succeed, got: %s", err)
+	}
+}
+
+func TestContentStoreGetRange(t *testing.T) {
+	setup()
+	defer teardown()
+
+	m := &MetaObject{
+		Oid:  "6ae8a75555209fd6c44157c0aed8016e763ff435a19cf186f76863140143ff72",
+		Size: 12,
+	}
+
+	b := bytes.NewBuffer([]byte("test content"))
+	if err := contentStore.Put(m, b); err != nil {
+		t.Fatalf("expected put to succeed, got: %s", err)
+	}
+
+	r, err := contentStore.GetRange(m, 5, 4)
+	if err != nil {
+		t.Fatalf("expected get range to succeed, got: %s", err)
+	}
+
+	by, _ := ioutil.ReadAll(r)
+	if string(by) != "cont" {
+		t.Fatalf("expected to read the range, got: %s", string(by))
+	}
+}
+
+func TestContentStorePutChunk(t *testing.T) {
+	setup()
+	defer teardown()
+
+	m := &MetaObject{
+		Oid:  "6ae8a75555209fd6c44157c0aed8016e763ff435a19cf186f76863140143ff72",
+		Size: 12,
+	}
+
+	offset, err := contentStore.PutChunk(m, 0, bytes.NewBufferString("test "))
+	if err != nil || offset != 5 {
+		t.Fatalf("expected first chunk to be stored at 5, got: %d, %v", offset, err)
+	}
+	if contentStore.Exists(m) {
+		t.Fatalf("expected content to not exist before the last chunk")
+	}
+
+	if offset, _ := contentStore.UploadOffset(m); offset != 5 {
+		t.Fatalf("expected upload offset 5, got: %d", offset)
+	}
+
+	if _, err := contentStore.PutChunk(m, 3, bytes.NewBufferString("t content")); err != errOffsetMismatch {
+		t.Fatalf("expected an offset mismatch, got: %v", err)
+	}
+
+	offset, err = contentStore.PutChunk(m, 5, bytes.NewBufferString("content"))
+	if err != nil || offset != 12 {
+		t.Fatalf("expected last chunk to complete the upload, got: %d, %v", offset, err)
+	}
+
+	r, err := contentStore.Get(m)
+	if err != nil {
+		t.Fatalf("expected get to succeed, got: %s", err)
+	}
+	by, _ := ioutil.ReadAll(r)
+	if string(by) != "test content" {
+		t.Fatalf("expected to read the content, got: %s", string(by))
+	}
+}
+
+func TestContentStorePutChunkHashMismatch(t *testing.T) {
+	setup()
+	defer teardown()
+
+	m := &MetaObject{
+		Oid:  "6ae8a75555209fd6c44157c0aed8016e763ff435a19cf186f76863140143ff72",
+		Size: 12,
+	}
+
+	contentStore.PutChunk(m, 0, bytes.NewBufferString("bogus "))
+	if _, err := contentStore.PutChunk(m, 6, bytes.NewBufferString("conten")); err != errHashMismatch {
+		t.Fatalf("expected a hash mismatch, got: %v", err)
+	}
+
+	if contentStore.Exists(m) {
+		t.Fatalf("expected content to not exist after a hash mismatch")
+	}
+	if offset, _ := contentStore.UploadOffset(m); offset != 0 {
+		t.Fatalf("expected the upload to restart from 0, got: %d", offset)
+	}
+}
+
 func setup() {
 	store, err := NewContentStore("content-store-test")
 	if err != nil {
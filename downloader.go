@@ -7,11 +7,13 @@ to allow/disallow a user to access a given project's objects
 import (
 	"io/ioutil"
 	"net/http"
+	"time"
 )
 
 type Downloader struct {
 	Auth     *UserServiceAuth
 	Url      string
+	Timeout  time.Duration
 	Response []byte
 	Status   string
 }
@@ -27,7 +29,15 @@ func (d *Downloader) GetPage() error {
 	if d.Response != nil {
 		return nil
 	}
-	resp, err := http.Get(d.Url)
+	client := &http.Client{Timeout: d.Timeout}
+	req, err := http.NewRequest("GET", d.Url, nil)
+	if err != nil {
+		return err
+	}
+	if d.Auth != nil {
+		req.SetBasicAuth(d.Auth.Username, d.Auth.Password)
+	}
+	resp, err := client.Do(req)
 	if err != nil {
 		return err
 	}
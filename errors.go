@@ -1,18 +1,83 @@
 package main
 
-import "errors"
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+)
 
 var (
 	errProjectNotFound     = errors.New("Project not found")
+	errProjectExists       = errors.New("Project already exists")
 	errObjectNotFound      = errors.New("Object not found")
 	errLdapUserNotFound    = errors.New("Unable to find user in LDAP")
 	errUserNotFound        = errors.New("Unable to find user")
 	errNoLdapSearchResults = errors.New("No results from LDAP")
 	errLdapSearchFailed    = errors.New("Failed searching LDAP")
+	errLdapTimeout         = errors.New("LDAP request timed out")
+	errLdapCACert          = errors.New("No certificates found in the LDAP CA bundle")
 	errHashMismatch        = errors.New("Content has does not match OID")
 	errSizeMismatch        = errors.New("Content size does not match")
 	errWriteS3             = errors.New("Erred writing to S3")
 	errNotImplemented      = errors.New("Not Implemented when using LDAP")
-        errMySQLNotImplemented = errors.New("Not Implemented when using 'bolt' or 'cassandra' meta store backend")
 	errMissingParams       = errors.New("Missing params")
+	errLockExists          = errors.New("Lock already exists")
+	errLockNotFound        = errors.New("Lock not found")
+	errLockCursor          = errors.New("Unknown lock cursor")
+	errOffsetMismatch      = errors.New("Upload offset does not match")
+	errChunkTooSmall       = errors.New("Upload chunk is too small")
+	errNoTokenSecret       = errors.New("TokenSecret is not configured")
+	errInvalidToken        = errors.New("Invalid token")
+	errTokenExpired        = errors.New("Token expired")
+	errTokenNotFound       = errors.New("Token not found")
+	errInvalidScope        = errors.New("Token scope must be read or write")
+	errSchemaTooNew        = errors.New("Database schema is newer than this server, upgrade lfs-server-go")
+	errNoSchema            = errors.New("BackingStore has no versioned schema, use mysql, postgres or sqlite")
 )
+
+// storeError is returned when a meta store backend can't be reached or fails a query.
+// Only failing to reach it makes it unavailable, requests are then answered with a 503
+// so clients retry.
+type storeError struct {
+	backend     string
+	op          string
+	err         error
+	unavailable bool
+}
+
+func (e storeError) Error() string {
+	return fmt.Sprintf("%s %s failed: %s", e.backend, e.op, e.err)
+}
+
+func (e storeError) Unavailable() bool {
+	return e.unavailable
+}
+
+func newStoreError(backend, op string, err error) error {
+	return storeError{backend: backend, op: op, err: err, unavailable: isConnectionError(err)}
+}
+
+// newUnavailableError is a store error for backend specific connection errors
+func newUnavailableError(backend, op string, err error) error {
+	return storeError{backend: backend, op: op, err: err, unavailable: true}
+}
+
+func isStoreError(err error) bool {
+	_, ok := err.(storeError)
+	return ok
+}
+
+// isConnectionError reports whether err comes from a lost or refused connection, as
+// opposed to a failing query
+func isConnectionError(err error) bool {
+	if err == driver.ErrBadConn || err == sql.ErrConnDone {
+		return true
+	}
+	if _, ok := err.(net.Error); ok {
+		return true
+	}
+	return strings.Contains(err.Error(), "connection refused")
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// GCResult reports the objects a garbage collection run removed, or would remove on a dry run.
+type GCResult struct {
+	DryRun  bool          `json:"dry_run"`
+	Deleted []*MetaObject `json:"deleted"`
+	Skipped []*MetaObject `json:"skipped"`
+	Errors  []string      `json:"errors,omitempty"`
+}
+
+// collectGarbage removes objects that no project references any more from the content
+// and meta stores. Objects whose content was written within gracePeriod are skipped,
+// so uploads that are still in flight are left alone.
+func collectGarbage(metaStore GenericMetaStore, contentStore GenericContentStore, gracePeriod time.Duration, dryRun bool) (*GCResult, error) {
+	result := &GCResult{DryRun: dryRun, Deleted: []*MetaObject{}, Skipped: []*MetaObject{}}
+
+	// mark
+	referenced, err := referencedOids(metaStore)
+	if err != nil {
+		return nil, err
+	}
+
+	objects, err := metaStore.Objects()
+	if err != nil {
+		return nil, err
+	}
+
+	cutoff := time.Now().Add(-gracePeriod)
+	var candidates []*MetaObject
+	for _, meta := range objects {
+		if referenced[meta.Oid] {
+			continue
+		}
+		if modTime, err := contentStore.ModTime(meta); err == nil && modTime.After(cutoff) {
+			result.Skipped = append(result.Skipped, meta)
+			continue
+		}
+		candidates = append(candidates, meta)
+	}
+
+	// objects may have been linked into a project while marking
+	referenced, err = referencedOids(metaStore)
+	if err != nil {
+		return nil, err
+	}
+
+	// sweep
+	for _, meta := range candidates {
+		if referenced[meta.Oid] {
+			continue
+		}
+		result.Deleted = append(result.Deleted, meta)
+		if dryRun {
+			continue
+		}
+		if err := contentStore.Delete(meta); err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %s", meta.Oid, err))
+			continue
+		}
+		if err := metaStore.DeleteObject(meta.Oid); err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %s", meta.Oid, err))
+		}
+	}
+
+	logger.Log(kv{"fn": "collectGarbage", "dry_run": dryRun, "deleted": len(result.Deleted), "skipped": len(result.Skipped), "errors": len(result.Errors)})
+	return result, nil
+}
+
+// referencedOids returns the set of oids held by at least one project
+func referencedOids(metaStore GenericMetaStore) (map[string]bool, error) {
+	projects, err := metaStore.Projects()
+	if err != nil && err != errProjectNotFound {
+		return nil, err
+	}
+
+	referenced := make(map[string]bool)
+	for _, project := range projects {
+		for _, oid := range project.Oids {
+			referenced[oid] = true
+		}
+	}
+	return referenced, nil
+}
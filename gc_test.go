@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const (
+	garbageContent = "garbage"
+	garbageOid     = "795b6904e54f82411df4b0e27a373a55eea3f9d66dac5a9bce1dd92f7b401da5"
+)
+
+var (
+	gcMetaStore    *MetaStore
+	gcContentStore *ContentStore
+)
+
+func TestGCDryRun(t *testing.T) {
+	setupGC(t)
+	defer teardownGC()
+
+	result, err := collectGarbage(gcMetaStore, gcContentStore, time.Hour, true)
+	if err != nil {
+		t.Fatalf("expected gc to succeed, got: %s", err)
+	}
+
+	if len(result.Deleted) != 1 || result.Deleted[0].Oid != garbageOid {
+		t.Errorf("expected only the garbage oid to be collected, got: %+v", result.Deleted)
+	}
+
+	if len(result.Skipped) != 1 || result.Skipped[0].Oid != noAuthOid {
+		t.Errorf("expected the recent oid to be skipped, got: %+v", result.Skipped)
+	}
+
+	if !gcContentStore.Exists(&MetaObject{Oid: garbageOid}) {
+		t.Errorf("expected dry run to keep the garbage content")
+	}
+}
+
+func TestGCDeletesUnreferenced(t *testing.T) {
+	setupGC(t)
+	defer teardownGC()
+
+	result, err := collectGarbage(gcMetaStore, gcContentStore, time.Hour, false)
+	if err != nil {
+		t.Fatalf("expected gc to succeed, got: %s", err)
+	}
+
+	if len(result.Deleted) != 1 || len(result.Errors) != 0 {
+		t.Fatalf("expected 1 object to be collected without errors, got: %+v", result)
+	}
+
+	if gcContentStore.Exists(&MetaObject{Oid: garbageOid}) {
+		t.Errorf("expected the garbage content to be removed")
+	}
+
+	objects, _ := gcMetaStore.Objects()
+	for _, o := range objects {
+		if o.Oid == garbageOid {
+			t.Errorf("expected the garbage meta to be removed")
+		}
+	}
+
+	for _, oid := range []string{contentOid, noAuthOid} {
+		if !gcContentStore.Exists(&MetaObject{Oid: oid}) {
+			t.Errorf("expected %s to be kept", oid)
+		}
+	}
+}
+
+// setupGC stores one referenced object, one unreferenced object written an hour
+// ago and one unreferenced object written just now
+func setupGC(t *testing.T) {
+	var err error
+	gcMetaStore, err = NewMetaStore("test-gc.db")
+	if err != nil {
+		t.Fatalf("error initializing test meta store: %s", err)
+	}
+	gcContentStore, err = NewContentStore("test-gc-content")
+	if err != nil {
+		t.Fatalf("error initializing test content store: %s", err)
+	}
+	if err := gcMetaStore.AddUser(testUser, testPass); err != nil {
+		t.Fatalf("error adding test user: %s", err)
+	}
+
+	objects := []struct {
+		oid, content, repo string
+	}{
+		{contentOid, content, testRepo},
+		{garbageOid, garbageContent, extraRepo},
+		{noAuthOid, noAuthcontent, extraRepo},
+	}
+	for _, o := range objects {
+		meta, err := gcMetaStore.Put(&RequestVars{Authorization: testAuth, Oid: o.oid, Size: int64(len(o.content)), Namespace: testNamespace, Repo: o.repo})
+		if err != nil {
+			t.Fatalf("error seeding meta store: %s", err)
+		}
+		if err := gcContentStore.Put(meta, bytes.NewBufferString(o.content)); err != nil {
+			t.Fatalf("error seeding content store: %s", err)
+		}
+	}
+
+	old := time.Now().Add(-2 * time.Hour)
+	os.Chtimes(filepath.Join("test-gc-content", transformKey(garbageOid)), old, old)
+
+	if err := gcMetaStore.DeleteProject(testNamespace, extraRepo); err != nil {
+		t.Fatalf("error deleting project: %s", err)
+	}
+}
+
+func teardownGC() {
+	gcMetaStore.Close()
+	os.RemoveAll("test-gc.db")
+	os.RemoveAll("test-gc-content")
+}
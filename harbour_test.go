@@ -4,12 +4,16 @@ import (
 	"bytes"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestGetAuthed(t *testing.T) {
@@ -56,6 +60,143 @@ func TestGetUnauthed(t *testing.T) {
 	}
 }
 
+func doGetContent(t *testing.T, method string, header map[string]string) *http.Response {
+	req, err := http.NewRequest(method, lfsServer.URL+"/namespace/repo/objects/"+contentOid, nil)
+	if err != nil {
+		t.Fatalf("request error: %s", err)
+	}
+	req.SetBasicAuth(testUser, testPass)
+	req.Header.Set("Accept", contentMediaType)
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("response error: %s", err)
+	}
+	return res
+}
+
+func TestGetHeaders(t *testing.T) {
+	res := doGetContent(t, "GET", nil)
+	if res.StatusCode != 200 {
+		t.Fatalf("expected status 200, got %d", res.StatusCode)
+	}
+
+	if res.ContentLength != contentSize {
+		t.Errorf("expected content length %d, got %d", contentSize, res.ContentLength)
+	}
+
+	if etag := res.Header.Get("ETag"); etag != `"`+contentOid+`"` {
+		t.Errorf("expected the oid as etag, got %s", etag)
+	}
+}
+
+func TestGetRange(t *testing.T) {
+	ranges := map[string]string{
+		"bytes=5-6":  content[5:7],
+		"bytes=11-":  content[11:],
+		"bytes=-7":   content[len(content)-7:],
+		"bytes=8-99": content[8:],
+	}
+	for rh, expected := range ranges {
+		res := doGetContent(t, "GET", map[string]string{"Range": rh})
+		if res.StatusCode != 206 {
+			t.Fatalf("expected status 206 for %s, got %d", rh, res.StatusCode)
+		}
+
+		by, _ := ioutil.ReadAll(res.Body)
+		if string(by) != expected {
+			t.Errorf("expected %q for %s, got %q", expected, rh, string(by))
+		}
+
+		if res.ContentLength != int64(len(expected)) {
+			t.Errorf("expected content length %d for %s, got %d", len(expected), rh, res.ContentLength)
+		}
+	}
+}
+
+func TestGetRangeUnsatisfiable(t *testing.T) {
+	res := doGetContent(t, "GET", map[string]string{"Range": fmt.Sprintf("bytes=%d-", contentSize)})
+	if res.StatusCode != 416 {
+		t.Fatalf("expected status 416, got %d", res.StatusCode)
+	}
+
+	if cr := res.Header.Get("Content-Range"); cr != fmt.Sprintf("bytes */%d", contentSize) {
+		t.Errorf("expected content range with the size, got %s", cr)
+	}
+}
+
+func TestGetIfRange(t *testing.T) {
+	res := doGetContent(t, "GET", map[string]string{"Range": "bytes=5-6", "If-Range": `"` + contentOid + `"`})
+	if res.StatusCode != 206 {
+		t.Fatalf("expected status 206, got %d", res.StatusCode)
+	}
+
+	res = doGetContent(t, "GET", map[string]string{"Range": "bytes=5-6", "If-Range": `"stale"`})
+	if res.StatusCode != 200 {
+		t.Fatalf("expected status 200, got %d", res.StatusCode)
+	}
+
+	by, _ := ioutil.ReadAll(res.Body)
+	if string(by) != content {
+		t.Errorf("expected the whole content, got %q", string(by))
+	}
+}
+
+func TestHeadContent(t *testing.T) {
+	res := doGetContent(t, "HEAD", nil)
+	if res.StatusCode != 200 {
+		t.Fatalf("expected status 200, got %d", res.StatusCode)
+	}
+
+	if res.ContentLength != contentSize {
+		t.Errorf("expected content length %d, got %d", contentSize, res.ContentLength)
+	}
+
+	if by, _ := ioutil.ReadAll(res.Body); len(by) != 0 {
+		t.Errorf("expected no body, got %q", string(by))
+	}
+}
+
+func TestGetFromOtherProject(t *testing.T) {
+	req, err := http.NewRequest("GET", lfsServer.URL+"/namespace/otherrepo/objects/"+contentOid, nil)
+	if err != nil {
+		t.Fatalf("request error: %s", err)
+	}
+	req.SetBasicAuth(testUser, testPass)
+	req.Header.Set("Accept", contentMediaType)
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("response error: %s", err)
+	}
+
+	if res.StatusCode != 404 {
+		t.Fatalf("expected status 404, got %d", res.StatusCode)
+	}
+}
+
+func TestSearch(t *testing.T) {
+	for project, status := range map[string]int{"namespace/repo": 200, "namespace/otherrepo": 404, "": 404} {
+		req, err := http.NewRequest("GET", lfsServer.URL+"/search/"+contentOid+"?project="+project, nil)
+		if err != nil {
+			t.Fatalf("request error: %s", err)
+		}
+		req.SetBasicAuth(testUser, testPass)
+
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("response error: %s", err)
+		}
+
+		if res.StatusCode != status {
+			t.Errorf("expected status %d for project %q, got %d", status, project, res.StatusCode)
+		}
+	}
+}
+
 func TestGetMetaAuthed(t *testing.T) {
 	req, err := http.NewRequest("GET", lfsServer.URL+"/namespace/repo/objects/"+contentOid, nil)
 	if err != nil {
@@ -227,36 +368,868 @@ func TestPostUnauthed(t *testing.T) {
 	}
 }
 
-func TestPut(t *testing.T) {
-	req, err := http.NewRequest("PUT", lfsServer.URL+"/namespace/repo/objects/"+contentOid, nil)
+func TestBatchDownload(t *testing.T) {
+	res := doBatch(t, "download", contentOid, contentSize)
+	if res.StatusCode != 200 {
+		t.Fatalf("expected status 200, got %d", res.StatusCode)
+	}
+
+	var br BatchResponse
+	dec := json.NewDecoder(res.Body)
+	dec.Decode(&br)
+
+	if br.Transfer != "basic" {
+		t.Fatalf("expected transfer to be basic, got %s", br.Transfer)
+	}
+
+	if len(br.Objects) != 1 {
+		t.Fatalf("expected 1 object, got %d", len(br.Objects))
+	}
+
+	obj := br.Objects[0]
+	if obj.Error != nil {
+		t.Fatalf("expected no error, got %d %s", obj.Error.Code, obj.Error.Message)
+	}
+
+	download, ok := obj.Actions["download"]
+	if !ok {
+		t.Fatal("expected download action to be present")
+	}
+
+	if download.Href != baseURL()+"/namespace/repo/objects/"+contentOid {
+		t.Fatalf("expected download link, got %s", download.Href)
+	}
+
+	if _, ok := obj.Actions["upload"]; ok {
+		t.Fatal("expected upload action to not be present")
+	}
+}
+
+func TestBatchDownloadMissing(t *testing.T) {
+	missingOid := "bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb"
+	res := doBatch(t, "download", missingOid, 1234)
+	if res.StatusCode != 200 {
+		t.Fatalf("expected status 200, got %d", res.StatusCode)
+	}
+
+	var br BatchResponse
+	dec := json.NewDecoder(res.Body)
+	dec.Decode(&br)
+
+	if len(br.Objects) != 1 {
+		t.Fatalf("expected 1 object, got %d", len(br.Objects))
+	}
+
+	obj := br.Objects[0]
+	if obj.Error == nil || obj.Error.Code != 404 {
+		t.Fatalf("expected a 404 error, got %+v", obj.Error)
+	}
+
+	if len(obj.Actions) != 0 {
+		t.Fatalf("expected no actions, got %v", obj.Actions)
+	}
+}
+
+func TestBatchUpload(t *testing.T) {
+	res := doBatch(t, "upload", noAuthOid, noAuthContentSize)
+	if res.StatusCode != 200 {
+		t.Fatalf("expected status 200, got %d", res.StatusCode)
+	}
+
+	var br BatchResponse
+	dec := json.NewDecoder(res.Body)
+	dec.Decode(&br)
+
+	if len(br.Objects) != 1 {
+		t.Fatalf("expected 1 object, got %d", len(br.Objects))
+	}
+
+	upload, ok := br.Objects[0].Actions["upload"]
+	if !ok {
+		t.Fatal("expected upload action to be present")
+	}
+
+	if upload.Href != baseURL()+"/namespace/repo/objects/"+noAuthOid {
+		t.Fatalf("expected upload link, got %s", upload.Href)
+	}
+
+	verify, ok := br.Objects[0].Actions["verify"]
+	if !ok {
+		t.Fatal("expected verify action to be present")
+	}
+
+	if verify.Href != baseURL()+"/namespace/repo/objects/verify" {
+		t.Fatalf("expected verify link, got %s", verify.Href)
+	}
+}
+
+func TestBatchUploadOtherProject(t *testing.T) {
+	objectURL := lfsServer.URL + "/namespace/otherproject/objects/" + contentOid
+	do := func(method, url, accept, body string, header map[string]string) *http.Response {
+		req, err := http.NewRequest(method, url, bytes.NewBufferString(body))
+		if err != nil {
+			t.Fatalf("request error: %s", err)
+		}
+		req.SetBasicAuth(testUser, testPass)
+		req.Header.Set("Accept", accept)
+		for k, v := range header {
+			req.Header.Set(k, v)
+		}
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("response error: %s", err)
+		}
+		return res
+	}
+
+	res := do("POST", lfsServer.URL+"/namespace/otherproject/objects/batch", metaMediaType,
+		fmt.Sprintf(`{"operation":"upload","objects":[{"oid":"%s", "size":%d}]}`, contentOid, contentSize), nil)
+	var br BatchResponse
+	json.NewDecoder(res.Body).Decode(&br)
+	if res.StatusCode != 200 || len(br.Objects) != 1 {
+		t.Fatalf("expected status 200 with 1 object, got %d %+v", res.StatusCode, br)
+	}
+	upload, ok := br.Objects[0].Actions["upload"]
+	if !ok || upload.Href != baseURL()+"/namespace/otherproject/objects/"+contentOid {
+		t.Fatalf("expected an upload action for an object of another project, got %+v", br.Objects[0].Actions)
+	}
+
+	if res := do("GET", objectURL, contentMediaType, "", nil); res.StatusCode != 404 {
+		t.Fatalf("expected status 404 before the upload, got %d", res.StatusCode)
+	}
+
+	res = do("PUT", objectURL, contentMediaType, "this is my CONTENT", nil)
+	if res.StatusCode != 422 {
+		t.Fatalf("expected status 422 for other content, got %d", res.StatusCode)
+	}
+	res = do("PUT", objectURL, contentMediaType, content[4:], map[string]string{"Content-Range": fmt.Sprintf("bytes 4-%d/%d", contentSize-1, contentSize)})
+	if res.StatusCode != 409 || res.Header.Get("Upload-Offset") != "0" {
+		t.Fatalf("expected status 409 at offset 0 for a chunk, got %d at %s", res.StatusCode, res.Header.Get("Upload-Offset"))
+	}
+	if res := do("GET", objectURL, contentMediaType, "", nil); res.StatusCode != 404 {
+		t.Fatalf("expected status 404 after failed uploads, got %d", res.StatusCode)
+	}
+
+	if res := do("PUT", objectURL, contentMediaType, content, nil); res.StatusCode != 200 {
+		t.Fatalf("expected status 200, got %d", res.StatusCode)
+	}
+	res = do("GET", objectURL, contentMediaType, "", nil)
+	if c, _ := ioutil.ReadAll(res.Body); res.StatusCode != 200 || string(c) != content {
+		t.Fatalf("expected the content after the upload, got %d `%s`", res.StatusCode, string(c))
+	}
+}
+
+func TestBatchUploadInvalidOid(t *testing.T) {
+	res := doBatch(t, "upload", "not-an-oid", 10)
+	if res.StatusCode != 200 {
+		t.Fatalf("expected status 200, got %d", res.StatusCode)
+	}
+
+	var br BatchResponse
+	dec := json.NewDecoder(res.Body)
+	dec.Decode(&br)
+
+	if len(br.Objects) != 1 {
+		t.Fatalf("expected 1 object, got %d", len(br.Objects))
+	}
+
+	if br.Objects[0].Error == nil || br.Objects[0].Error.Code != 422 {
+		t.Fatalf("expected a 422 error, got %+v", br.Objects[0].Error)
+	}
+}
+
+func TestBatchInvalidOperation(t *testing.T) {
+	res := doBatch(t, "delete", contentOid, contentSize)
+	if res.StatusCode != 422 {
+		t.Fatalf("expected status 422, got %d", res.StatusCode)
+	}
+}
+
+func TestBatchUnauthed(t *testing.T) {
+	req, err := http.NewRequest("POST", lfsServer.URL+"/namespace/repo/objects/batch", nil)
 	if err != nil {
 		t.Fatalf("request error: %s", err)
 	}
-	req.SetBasicAuth(testUser, testPass)
-	req.Header.Set("Accept", contentMediaType)
-	req.Header.Set("Content-Type", "application/octet-stream")
-	req.Body = ioutil.NopCloser(bytes.NewBuffer([]byte(content)))
+	req.Header.Set("Accept", metaMediaType)
+
+	buf := bytes.NewBufferString(fmt.Sprintf(`{"operation":"download","objects":[{"oid":"%s", "size":%d}]}`, contentOid, contentSize))
+	req.Body = ioutil.NopCloser(buf)
 
 	res, err := http.DefaultClient.Do(req)
 	if err != nil {
 		t.Fatalf("response error: %s", err)
 	}
 
-	if res.StatusCode != 200 {
-		t.Fatalf("expected status 200, got %d", res.StatusCode)
+	if res.StatusCode != 401 {
+		t.Fatalf("expected status 401, got %d", res.StatusCode)
 	}
+}
 
-	r, err := testContentStore.Get(&MetaObject{Oid: contentOid})
-	if err != nil {
-		t.Fatalf("error retreiving from content store: %s", err)
+// presigningContentStore hands out fake presigned links for the test content store
+type presigningContentStore struct {
+	GenericContentStore
+}
+
+func (s *presigningContentStore) PresignGet(meta *MetaObject, expires time.Time) (string, error) {
+	return "https://s3.example.com/get/" + meta.Oid, nil
+}
+
+func (s *presigningContentStore) PresignPut(meta *MetaObject, expires time.Time) (string, error) {
+	return "https://s3.example.com/put/" + meta.Oid, nil
+}
+
+func TestBatchDirectLinks(t *testing.T) {
+	Config.Aws.DirectLinks = true
+	defer func() { Config.Aws.DirectLinks = false }()
+
+	app := NewApp(&presigningContentStore{testContentStore}, testMetaStore)
+	for operation, oid := range map[string]string{"download": contentOid, "upload": strings.Repeat("d", 64)} {
+		body := fmt.Sprintf(`{"operation":"%s","objects":[{"oid":"%s", "size":%d}]}`, operation, oid, contentSize)
+		req, err := http.NewRequest("POST", "/namespace/repo/objects/batch", bytes.NewBufferString(body))
+		if err != nil {
+			t.Fatalf("request error: %s", err)
+		}
+		req.SetBasicAuth(testUser, testPass)
+		req.Header.Set("Accept", metaMediaType)
+
+		res := httptest.NewRecorder()
+		app.ServeHTTP(res, req)
+		if res.Code != 200 {
+			t.Fatalf("expected status 200, got %d", res.Code)
+		}
+
+		var br BatchResponse
+		json.NewDecoder(res.Body).Decode(&br)
+		if len(br.Objects) != 1 {
+			t.Fatalf("expected 1 object, got %d", len(br.Objects))
+		}
+
+		action, ok := br.Objects[0].Actions[operation]
+		if !ok {
+			t.Fatalf("expected %s action to be present", operation)
+		}
+
+		prefix := map[string]string{"download": "get", "upload": "put"}[operation]
+		if action.Href != "https://s3.example.com/"+prefix+"/"+oid {
+			t.Errorf("expected presigned %s link, got %s", operation, action.Href)
+		}
+
+		if action.ExpiresAt == nil || !action.ExpiresAt.After(time.Now()) {
+			t.Errorf("expected %s link to expire in the future, got %v", operation, action.ExpiresAt)
+		}
+
+		if _, ok := action.Header["Authorization"]; ok {
+			t.Errorf("expected no credentials to be sent to S3")
+		}
+
+		if operation == "upload" {
+			if _, ok := br.Objects[0].Actions["verify"]; !ok {
+				t.Error("expected verify action to be present")
+			}
+		}
 	}
-	c, err := ioutil.ReadAll(r)
+}
+
+func TestBatchBearerToken(t *testing.T) {
+	defer withTokenSecret("s3cret")()
+
+	resp, err := sshAuthResponse(testUser, &RequestVars{Namespace: testNamespace, Repo: testRepo}, "download")
 	if err != nil {
-		t.Fatalf("error reading content: %s", err)
+		t.Fatalf("error issuing token: %s", err)
 	}
-	if string(c) != content {
-		t.Fatalf("expected content, got `%s`", string(c))
+
+	for operation, status := range map[string]int{"download": 200, "upload": 401} {
+		req, err := http.NewRequest("POST", lfsServer.URL+"/namespace/repo/objects/batch", nil)
+		if err != nil {
+			t.Fatalf("request error: %s", err)
+		}
+		req.Header.Set("Authorization", resp.Header["Authorization"])
+		req.Header.Set("Accept", metaMediaType)
+		req.Body = ioutil.NopCloser(bytes.NewBufferString(fmt.Sprintf(`{"operation":"%s","objects":[{"oid":"%s", "size":%d}]}`, operation, contentOid, contentSize)))
+
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("response error: %s", err)
+		}
+		if res.StatusCode != status {
+			t.Errorf("expected %s with a download token to get %d, got %d", operation, status, res.StatusCode)
+		}
+	}
+}
+
+func TestBatchAccessToken(t *testing.T) {
+	token, secret, _ := newAccessToken(testUser, "ci", "read", []string{projectKey(testNamespace, testRepo)})
+	if err := testMetaStore.AddToken(token); err != nil {
+		t.Fatalf("error adding token: %s", err)
+	}
+	defer testMetaStore.DeleteToken(token.Id)
+
+	basic := fmt.Sprintf("Basic %s", base64.StdEncoding.EncodeToString([]byte(testUser+":"+secret)))
+	cases := []struct {
+		auth, repo, operation string
+		status                int
+	}{
+		{basic, testRepo, "download", 200},
+		{"Bearer " + secret, testRepo, "download", 200},
+		{basic, testRepo, "upload", 403},
+		{"Bearer " + secret, extraRepo, "download", 403},
+		{"Bearer lfs_notatoken", testRepo, "download", 401},
+	}
+	for _, c := range cases {
+		req, err := http.NewRequest("POST", lfsServer.URL+"/namespace/"+c.repo+"/objects/batch", nil)
+		if err != nil {
+			t.Fatalf("request error: %s", err)
+		}
+		req.Header.Set("Authorization", c.auth)
+		req.Header.Set("Accept", metaMediaType)
+		req.Body = ioutil.NopCloser(bytes.NewBufferString(fmt.Sprintf(`{"operation":"%s","objects":[{"oid":"%s", "size":%d}]}`, c.operation, contentOid, contentSize)))
+
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("response error: %s", err)
+		}
+		if res.StatusCode != c.status {
+			t.Errorf("expected %s on %s with %s to get %d, got %d", c.operation, c.repo, c.auth[:6], c.status, res.StatusCode)
+		}
+	}
+}
+
+func TestOwnTokens(t *testing.T) {
+	do := func(method, path, body, password string) *http.Response {
+		req, err := http.NewRequest(method, lfsServer.URL+path, strings.NewReader(body))
+		if err != nil {
+			t.Fatalf("request error: %s", err)
+		}
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.SetBasicAuth(testUser, password)
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("response error: %s", err)
+		}
+		return res
+	}
+
+	res := do("POST", "/tokens", "name=laptop&scope=read", testPass)
+	if res.StatusCode != 201 {
+		t.Fatalf("expected status 201, got %d", res.StatusCode)
+	}
+	var created struct {
+		Id    string `json:"id"`
+		User  string `json:"user"`
+		Token string `json:"token"`
+	}
+	json.NewDecoder(res.Body).Decode(&created)
+	if created.User != testUser || !strings.HasPrefix(created.Token, accessTokenPrefix) {
+		t.Fatalf("expected a token of %s, got %+v", testUser, created)
+	}
+	defer testMetaStore.DeleteToken(created.Id)
+
+	if res := do("POST", "/tokens", "name=escalate&scope=write", created.Token); res.StatusCode != 403 {
+		t.Errorf("expected a token not to create tokens, got %d", res.StatusCode)
+	}
+	if res := do("GET", "/tokens", "", "wrong"); res.StatusCode != 401 {
+		t.Errorf("expected a wrong password to get 401, got %d", res.StatusCode)
+	}
+
+	var tokens []*MetaToken
+	json.NewDecoder(do("GET", "/tokens", "", testPass).Body).Decode(&tokens)
+	if len(tokens) != 1 || tokens[0].Id != created.Id {
+		t.Errorf("expected the new token to be listed, got %+v", tokens)
+	}
+
+	other, _, _ := newAccessToken("someoneelse", "ci", "read", nil)
+	if err := testMetaStore.AddToken(other); err != nil {
+		t.Fatalf("error adding token: %s", err)
+	}
+	defer testMetaStore.DeleteToken(other.Id)
+	if res := do("DELETE", "/tokens/"+other.Id, "", testPass); res.StatusCode != 404 {
+		t.Errorf("expected the token of another user not to be found, got %d", res.StatusCode)
+	}
+	if res := do("DELETE", "/tokens/"+created.Id, "", testPass); res.StatusCode != 200 {
+		t.Errorf("expected the own token to be revoked, got %d", res.StatusCode)
+	}
+}
+
+// downMetaStore fails reading and writing objects like a meta store that lost its database
+type downMetaStore struct {
+	*MetaStore
+}
+
+func (s *downMetaStore) Get(v *RequestVars) (*MetaObject, error) {
+	return nil, newStoreError("test", "get", errors.New("connection refused"))
+}
+
+func (s *downMetaStore) Put(v *RequestVars) (*MetaObject, error) {
+	return nil, newStoreError("test", "put", errors.New("connection refused"))
+}
+
+func TestMetaStoreUnavailable(t *testing.T) {
+	server := httptest.NewServer(NewApp(testContentStore, &downMetaStore{testMetaStore.(*MetaStore)}))
+	defer server.Close()
+
+	req, _ := http.NewRequest("GET", server.URL+"/namespace/repo/objects/"+contentOid, nil)
+	req.SetBasicAuth(testUser, testPass)
+	req.Header.Set("Accept", contentMediaType)
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("response error: %s", err)
+	}
+	defer res.Body.Close()
+
+	var body map[string]string
+	json.NewDecoder(res.Body).Decode(&body)
+	if res.StatusCode != 503 || res.Header.Get("Content-Type") != "application/json" || body["message"] == "" {
+		t.Errorf("expected a 503 with a JSON message, got %d %s %v", res.StatusCode, res.Header.Get("Content-Type"), body)
+	}
+
+	req, _ = http.NewRequest("GET", server.URL+"/namespace/repo/objects/"+contentOid+"/upload", nil)
+	req.SetBasicAuth(testUser, testPass)
+	req.Header.Set("Accept", metaMediaType)
+	res, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("response error: %s", err)
+	}
+	res.Body.Close()
+	if res.StatusCode != 503 {
+		t.Errorf("expected the upload status to fail with 503, got %d", res.StatusCode)
+	}
+
+	req, _ = http.NewRequest("HEAD", server.URL+"/namespace/repo/objects/"+contentOid+"/tus", nil)
+	req.SetBasicAuth(testUser, testPass)
+	req.Header.Set("Tus-Resumable", tusVersion)
+	res, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("response error: %s", err)
+	}
+	res.Body.Close()
+	if res.StatusCode != 503 || res.Header.Get("Retry-After") == "" {
+		t.Errorf("expected the tus upload offset to fail with 503, got %d", res.StatusCode)
+	}
+
+	req, _ = http.NewRequest("POST", server.URL+"/namespace/repo/objects/batch", bytes.NewBufferString(`{"operation":"upload","objects":[{"oid":"`+contentOid+`", "size":10}]}`))
+	req.SetBasicAuth(testUser, testPass)
+	req.Header.Set("Accept", metaMediaType)
+	res, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("response error: %s", err)
+	}
+	res.Body.Close()
+	if res.StatusCode != 503 {
+		t.Errorf("expected the batch to fail with 503, got %d", res.StatusCode)
+	}
+}
+
+func doBatch(t *testing.T, operation, oid string, size int64) *http.Response {
+	return doBatchTransfers(t, operation, oid, size, `["basic"]`)
+}
+
+func doBatchTransfers(t *testing.T, operation, oid string, size int64, transfers string) *http.Response {
+	req, err := http.NewRequest("POST", lfsServer.URL+"/namespace/repo/objects/batch", nil)
+	if err != nil {
+		t.Fatalf("request error: %s", err)
+	}
+	req.SetBasicAuth(testUser, testPass)
+	req.Header.Set("Accept", metaMediaType)
+
+	buf := bytes.NewBufferString(fmt.Sprintf(`{"operation":"%s","transfers":%s,"objects":[{"oid":"%s", "size":%d}]}`, operation, transfers, oid, size))
+	req.Body = ioutil.NopCloser(buf)
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("response error: %s", err)
+	}
+	return res
+}
+
+func TestVerify(t *testing.T) {
+	res := doVerify(t, contentOid, contentSize)
+	if res.StatusCode != 200 {
+		t.Fatalf("expected status 200, got %d", res.StatusCode)
+	}
+}
+
+func TestVerifySizeMismatch(t *testing.T) {
+	res := doVerify(t, contentOid, contentSize+1)
+	if res.StatusCode != 422 {
+		t.Fatalf("expected status 422, got %d", res.StatusCode)
+	}
+}
+
+func TestVerifyMissing(t *testing.T) {
+	res := doVerify(t, "bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb", 1234)
+	if res.StatusCode != 404 {
+		t.Fatalf("expected status 404, got %d", res.StatusCode)
+	}
+}
+
+// corruptContentStore is a presigning store whose objects were uploaded with the wrong content
+type corruptContentStore struct {
+	presigningContentStore
+	deleted bool
+}
+
+func (s *corruptContentStore) Get(meta *MetaObject) (io.Reader, error) {
+	return strings.NewReader(strings.Repeat("x", int(meta.Size))), nil
+}
+
+func (s *corruptContentStore) Delete(meta *MetaObject) error {
+	s.deleted = true
+	return nil
+}
+
+func TestVerifyDirectLinksHash(t *testing.T) {
+	Config.Aws.DirectLinks = true
+	defer func() { Config.Aws.DirectLinks = false }()
+
+	for _, store := range []*corruptContentStore{nil, {presigningContentStore: presigningContentStore{testContentStore}}} {
+		var app *App
+		expected := 200
+		if store == nil {
+			app = NewApp(&presigningContentStore{testContentStore}, testMetaStore)
+		} else {
+			app = NewApp(store, testMetaStore)
+			expected = 422
+		}
+
+		req, err := http.NewRequest("POST", "/namespace/repo/objects/verify", bytes.NewBufferString(fmt.Sprintf(`{"oid":"%s", "size":%d}`, contentOid, contentSize)))
+		if err != nil {
+			t.Fatalf("request error: %s", err)
+		}
+		req.SetBasicAuth(testUser, testPass)
+		req.Header.Set("Accept", metaMediaType)
+		res := httptest.NewRecorder()
+		app.ServeHTTP(res, req)
+
+		if res.Code != expected {
+			t.Errorf("expected status %d, got %d", expected, res.Code)
+		}
+		if store != nil && !store.deleted {
+			t.Errorf("expected content not matching the oid to be deleted")
+		}
+	}
+}
+
+func doVerify(t *testing.T, oid string, size int64) *http.Response {
+	req, err := http.NewRequest("POST", lfsServer.URL+"/namespace/repo/objects/verify", nil)
+	if err != nil {
+		t.Fatalf("request error: %s", err)
+	}
+	req.SetBasicAuth(testUser, testPass)
+	req.Header.Set("Accept", metaMediaType)
+
+	buf := bytes.NewBufferString(fmt.Sprintf(`{"oid":"%s", "size":%d}`, oid, size))
+	req.Body = ioutil.NopCloser(buf)
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("response error: %s", err)
+	}
+	return res
+}
+
+func TestLocks(t *testing.T) {
+	res := doLockRequest(t, "POST", "/namespace/repo/locks", testUser, testPass, `{"path":"art/hero.psd"}`)
+	if res.StatusCode != 201 {
+		t.Fatalf("expected status 201, got %d", res.StatusCode)
+	}
+
+	var lr LockResponse
+	json.NewDecoder(res.Body).Decode(&lr)
+	if lr.Lock == nil || lr.Lock.Path != "art/hero.psd" {
+		t.Fatalf("expected lock for art/hero.psd, got %+v", lr.Lock)
+	}
+	if lr.Lock.Owner == nil || lr.Lock.Owner.Name != testUser {
+		t.Fatalf("expected lock to be owned by %s, got %+v", testUser, lr.Lock.Owner)
+	}
+	id := lr.Lock.Id
+
+	res = doLockRequest(t, "POST", "/namespace/repo/locks", testUser, testPass, `{"path":"art/hero.psd"}`)
+	if res.StatusCode != 409 {
+		t.Fatalf("expected status 409, got %d", res.StatusCode)
+	}
+
+	res = doLockRequest(t, "GET", "/namespace/repo/locks?path=art/hero.psd", testUser, testPass, "")
+	if res.StatusCode != 200 {
+		t.Fatalf("expected status 200, got %d", res.StatusCode)
+	}
+	var ll LockList
+	json.NewDecoder(res.Body).Decode(&ll)
+	if len(ll.Locks) != 1 || ll.Locks[0].Id != id {
+		t.Fatalf("expected to list lock %s, got %+v", id, ll.Locks)
+	}
+
+	res = doLockRequest(t, "GET", "/namespace/repo/locks?cursor=unknown", testUser, testPass, "")
+	if res.StatusCode != 400 {
+		t.Fatalf("expected status 400 for an unknown cursor, got %d", res.StatusCode)
+	}
+	res = doLockRequest(t, "POST", "/namespace/repo/locks/verify", testUser, testPass, `{"cursor":"unknown"}`)
+	if res.StatusCode != 400 {
+		t.Fatalf("expected status 400 for an unknown cursor, got %d", res.StatusCode)
+	}
+
+	res = doLockRequest(t, "POST", "/namespace/repo/locks/verify", testUser, testPass, `{}`)
+	if res.StatusCode != 200 {
+		t.Fatalf("expected status 200, got %d", res.StatusCode)
+	}
+	var vl VerifiableLockList
+	json.NewDecoder(res.Body).Decode(&vl)
+	if len(vl.Ours) != 1 || len(vl.Theirs) != 0 {
+		t.Fatalf("expected 1 lock of ours and none of theirs, got %d and %d", len(vl.Ours), len(vl.Theirs))
+	}
+
+	if err := testMetaStore.AddUser("artist", "brush"); err != nil {
+		t.Fatalf("error adding user: %s", err)
+	}
+	defer testMetaStore.DeleteUser("artist")
+
+	res = doLockRequest(t, "POST", "/namespace/repo/locks/"+id+"/unlock", "artist", "brush", `{}`)
+	if res.StatusCode != 403 {
+		t.Fatalf("expected status 403, got %d", res.StatusCode)
+	}
+
+	res = doLockRequest(t, "POST", "/namespace/repo/locks/"+id+"/unlock", "artist", "brush", `{"force":true}`)
+	if res.StatusCode != 200 {
+		t.Fatalf("expected status 200, got %d", res.StatusCode)
+	}
+
+	res = doLockRequest(t, "POST", "/namespace/repo/locks/"+id+"/unlock", testUser, testPass, `{}`)
+	if res.StatusCode != 404 {
+		t.Fatalf("expected status 404, got %d", res.StatusCode)
+	}
+}
+
+func TestLocksUnauthed(t *testing.T) {
+	res := doLockRequest(t, "POST", "/namespace/repo/locks", "", "", `{"path":"art/hero.psd"}`)
+	if res.StatusCode != 401 {
+		t.Fatalf("expected status 401, got %d", res.StatusCode)
+	}
+}
+
+func doLockRequest(t *testing.T, method, path, user, pass, body string) *http.Response {
+	req, err := http.NewRequest(method, lfsServer.URL+path, nil)
+	if err != nil {
+		t.Fatalf("request error: %s", err)
+	}
+	if user != "" {
+		req.SetBasicAuth(user, pass)
+	}
+	req.Header.Set("Accept", metaMediaType)
+	if body != "" {
+		req.Body = ioutil.NopCloser(bytes.NewBufferString(body))
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("response error: %s", err)
+	}
+	return res
+}
+
+func TestUserServiceAuthorization(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		access := r.URL.Query().Get("project") == "namespace/repo" && r.URL.Query().Get("action") == "download"
+		fmt.Fprintf(w, `{"access": %t, "message": "no access"}`, access)
+	}))
+	defer srv.Close()
+	defer setupUserServiceConfig(srv.URL)()
+
+	req, err := http.NewRequest("GET", lfsServer.URL+"/namespace/repo/objects/"+contentOid, nil)
+	if err != nil {
+		t.Fatalf("request error: %s", err)
+	}
+	req.SetBasicAuth(testUser, testPass)
+	req.Header.Set("Accept", contentMediaType)
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("response error: %s", err)
+	}
+	if res.StatusCode != 200 {
+		t.Fatalf("expected status 200, got %d", res.StatusCode)
+	}
+
+	req, err = http.NewRequest("GET", lfsServer.URL+"/namespace/other/objects/"+contentOid, nil)
+	if err != nil {
+		t.Fatalf("request error: %s", err)
+	}
+	req.SetBasicAuth(testUser, testPass)
+	req.Header.Set("Accept", contentMediaType)
+
+	res, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("response error: %s", err)
+	}
+	if res.StatusCode != 403 {
+		t.Fatalf("expected status 403, got %d", res.StatusCode)
+	}
+
+	res = doBatch(t, "upload", contentOid, contentSize)
+	if res.StatusCode != 403 {
+		t.Fatalf("expected status 403, got %d", res.StatusCode)
+	}
+}
+
+func TestPut(t *testing.T) {
+	req, err := http.NewRequest("PUT", lfsServer.URL+"/namespace/repo/objects/"+contentOid, nil)
+	if err != nil {
+		t.Fatalf("request error: %s", err)
+	}
+	req.SetBasicAuth(testUser, testPass)
+	req.Header.Set("Accept", contentMediaType)
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Body = ioutil.NopCloser(bytes.NewBuffer([]byte(content)))
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("response error: %s", err)
+	}
+
+	if res.StatusCode != 200 {
+		t.Fatalf("expected status 200, got %d", res.StatusCode)
+	}
+
+	r, err := testContentStore.Get(&MetaObject{Oid: contentOid})
+	if err != nil {
+		t.Fatalf("error retreiving from content store: %s", err)
+	}
+	c, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatalf("error reading content: %s", err)
+	}
+	if string(c) != content {
+		t.Fatalf("expected content, got `%s`", string(c))
+	}
+}
+
+func TestPutChunked(t *testing.T) {
+	chunkOid := "6ae8a75555209fd6c44157c0aed8016e763ff435a19cf186f76863140143ff72"
+	_, err := testMetaStore.Put(&RequestVars{Authorization: testAuth, Oid: chunkOid, Size: 12, Namespace: testNamespace, Repo: testRepo})
+	if err != nil {
+		t.Fatalf("error seeding meta store: %s", err)
+	}
+
+	res := putChunk(t, chunkOid, "bytes 0-4/12", "test ")
+	if res.StatusCode != 202 || res.Header.Get("Upload-Offset") != "5" {
+		t.Fatalf("expected status 202 at offset 5, got %d at %s", res.StatusCode, res.Header.Get("Upload-Offset"))
+	}
+
+	req, err := http.NewRequest("GET", lfsServer.URL+"/namespace/repo/objects/"+chunkOid+"/upload", nil)
+	if err != nil {
+		t.Fatalf("request error: %s", err)
+	}
+	req.SetBasicAuth(testUser, testPass)
+	req.Header.Set("Accept", metaMediaType)
+	res, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("response error: %s", err)
+	}
+	var status UploadStatus
+	json.NewDecoder(res.Body).Decode(&status)
+	if res.StatusCode != 200 || status.Offset != 5 || status.Size != 12 {
+		t.Fatalf("expected upload status at offset 5, got %d %+v", res.StatusCode, status)
+	}
+
+	res = putChunk(t, chunkOid, "bytes 2-11/12", "st content")
+	if res.StatusCode != 409 || res.Header.Get("Upload-Offset") != "5" {
+		t.Fatalf("expected status 409 at offset 5, got %d at %s", res.StatusCode, res.Header.Get("Upload-Offset"))
+	}
+
+	res = putChunk(t, chunkOid, "bytes 5-11/12", "content")
+	if res.StatusCode != 200 {
+		t.Fatalf("expected status 200, got %d", res.StatusCode)
+	}
+	if !testContentStore.Exists(&MetaObject{Oid: chunkOid}) {
+		t.Fatalf("expected content to exist after the last chunk")
+	}
+}
+
+func putChunk(t *testing.T, oid, contentRange, body string) *http.Response {
+	req, err := http.NewRequest("PUT", lfsServer.URL+"/namespace/repo/objects/"+oid, bytes.NewBufferString(body))
+	if err != nil {
+		t.Fatalf("request error: %s", err)
+	}
+	req.SetBasicAuth(testUser, testPass)
+	req.Header.Set("Accept", contentMediaType)
+	req.Header.Set("Content-Range", contentRange)
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("response error: %s", err)
+	}
+	return res
+}
+
+func TestBatchTus(t *testing.T) {
+	tusOid := "b2357930f5f5ecbb19571f5b64108ad30b10c8425977322ca4780c04b252ffa7"
+	res := doBatchTransfers(t, "upload", tusOid, 11, `["tus","basic"]`)
+	if res.StatusCode != 200 {
+		t.Fatalf("expected status 200, got %d", res.StatusCode)
+	}
+
+	var br BatchResponse
+	json.NewDecoder(res.Body).Decode(&br)
+	if br.Transfer != "tus" {
+		t.Fatalf("expected transfer to be tus, got %s", br.Transfer)
+	}
+	upload, ok := br.Objects[0].Actions["upload"]
+	if !ok || !strings.HasSuffix(upload.Href, "/namespace/repo/objects/"+tusOid+"/tus") {
+		t.Fatalf("expected a tus upload link, got %+v", br.Objects[0].Actions)
+	}
+
+	res = doTus(t, "HEAD", tusOid, "", "")
+	if res.StatusCode != 200 || res.Header.Get("Upload-Offset") != "0" || res.Header.Get("Upload-Length") != "11" {
+		t.Fatalf("expected an empty upload of 11 bytes, got %d %v", res.StatusCode, res.Header)
+	}
+
+	res = doTus(t, "PATCH", tusOid, "4", "content")
+	if res.StatusCode != 409 {
+		t.Fatalf("expected status 409, got %d", res.StatusCode)
+	}
+
+	res = doTus(t, "PATCH", tusOid, "0", "tus ")
+	if res.StatusCode != 204 || res.Header.Get("Upload-Offset") != "4" {
+		t.Fatalf("expected status 204 at offset 4, got %d at %s", res.StatusCode, res.Header.Get("Upload-Offset"))
+	}
+
+	res = doTus(t, "PATCH", tusOid, "4", "content")
+	if res.StatusCode != 204 || res.Header.Get("Upload-Offset") != "11" {
+		t.Fatalf("expected status 204 at offset 11, got %d at %s", res.StatusCode, res.Header.Get("Upload-Offset"))
+	}
+	if !testContentStore.Exists(&MetaObject{Oid: tusOid}) {
+		t.Fatalf("expected content to exist after the upload completed")
+	}
+}
+
+func TestTusVersionRequired(t *testing.T) {
+	req, err := http.NewRequest("HEAD", lfsServer.URL+"/namespace/repo/objects/"+contentOid+"/tus", nil)
+	if err != nil {
+		t.Fatalf("request error: %s", err)
+	}
+	req.SetBasicAuth(testUser, testPass)
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("response error: %s", err)
+	}
+	if res.StatusCode != 412 || res.Header.Get("Tus-Version") != tusVersion {
+		t.Fatalf("expected status 412 with Tus-Version, got %d %v", res.StatusCode, res.Header)
+	}
+}
+
+func doTus(t *testing.T, method, oid, offset, body string) *http.Response {
+	req, err := http.NewRequest(method, lfsServer.URL+"/namespace/repo/objects/"+oid+"/tus", bytes.NewBufferString(body))
+	if err != nil {
+		t.Fatalf("request error: %s", err)
+	}
+	req.SetBasicAuth(testUser, testPass)
+	req.Header.Set("Tus-Resumable", tusVersion)
+	if method == "PATCH" {
+		req.Header.Set("Content-Type", "application/offset+octet-stream")
+		req.Header.Set("Upload-Offset", offset)
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("response error: %s", err)
 	}
+	return res
 }
 
 func TestMediaTypesRequired(t *testing.T) {
@@ -313,6 +1286,7 @@ var (
 	noAuthOid         = "4609ed10888c145d228409aa5587bab9fe166093bb7c155491a96d079c9149be"
 	extraRepo         = "mytestproject"
 	testRepo          = "repo"
+	testNamespace     = "namespace"
 )
 
 func baseURL() string {
@@ -366,7 +1340,7 @@ func seedMetaStore() error {
 		return err
 	}
 
-	rv := &RequestVars{Authorization: testAuth, Oid: contentOid, Size: contentSize, Repo: testRepo}
+	rv := &RequestVars{Authorization: testAuth, Oid: contentOid, Size: contentSize, Namespace: testNamespace, Repo: testRepo}
 	if _, err := testMetaStore.Put(rv); err != nil {
 		return err
 	}
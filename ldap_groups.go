@@ -0,0 +1,188 @@
+package main
+
+import (
+	"fmt"
+	"github.com/nmcclain/ldap"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ldapGroupRule grants read (download) or write (download and push) rights on the
+// projects matching pattern, e.g. games/*, to the members of group
+type ldapGroupRule struct {
+	group   string
+	pattern string
+	rights  string
+}
+
+// parseLdapGroups reads Config.Ldap.Groups, a comma separated list of group:pattern:rights
+// such as "cn=art-team:games/*:write, cn=qa:games/tetris:read". Groups are named by the
+// first RDN of their DN, or by their whole DN when it has no commas.
+func parseLdapGroups(s string) ([]*ldapGroupRule, error) {
+	var rules []*ldapGroupRule
+	for _, r := range strings.Split(s, ",") {
+		if r = strings.TrimSpace(r); r == "" {
+			continue
+		}
+		parts := strings.Split(r, ":")
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("Invalid LDAP group rule %q, expected group:namespace/repo:rights", r)
+		}
+		rule := &ldapGroupRule{group: strings.TrimSpace(parts[0]), pattern: strings.TrimSpace(parts[1]), rights: strings.TrimSpace(parts[2])}
+		if rule.rights != "read" && rule.rights != "write" {
+			return nil, fmt.Errorf("Invalid rights %q in LDAP group rule %q, expected read or write", rule.rights, r)
+		}
+		if _, err := path.Match(rule.pattern, ""); err != nil {
+			return nil, fmt.Errorf("Invalid project pattern %q in LDAP group rule %q", rule.pattern, r)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// allows reports whether the rule lets a member of groups perform action on project
+func (rule *ldapGroupRule) allows(groups []string, project, action string) bool {
+	if action != "download" && rule.rights != "write" {
+		return false
+	}
+	if ok, _ := path.Match(rule.pattern, project); !ok {
+		return false
+	}
+	for _, g := range groups {
+		if strings.EqualFold(g, rule.group) || strings.HasPrefix(strings.ToLower(g), strings.ToLower(rule.group)+",") {
+			return true
+		}
+	}
+	return false
+}
+
+// ldapGroupsEnabled reports whether projects are authorized by LDAP group membership
+func ldapGroupsEnabled() bool {
+	return Config.Ldap.Enabled && strings.TrimSpace(Config.Ldap.Groups) != ""
+}
+
+// ldapGroupsCan checks whether the LDAP groups of username grant action on project.
+// Returns the access and a message explaining a denial.
+func ldapGroupsCan(username, project, action string) (bool, string) {
+	rules, err := parseLdapGroups(Config.Ldap.Groups)
+	if err != nil {
+		logger.Log(kv{"fn": "ldapGroupsCan", "error": err.Error()})
+		return false, "Invalid LDAP group configuration"
+	}
+	groups, err := groupCache.get(username)
+	if err != nil {
+		logger.Log(kv{"fn": "ldapGroupsCan", "user": username, "error": err.Error()})
+		return false, "Unable to reach LDAP"
+	}
+	for _, rule := range rules {
+		if rule.allows(groups, project, action) {
+			return true, ""
+		}
+	}
+	return false, fmt.Sprintf("None of the LDAP groups of %s allow %s on %s", username, action, project)
+}
+
+type ldapGroupEntry struct {
+	groups  []string
+	expires time.Time
+}
+
+// ldapGroupCache remembers the groups of each user for Config.Ldap.GroupTTL
+type ldapGroupCache struct {
+	mu      sync.Mutex
+	entries map[string]*ldapGroupEntry
+	lookup  func(user string) ([]string, error)
+}
+
+var groupCache = &ldapGroupCache{entries: make(map[string]*ldapGroupEntry), lookup: lookupLdapGroups}
+
+func (c *ldapGroupCache) get(user string) ([]string, error) {
+	c.mu.Lock()
+	entry, ok := c.entries[user]
+	c.mu.Unlock()
+	if ok && time.Now().Before(entry.expires) {
+		return entry.groups, nil
+	}
+
+	groups, err := c.lookup(user)
+	if err != nil {
+		return nil, err
+	}
+	if Config.Ldap.GroupTTL > 0 {
+		c.mu.Lock()
+		c.entries[user] = &ldapGroupEntry{groups: groups, expires: time.Now().Add(Config.Ldap.GroupTTL)}
+		c.mu.Unlock()
+	}
+	return groups, nil
+}
+
+func (c *ldapGroupCache) forget(user string) {
+	c.mu.Lock()
+	delete(c.entries, user)
+	c.mu.Unlock()
+}
+
+func (c *ldapGroupCache) clear() {
+	c.mu.Lock()
+	c.entries = make(map[string]*ldapGroupEntry)
+	c.mu.Unlock()
+}
+
+// lookupLdapGroups returns the DNs of the groups of user, read from the memberOf
+// attribute of the user, or with GroupLookup = groupOfNames, found by searching for
+// groups listing the user as a member
+func lookupLdapGroups(user string) ([]string, error) {
+	if strings.EqualFold(Config.Ldap.GroupLookup, "groupOfNames") {
+		return searchLdapGroups(user)
+	}
+
+	search := &ldap.SearchRequest{
+		BaseDN:     Config.Ldap.Base,
+		Filter:     fmt.Sprintf("(&(objectclass=%s)(%s=%s))", Config.Ldap.UserObjectClass, Config.Ldap.UserCn, ldapEscape(user)),
+		Scope:      1,
+		Attributes: []string{"memberOf"},
+	}
+	r, err := LdapSearch(search)
+	if err == errNoLdapSearchResults {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return r.Entries[0].GetAttributeValues("memberOf"), nil
+}
+
+func searchLdapGroups(user string) ([]string, error) {
+	dn, err := findUserDn(user)
+	if err == errLdapUserNotFound || err == errNoLdapSearchResults {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	base := Config.Ldap.GroupBase
+	if base == "" {
+		base = Config.Ldap.Base
+	}
+	search := &ldap.SearchRequest{
+		BaseDN:     base,
+		Filter:     fmt.Sprintf("(&(objectclass=%s)(%s=%s))", Config.Ldap.GroupClass, Config.Ldap.GroupMember, ldapEscape(dn)),
+		Scope:      2,
+		Attributes: []string{"dn"},
+	}
+	r, err := LdapSearch(search)
+	if err == errNoLdapSearchResults {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var groups []string
+	for _, e := range r.Entries {
+		groups = append(groups, e.DN)
+	}
+	return groups, nil
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestParseLdapGroups(t *testing.T) {
+	rules, err := parseLdapGroups("cn=art-team:games/*:write, cn=qa:games/tetris:read")
+	if err != nil {
+		t.Fatalf("expected rules, got: %s", err)
+	}
+	if len(rules) != 2 || rules[0].group != "cn=art-team" || rules[1].pattern != "games/tetris" || rules[1].rights != "read" {
+		t.Errorf("unexpected rules: %+v %+v", rules[0], rules[1])
+	}
+
+	for _, bad := range []string{"cn=art-team:games/*", "cn=art-team:games/*:admin", "cn=art-team:games/[:read"} {
+		if _, err := parseLdapGroups(bad); err == nil {
+			t.Errorf("expected %q to be rejected", bad)
+		}
+	}
+}
+
+func TestLdapGroupRuleAllows(t *testing.T) {
+	rules, _ := parseLdapGroups("cn=art-team:games/*:write, cn=qa:games/tetris:read")
+	groups := []string{"CN=qa,ou=groups,o=company"}
+
+	if !rules[1].allows(groups, "games/tetris", "download") {
+		t.Errorf("expected qa to download games/tetris")
+	}
+	if rules[1].allows(groups, "games/tetris", "push") {
+		t.Errorf("expected read rights not to allow push")
+	}
+	if rules[1].allows(groups, "games/doom", "download") {
+		t.Errorf("expected qa to be limited to games/tetris")
+	}
+	if rules[0].allows(groups, "games/tetris", "download") {
+		t.Errorf("expected art-team rules not to apply to qa")
+	}
+	if !rules[0].allows([]string{"cn=art-team,ou=groups,o=company"}, "games/doom", "push") {
+		t.Errorf("expected art-team to push to games/*")
+	}
+	if rules[0].allows([]string{"cn=art-team-alumni,ou=groups,o=company"}, "games/doom", "push") {
+		t.Errorf("expected groups to match on their whole RDN")
+	}
+}
+
+func TestLdapGroupsAuthorize(t *testing.T) {
+	lookups := 0
+	defer setupLdapGroups("cn=art-team:games/*:write", func(user string) ([]string, error) {
+		lookups++
+		if user == "alice" {
+			return []string{"cn=art-team,ou=groups,o=company"}, nil
+		}
+		return nil, nil
+	})()
+
+	app := &App{authenticator: authChain{&stubAuthenticator{user: "alice", password: "one"}, &stubAuthenticator{user: "bob", password: "two"}}}
+	authorize := func(user, password, project, action string) int {
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/", nil)
+		rv := &RequestVars{User: user, Password: password, Namespace: project, Repo: "doom"}
+		app.authorize(w, r, rv, action)
+		return w.Code
+	}
+
+	if code := authorize("alice", "one", "games", "push"); code != 200 {
+		t.Errorf("expected art-team to push to games/doom, got %d", code)
+	}
+	if code := authorize("alice", "one", "tools", "download"); code != 403 {
+		t.Errorf("expected art-team to be denied tools/doom, got %d", code)
+	}
+	if code := authorize("bob", "two", "games", "download"); code != 403 {
+		t.Errorf("expected users outside the groups to be denied, got %d", code)
+	}
+	if lookups != 2 {
+		t.Errorf("expected the groups of each user to be cached, got %d lookups", lookups)
+	}
+
+	groupCache.forget("alice")
+	authorize("alice", "one", "games", "download")
+	if lookups != 3 {
+		t.Errorf("expected forgotten users to be looked up again, got %d lookups", lookups)
+	}
+}
+
+func TestLdapGroupsLookup(t *testing.T) {
+	setupMetaAuth()
+	defer tearDownMetaAuth()
+
+	groups, err := lookupLdapGroups(testUser)
+	if err != nil {
+		t.Fatalf("expected the groups of %s, got: %s", testUser, err)
+	}
+	if len(groups) != 2 || groups[0] != "cn=art-team,ou=groups,o=company" {
+		t.Errorf("expected %s to be in art-team and qa, got: %v", testUser, groups)
+	}
+}
+
+// setupLdapGroups enables the LDAP group mapping with a stubbed group lookup and
+// returns a func restoring the configuration
+func setupLdapGroups(groups string, lookup func(string) ([]string, error)) func() {
+	oldLdap, oldPublic, oldLookup := Config.Ldap, Config.Public, groupCache.lookup
+	Config.Ldap = &LdapConfig{Enabled: true, Groups: groups, GroupTTL: time.Minute}
+	Config.Public = false
+	groupCache.lookup = lookup
+	groupCache.clear()
+	return func() {
+		Config.Ldap, Config.Public, groupCache.lookup = oldLdap, oldPublic, oldLookup
+		groupCache.clear()
+	}
+}
@@ -0,0 +1,307 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+const defaultLockLimit = 100
+
+// Lock is a file lock as seen by clients of the lfs server.
+type Lock struct {
+	Id       string     `json:"id"`
+	Path     string     `json:"path"`
+	LockedAt time.Time  `json:"locked_at"`
+	Owner    *LockOwner `json:"owner,omitempty"`
+}
+
+// LockOwner is the user that holds a lock.
+type LockOwner struct {
+	Name string `json:"name"`
+}
+
+// LockRef is the git ref a lock request applies to.
+type LockRef struct {
+	Name string `json:"name"`
+}
+
+// LockRequest is the body of a create lock request.
+type LockRequest struct {
+	Path string   `json:"path"`
+	Ref  *LockRef `json:"ref,omitempty"`
+}
+
+// LockResponse is the body of a create or delete lock response.
+type LockResponse struct {
+	Lock    *Lock  `json:"lock,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// LockList is the body of a list locks response.
+type LockList struct {
+	Locks      []*Lock `json:"locks"`
+	NextCursor string  `json:"next_cursor,omitempty"`
+}
+
+// VerifiableLockRequest is the body of a verify locks request.
+type VerifiableLockRequest struct {
+	Ref    *LockRef `json:"ref,omitempty"`
+	Cursor string   `json:"cursor,omitempty"`
+	Limit  int      `json:"limit,omitempty"`
+}
+
+// VerifiableLockList is the body of a verify locks response.
+type VerifiableLockList struct {
+	Ours       []*Lock `json:"ours"`
+	Theirs     []*Lock `json:"theirs"`
+	NextCursor string  `json:"next_cursor,omitempty"`
+}
+
+// UnlockRequest is the body of a delete lock request.
+type UnlockRequest struct {
+	Force bool     `json:"force"`
+	Ref   *LockRef `json:"ref,omitempty"`
+}
+
+func (a *App) addLocks(r *mux.Router) {
+	r.HandleFunc("/{namespace}/{repo}/locks", a.CreateLockHandler).Methods("POST").MatcherFunc(MetaMatcher)
+	r.HandleFunc("/{namespace}/{repo}/locks", a.LocksHandler).Methods("GET").MatcherFunc(MetaMatcher)
+	r.HandleFunc("/{namespace}/{repo}/locks/verify", a.LocksVerifyHandler).Methods("POST").MatcherFunc(MetaMatcher)
+	r.HandleFunc("/{namespace}/{repo}/locks/{id}/unlock", a.DeleteLockHandler).Methods("POST").MatcherFunc(MetaMatcher)
+}
+
+// CreateLockHandler locks a path for the authenticated user
+func (a *App) CreateLockHandler(w http.ResponseWriter, r *http.Request) {
+	rv := unpackVars(r)
+	if !a.authorize(w, r, rv, "push") {
+		return
+	}
+	if rv.User == "" {
+		requireAuth(w, r)
+		return
+	}
+
+	var lr LockRequest
+	if err := json.NewDecoder(r.Body).Decode(&lr); err != nil || lr.Path == "" {
+		writeStatus(w, r, 422)
+		return
+	}
+
+	lock := &MetaLock{Id: newLockId(), Path: lr.Path, Owner: rv.User, LockedAt: time.Now().UTC()}
+	err := a.metaStore.AddLock(rv, lock)
+	if err == errLockExists {
+		existing, _ := a.findLock(rv, func(l *MetaLock) bool { return l.Path == lr.Path })
+		writeLockResponse(w, r, 409, &LockResponse{Lock: representLock(existing), Message: "already created lock"})
+		return
+	}
+	if err != nil {
+		writeLockError(w, r, err)
+		return
+	}
+
+	writeLockResponse(w, r, 201, &LockResponse{Lock: representLock(lock)})
+}
+
+// LocksHandler lists the locks of a repo
+func (a *App) LocksHandler(w http.ResponseWriter, r *http.Request) {
+	rv := unpackVars(r)
+	if !a.authorize(w, r, rv, "download") {
+		return
+	}
+	locks, err := a.metaStore.Locks(rv)
+	if err != nil {
+		writeLockError(w, r, err)
+		return
+	}
+
+	query := r.URL.Query()
+	path, id := query.Get("path"), query.Get("id")
+	var filtered []*MetaLock
+	for _, l := range locks {
+		if (path == "" || l.Path == path) && (id == "" || l.Id == id) {
+			filtered = append(filtered, l)
+		}
+	}
+
+	limit, _ := strconv.Atoi(query.Get("limit"))
+	page, next, err := pageLocks(filtered, query.Get("cursor"), limit)
+	if err != nil {
+		writeLockError(w, r, err)
+		return
+	}
+
+	list := &LockList{Locks: []*Lock{}, NextCursor: next}
+	for _, l := range page {
+		list.Locks = append(list.Locks, representLock(l))
+	}
+	writeLockResponse(w, r, 200, list)
+}
+
+// LocksVerifyHandler lists the locks of a repo, split by whether the authenticated user owns them
+func (a *App) LocksVerifyHandler(w http.ResponseWriter, r *http.Request) {
+	rv := unpackVars(r)
+	if !a.authorize(w, r, rv, "download") {
+		return
+	}
+	if rv.User == "" {
+		requireAuth(w, r)
+		return
+	}
+
+	var vr VerifiableLockRequest
+	json.NewDecoder(r.Body).Decode(&vr)
+
+	locks, err := a.metaStore.Locks(rv)
+	if err != nil {
+		writeLockError(w, r, err)
+		return
+	}
+
+	page, next, err := pageLocks(locks, vr.Cursor, vr.Limit)
+	if err != nil {
+		writeLockError(w, r, err)
+		return
+	}
+
+	list := &VerifiableLockList{Ours: []*Lock{}, Theirs: []*Lock{}, NextCursor: next}
+	for _, l := range page {
+		if l.Owner == rv.User {
+			list.Ours = append(list.Ours, representLock(l))
+		} else {
+			list.Theirs = append(list.Theirs, representLock(l))
+		}
+	}
+	writeLockResponse(w, r, 200, list)
+}
+
+// DeleteLockHandler removes a lock. Locks owned by other users are only removed when forced.
+func (a *App) DeleteLockHandler(w http.ResponseWriter, r *http.Request) {
+	rv := unpackVars(r)
+	if !a.authorize(w, r, rv, "push") {
+		return
+	}
+	if rv.User == "" {
+		requireAuth(w, r)
+		return
+	}
+
+	var ur UnlockRequest
+	json.NewDecoder(r.Body).Decode(&ur)
+
+	id := mux.Vars(r)["id"]
+	lock, err := a.findLock(rv, func(l *MetaLock) bool { return l.Id == id })
+	if err != nil {
+		writeLockError(w, r, err)
+		return
+	}
+
+	if lock.Owner != rv.User && !ur.Force {
+		writeLockResponse(w, r, 403, &LockResponse{Lock: representLock(lock), Message: fmt.Sprintf("lock is owned by %s", lock.Owner)})
+		return
+	}
+
+	if err := a.metaStore.DeleteLock(rv, id); err != nil {
+		writeLockError(w, r, err)
+		return
+	}
+
+	writeLockResponse(w, r, 200, &LockResponse{Lock: representLock(lock)})
+}
+
+func (a *App) findLock(rv *RequestVars, match func(*MetaLock) bool) (*MetaLock, error) {
+	locks, err := a.metaStore.Locks(rv)
+	if err != nil {
+		return nil, err
+	}
+	for _, l := range locks {
+		if match(l) {
+			return l, nil
+		}
+	}
+	return nil, errLockNotFound
+}
+
+// pageLocks orders locks by creation time and returns the page starting at the lock
+// with the id in cursor, along with the cursor for the next page. A cursor that is
+// not among the locks, e.g. because that lock was removed, fails with errLockCursor.
+func pageLocks(locks []*MetaLock, cursor string, limit int) ([]*MetaLock, string, error) {
+	if limit <= 0 {
+		limit = defaultLockLimit
+	}
+	sort.Sort(locksByTime(locks))
+
+	start := 0
+	if cursor != "" {
+		start = -1
+		for i, l := range locks {
+			if l.Id == cursor {
+				start = i
+				break
+			}
+		}
+		if start < 0 {
+			return nil, "", errLockCursor
+		}
+	}
+
+	end := start + limit
+	if end >= len(locks) {
+		return locks[start:], "", nil
+	}
+	return locks[start:end], locks[end].Id, nil
+}
+
+type locksByTime []*MetaLock
+
+func (l locksByTime) Len() int      { return len(l) }
+func (l locksByTime) Swap(i, j int) { l[i], l[j] = l[j], l[i] }
+func (l locksByTime) Less(i, j int) bool {
+	if l[i].LockedAt.Equal(l[j].LockedAt) {
+		return l[i].Id < l[j].Id
+	}
+	return l[i].LockedAt.Before(l[j].LockedAt)
+}
+
+func representLock(l *MetaLock) *Lock {
+	if l == nil {
+		return nil
+	}
+	return &Lock{Id: l.Id, Path: l.Path, LockedAt: l.LockedAt, Owner: &LockOwner{Name: l.Owner}}
+}
+
+func newLockId() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return fmt.Sprintf("%x", b)
+}
+
+func writeLockResponse(w http.ResponseWriter, r *http.Request, status int, body interface{}) {
+	w.Header().Set("Content-Type", metaMediaType)
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.Encode(body)
+	logRequest(r, status)
+}
+
+func writeLockError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case isAuthError(err):
+		requireAuth(w, r)
+	case isUnavailable(err):
+		writeUnavailable(w, r, err)
+	case err == errLockNotFound:
+		writeStatus(w, r, 404)
+	case err == errLockCursor:
+		writeStatusMessage(w, r, 400, err.Error())
+	default:
+		logger.Log(kv{"fn": "writeLockError", "error": err.Error()})
+		writeStatus(w, r, 500)
+	}
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"crypto/tls"
+	"flag"
 	"fmt"
 	"net"
 	"os"
@@ -60,23 +61,68 @@ func wrapHttps(l net.Listener, cert, key string) (net.Listener, error) {
 	return tlsListener, nil
 }
 
+// maxStoreBackoff caps the wait between attempts to connect to the meta store
+const maxStoreBackoff = 30 * time.Second
+
 func FindMetaStore() (GenericMetaStore, error) {
 	switch Config.BackingStore {
 	case "bolt":
 		m, err := NewMetaStore(Config.MetaDB)
 		return m, err
 	case "cassandra":
-		m, err := NewCassandraMetaStore(NewCassandraSession())
-		return m, err
+		return connectWithRetry("cassandra", func() (GenericMetaStore, error) {
+			m, err := NewCassandraMetaStore()
+			if err != nil {
+				return nil, err
+			}
+			return m, nil
+		})
 	case "mysql":
-		m, err := NewMySQLMetaStore(NewMySQLSession())
-		return m, err
+		return connectWithRetry("mysql", func() (GenericMetaStore, error) {
+			m, err := NewMySQLMetaStore()
+			if err != nil {
+				return nil, err
+			}
+			return m, nil
+		})
+	case "sqlite":
+		m, err := NewSQLiteMetaStore()
+		if err != nil {
+			return nil, err
+		}
+		return m, nil
+	case "postgres":
+		return connectWithRetry("postgres", func() (GenericMetaStore, error) {
+			m, err := NewPostgresMetaStore()
+			if err != nil {
+				return nil, err
+			}
+			return m, nil
+		})
 	default:
 		m, err := NewMetaStore(Config.MetaDB)
 		return m, err
 	}
 }
 
+// connectWithRetry calls connect until it succeeds, up to Config.StoreRetries more times
+// while the store is unavailable, doubling the wait from Config.StoreBackoff each time.
+// Configuration errors are returned right away.
+func connectWithRetry(name string, connect func() (GenericMetaStore, error)) (GenericMetaStore, error) {
+	backoff := Config.StoreBackoff
+	for attempt := 1; ; attempt++ {
+		store, err := connect()
+		if err == nil || !isUnavailable(err) || attempt > Config.StoreRetries {
+			return store, err
+		}
+		logger.Log(kv{"fn": "connectWithRetry", "store": name, "attempt": attempt, "retry_in": backoff.String(), "error": err.Error()})
+		time.Sleep(backoff)
+		if backoff *= 2; backoff > maxStoreBackoff {
+			backoff = maxStoreBackoff
+		}
+	}
+}
+
 func findContentStore() (GenericContentStore, error) {
 	logger.Log(kv{"fn": "findContentStore", "msg": fmt.Sprintf("Using ContentStore %s", Config.ContentStore)})
 	switch Config.ContentStore {
@@ -94,6 +140,18 @@ func main() {
 		os.Exit(0)
 	}
 
+	if len(os.Args) > 1 && os.Args[1] == "gc" {
+		os.Exit(gc(os.Args[2:]))
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		os.Exit(migrateSchema(os.Args[2:]))
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "git-lfs-authenticate" {
+		os.Exit(sshAuthenticate(os.Args[2:]))
+	}
+
 	var listener net.Listener
 	runtime.GOMAXPROCS(Config.NumProcs)
 
@@ -144,3 +202,96 @@ func main() {
 	app.Serve(listener)
 	tl.WaitForChildren()
 }
+
+// gc runs garbage collection from the command line, e.g. lfs-server-go gc -dry-run
+func gc(args []string) int {
+	flags := flag.NewFlagSet("gc", flag.ExitOnError)
+	dryRun := flags.Bool("dry-run", false, "only list the objects that would be removed")
+	grace := flags.Duration("grace", Config.GCGracePeriod, "keep unreferenced objects written within this period")
+	flags.Parse(args)
+
+	metaStore, err := FindMetaStore()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Could not open the meta store: %s\n", err)
+		return 1
+	}
+	defer metaStore.Close()
+
+	contentStore, err := findContentStore()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Could not open the content store: %s\n", err)
+		return 1
+	}
+
+	result, err := collectGarbage(metaStore, contentStore, *grace, *dryRun)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Garbage collection failed: %s\n", err)
+		return 1
+	}
+
+	verb := "removed"
+	if result.DryRun {
+		verb = "would remove"
+	}
+	for _, meta := range result.Deleted {
+		fmt.Printf("%s %s (%d bytes)\n", verb, meta.Oid, meta.Size)
+	}
+	fmt.Printf("%d objects %s, %d kept within the grace period\n", len(result.Deleted), verb, len(result.Skipped))
+	for _, e := range result.Errors {
+		fmt.Fprintln(os.Stderr, e)
+	}
+	if len(result.Errors) > 0 {
+		return 1
+	}
+	return 0
+}
+
+// migrateSchema shows or upgrades the schema of the SQL meta store from the command line,
+// e.g. lfs-server-go migrate status
+func migrateSchema(args []string) int {
+	if len(args) != 1 || (args[0] != "status" && args[0] != "up") {
+		fmt.Fprintln(os.Stderr, "usage: lfs-server-go migrate status|up")
+		return 2
+	}
+
+	db, migrations, bind, err := openSchema()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Could not open the meta store: %s\n", err)
+		return 1
+	}
+	defer db.Close()
+
+	if args[0] == "up" {
+		applied, err := migrate(db, migrations, bind)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Migration failed after %d applied: %s\n", applied, err)
+			return 1
+		}
+		fmt.Printf("%d migrations applied\n", applied)
+	}
+
+	current, err := schemaVersion(db)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Could not read the schema version: %s\n", err)
+		return 1
+	}
+	applied, err := appliedMigrations(db)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Could not read the schema version: %s\n", err)
+		return 1
+	}
+	for _, m := range migrations {
+		state := "pending"
+		if at, ok := applied[m.version]; ok {
+			state = "applied " + at
+		}
+		fmt.Printf("%3d %-50s %s\n", m.version, m.description, state)
+	}
+	latest := latestVersion(migrations)
+	fmt.Printf("schema version %d of %d (%s)\n", current, latest, Config.BackingStore)
+	if current > latest {
+		fmt.Fprintln(os.Stderr, errSchemaTooNew)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"database/sql/driver"
+	"errors"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestConnectWithRetry(t *testing.T) {
+	oldRetries, oldBackoff := Config.StoreRetries, Config.StoreBackoff
+	Config.StoreRetries, Config.StoreBackoff = 3, time.Millisecond
+	defer func() { Config.StoreRetries, Config.StoreBackoff = oldRetries, oldBackoff }()
+
+	attempts := 0
+	store, err := connectWithRetry("test", func() (GenericMetaStore, error) {
+		if attempts++; attempts < 3 {
+			return nil, newStoreError("test", "connect", errors.New("connection refused"))
+		}
+		return &MetaStore{}, nil
+	})
+	if err != nil || store == nil || attempts != 3 {
+		t.Errorf("expected to connect on the third attempt, got: %v after %d attempts", err, attempts)
+	}
+
+	attempts = 0
+	_, err = connectWithRetry("test", func() (GenericMetaStore, error) {
+		attempts++
+		return nil, newStoreError("test", "connect", errors.New("connection refused"))
+	})
+	if !isUnavailable(err) || attempts != 4 {
+		t.Errorf("expected to give up after 3 retries, got: %v after %d attempts", err, attempts)
+	}
+
+	attempts = 0
+	_, err = connectWithRetry("test", func() (GenericMetaStore, error) {
+		attempts++
+		return nil, errMissingParams
+	})
+	if err != errMissingParams || attempts != 1 {
+		t.Errorf("expected configuration errors not to be retried, got: %v after %d attempts", err, attempts)
+	}
+}
+
+func TestStoreErrorUnavailable(t *testing.T) {
+	cases := []struct {
+		err         error
+		unavailable bool
+	}{
+		{errors.New("dial tcp 127.0.0.1:3306: connection refused"), true},
+		{driver.ErrBadConn, true},
+		{&net.OpError{Op: "read", Err: errors.New("i/o timeout")}, true},
+		{errors.New("Error 1064: You have an error in your SQL syntax"), false},
+		{errors.New("Error 1062: Duplicate entry"), false},
+	}
+	for _, c := range cases {
+		if got := isUnavailable(newStoreError("test", "query", c.err)); got != c.unavailable {
+			t.Errorf("expected %q to be unavailable %t, got %t", c.err, c.unavailable, got)
+		}
+	}
+}
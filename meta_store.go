@@ -4,12 +4,10 @@ import (
 	"bytes"
 	"encoding/gob"
 	"errors"
+	"strings"
 	"time"
 
-	"encoding/base64"
-	"fmt"
 	"github.com/boltdb/bolt"
-	"strings"
 )
 
 // MetaStore implements a metadata storage. It stores user credentials and Meta information
@@ -26,6 +24,8 @@ var (
 	usersBucket    = []byte("users")
 	objectsBucket  = []byte("objects")
 	projectsBucket = []byte("projects")
+	locksBucket    = []byte("locks")
+	tokensBucket   = []byte("tokens")
 )
 
 // NewMetaStore creates a new MetaStore using the boltdb database at dbFile.
@@ -35,7 +35,7 @@ func NewMetaStore(dbFile string) (*MetaStore, error) {
 		return nil, err
 	}
 
-	db.Update(func(tx *bolt.Tx) error {
+	err = db.Update(func(tx *bolt.Tx) error {
 		if _, err := tx.CreateBucketIfNotExists(usersBucket); err != nil {
 			return err
 		}
@@ -48,33 +48,101 @@ func NewMetaStore(dbFile string) (*MetaStore, error) {
 			return err
 		}
 
-		return nil
+		if _, err := tx.CreateBucketIfNotExists(locksBucket); err != nil {
+			return err
+		}
+
+		if _, err := tx.CreateBucketIfNotExists(tokensBucket); err != nil {
+			return err
+		}
+
+		return upgradeLegacyProjects(tx)
 	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
 
 	return &MetaStore{db: db}, nil
 }
 
-// Get retrieves the Meta information for an object given information in
-// RequestVars
-func (s *MetaStore) Get(rv *RequestVars) (*MetaObject, error) {
-	if !s.authenticate(rv.Authorization) {
-		return nil, newAuthError()
+// upgradeLegacyProjects moves the projects of databases from before namespaces, keyed by
+// the repo alone, into Config.LegacyNamespace, along with the project names of their objects
+func upgradeLegacyProjects(tx *bolt.Tx) error {
+	projects := tx.Bucket(projectsBucket)
+	var legacy []*MetaProject
+	err := projects.ForEach(func(k, v []byte) error {
+		var project MetaProject
+		dec := gob.NewDecoder(bytes.NewBuffer(v))
+		if err := dec.Decode(&project); err != nil {
+			return err
+		}
+		if project.Namespace == "" {
+			legacy = append(legacy, &project)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
 	}
-
-	var meta MetaObject
-	err := s.db.View(func(tx *bolt.Tx) error {
-		bucket := tx.Bucket(objectsBucket)
-		if bucket == nil {
-			return errNoBucket
+	for _, project := range legacy {
+		if err := projects.Delete([]byte(project.Name)); err != nil {
+			return err
+		}
+		project.Namespace = Config.LegacyNamespace
+		var buf bytes.Buffer
+		if err := gob.NewEncoder(&buf).Encode(project); err != nil {
+			return err
+		}
+		if err := projects.Put([]byte(project.Key()), buf.Bytes()); err != nil {
+			return err
 		}
+		logger.Log(kv{"fn": "upgradeLegacyProjects", "project": project.Key()})
+	}
 
-		value := bucket.Get([]byte(rv.Oid))
-		if len(value) == 0 {
-			return errObjectNotFound
+	objects := tx.Bucket(objectsBucket)
+	var upgraded []*MetaObject
+	err = objects.ForEach(func(k, v []byte) error {
+		var meta MetaObject
+		dec := gob.NewDecoder(bytes.NewBuffer(v))
+		if err := dec.Decode(&meta); err != nil {
+			return err
+		}
+		changed := false
+		for i, name := range meta.ProjectNames {
+			if !strings.Contains(name, "/") {
+				meta.ProjectNames[i] = projectKey(Config.LegacyNamespace, name)
+				changed = true
+			}
+		}
+		if changed {
+			upgraded = append(upgraded, &meta)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, meta := range upgraded {
+		var buf bytes.Buffer
+		if err := gob.NewEncoder(&buf).Encode(meta); err != nil {
+			return err
 		}
+		if err := objects.Put([]byte(meta.Oid), buf.Bytes()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-		dec := gob.NewDecoder(bytes.NewBuffer(value))
-		return dec.Decode(&meta)
+// Get retrieves the Meta information for an object given information in
+// RequestVars. Objects are only found through the projects they belong to.
+func (s *MetaStore) Get(rv *RequestVars) (*MetaObject, error) {
+	var meta *MetaObject
+	err := s.db.View(func(tx *bolt.Tx) error {
+		var err error
+		meta, err = findObject(tx, rv.Oid)
+		return err
 	})
 
 	if err != nil {
@@ -82,10 +150,60 @@ func (s *MetaStore) Get(rv *RequestVars) (*MetaObject, error) {
 		return nil, err
 	}
 
+	if !inProject(meta, rv.Project()) {
+		return nil, errObjectNotFound
+	}
+
+	return meta, nil
+}
+
+// FindObject returns the object with the given oid, whatever projects it belongs to
+func (s *MetaStore) FindObject(oid string) (*MetaObject, error) {
+	var meta *MetaObject
+	err := s.db.View(func(tx *bolt.Tx) error {
+		var err error
+		meta, err = findObject(tx, oid)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return meta, nil
+}
+
+// inProject returns true if the object is a member of the project with the namespace/repo key
+func inProject(meta *MetaObject, key string) bool {
+	if key == "" {
+		return false
+	}
+	for _, name := range meta.ProjectNames {
+		if name == key {
+			return true
+		}
+	}
+	return false
+}
+
+func findObject(tx *bolt.Tx, oid string) (*MetaObject, error) {
+	bucket := tx.Bucket(objectsBucket)
+	if bucket == nil {
+		return nil, errNoBucket
+	}
+
+	value := bucket.Get([]byte(oid))
+	if len(value) == 0 {
+		return nil, errObjectNotFound
+	}
+
+	var meta MetaObject
+	dec := gob.NewDecoder(bytes.NewBuffer(value))
+	if err := dec.Decode(&meta); err != nil {
+		return nil, err
+	}
 	return &meta, nil
 }
 
-func (s *MetaStore) findProject(projectName string) (*MetaProject, error) {
+func (s *MetaStore) findProject(namespace, name string) (*MetaProject, error) {
 	// var projects []*MetaProject
 	var project *MetaProject
 	err := s.db.View(func(tx *bolt.Tx) error {
@@ -93,7 +211,7 @@ func (s *MetaStore) findProject(projectName string) (*MetaProject, error) {
 		if bucket == nil {
 			return errNoBucket
 		}
-		val := bucket.Get([]byte(projectName))
+		val := bucket.Get([]byte(projectKey(namespace, name)))
 		if len(val) < 1 {
 			return errProjectNotFound
 		}
@@ -109,84 +227,82 @@ func (s *MetaStore) findProject(projectName string) (*MetaProject, error) {
 	return nil, errProjectNotFound
 }
 
-// Currently the OIDS are nil
-func (s *MetaStore) createProject(rv *RequestVars) error {
-	if _, err := s.findProject(rv.Repo); err == nil {
-		// already there
-		return nil
+// addOidToProject records the oid in the project, creating the project if needed
+func addOidToProject(tx *bolt.Tx, namespace, name, oid string) error {
+	bucket := tx.Bucket(projectsBucket)
+	if bucket == nil {
+		// should never get here unless the db is jacked
+		return errNoBucket
 	}
 
-	if rv.Repo == "" {
-		return nil
-	}
-	err := s.db.Update(func(tx *bolt.Tx) error {
-		bucket := tx.Bucket(projectsBucket)
-		if bucket == nil {
-			// should never get here unless the db is jacked
-			return errNoBucket
-		}
-		var buf bytes.Buffer
-		enc := gob.NewEncoder(&buf)
-		meta := MetaProject{Name: rv.Repo, Oids: []string{rv.Oid}}
-		err := enc.Encode(meta)
-		// Just a bunch o keys
-		err = bucket.Put([]byte(rv.Repo), buf.Bytes())
-		if err != nil {
+	project := MetaProject{Namespace: namespace, Name: name}
+	if val := bucket.Get([]byte(project.Key())); len(val) > 0 {
+		dec := gob.NewDecoder(bytes.NewBuffer(val))
+		if err := dec.Decode(&project); err != nil {
 			return err
 		}
-
-		return nil
-	})
-	return err
-}
-
-// Put writes meta information from RequestVars to the store.
-func (s *MetaStore) Put(rv *RequestVars) (*MetaObject, error) {
-	if !s.authenticate(rv.Authorization) {
-		return nil, newAuthError()
 	}
-
-	// Check if it exists first
-	if meta, err := s.Get(rv); err == nil {
-		meta.Existing = true
-		return meta, nil
+	for _, o := range project.Oids {
+		if o == oid {
+			return nil
+		}
 	}
+	project.Oids = append(project.Oids, oid)
 
 	var buf bytes.Buffer
 	enc := gob.NewEncoder(&buf)
-	meta := MetaObject{Oid: rv.Oid, Size: rv.Size, ProjectNames: []string{rv.Repo}}
-	err := enc.Encode(meta)
-	if err != nil {
-		return nil, err
-	}
-	// create the project first, if we can
-	if rv.Repo != "" {
-		err := s.createProject(rv)
-		if err != nil {
-			logger.Log(kv{"fn": "Put", "err": err.Error()})
-			return nil, err
-		}
+	if err := enc.Encode(project); err != nil {
+		return err
 	}
+	// Just a bunch o keys
+	return bucket.Put([]byte(project.Key()), buf.Bytes())
+}
 
-	err = s.db.Update(func(tx *bolt.Tx) error {
+// Put writes meta information from RequestVars to the store. An object that
+// already exists is linked into the project in RequestVars.
+func (s *MetaStore) Put(rv *RequestVars) (*MetaObject, error) {
+	var meta *MetaObject
+	existing := false
+	err := s.db.Update(func(tx *bolt.Tx) error {
 		bucket := tx.Bucket(objectsBucket)
 		if bucket == nil {
 			return errNoBucket
 		}
 
-		err = bucket.Put([]byte(rv.Oid), buf.Bytes())
-		if err != nil {
+		var err error
+		meta, err = findObject(tx, rv.Oid)
+		switch err {
+		case nil:
+			existing = true
+		case errObjectNotFound:
+			meta = &MetaObject{Oid: rv.Oid, Size: rv.Size}
+		default:
 			return err
 		}
 
-		return nil
+		if rv.Repo != "" && !inProject(meta, rv.Project()) {
+			meta.ProjectNames = append(meta.ProjectNames, rv.Project())
+			if err := addOidToProject(tx, rv.Namespace, rv.Repo, rv.Oid); err != nil {
+				logger.Log(kv{"fn": "Put", "err": err.Error()})
+				return err
+			}
+		}
+
+		var buf bytes.Buffer
+		enc := gob.NewEncoder(&buf)
+		if err := enc.Encode(meta); err != nil {
+			return err
+		}
+
+		return bucket.Put([]byte(rv.Oid), buf.Bytes())
 	})
 
 	if err != nil {
 		return nil, err
 	}
 
-	return &meta, nil
+	meta.Existing = existing
+	return meta, nil
 }
 
 // Close closes the underlying boltdb.
@@ -227,13 +343,46 @@ func (s *MetaStore) DeleteUser(user string) error {
 			return errNoBucket
 		}
 
-		err := bucket.Delete([]byte(user))
-		return err
+		if err := bucket.Delete([]byte(user)); err != nil {
+			return err
+		}
+		return revokeTokens(tx, user)
 	})
 
 	return err
 }
 
+// revokeTokens deletes the personal access tokens of user, so they stop working with
+// the user gone
+func revokeTokens(tx *bolt.Tx, user string) error {
+	bucket := tx.Bucket(tokensBucket)
+	if bucket == nil {
+		return errNoBucket
+	}
+
+	var hashes [][]byte
+	err := bucket.ForEach(func(k, val []byte) error {
+		var token MetaToken
+		dec := gob.NewDecoder(bytes.NewBuffer(val))
+		if err := dec.Decode(&token); err != nil {
+			return err
+		}
+		if token.User == user {
+			hashes = append(hashes, k)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, hash := range hashes {
+		if err := bucket.Delete(hash); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // Users returns all MetaUsers in the meta store
 func (s *MetaStore) Users() ([]*MetaUser, error) {
 	if Config.Ldap.Enabled {
@@ -282,50 +431,23 @@ func (s *MetaStore) Objects() ([]*MetaObject, error) {
 	return objects, err
 }
 
-// authenticate uses the authorization string to determine whether
-// or not to proceed. This server assumes an HTTP Basic auth format.
-func (s *MetaStore) authenticate(authorization string) bool {
-	if Config.IsPublic() {
-		return true
-	}
-
-	if authorization == "" {
-		return false
-	}
-
-	if !strings.HasPrefix(authorization, "Basic ") {
-		return false
-	}
-	c, err := base64.URLEncoding.DecodeString(strings.TrimPrefix(authorization, "Basic "))
-	if err != nil {
-		logger.Log(kv{"fn": "meta_store.authenticate", "msg": err.Error()})
-		return false
-	}
-	cs := string(c)
-	i := strings.IndexByte(cs, ':')
-	if i < 0 {
-		return false
-	}
-	user, password := cs[:i], cs[i+1:]
-	if Config.Ldap.Enabled {
-		return authenticateLdap(user, password)
-	}
-	value := ""
-
-	s.db.View(func(tx *bolt.Tx) error {
+// CheckPassword reports whether password is the password of the local user
+func (s *MetaStore) CheckPassword(user, password string) (bool, error) {
+	var value []byte
+	err := s.db.View(func(tx *bolt.Tx) error {
 		bucket := tx.Bucket(usersBucket)
 		if bucket == nil {
 			return errNoBucket
 		}
 
-		value = string(bucket.Get([]byte(user)))
+		// bolt values are only valid during the transaction
+		value = append([]byte(nil), bucket.Get([]byte(user))...)
 		return nil
 	})
-	match, err := checkPass([]byte(value), []byte(password))
-	if err != nil {
-		logger.Log(kv{"fn": "meta_store.authenticate", "msg": fmt.Sprintf("Decrypt error: %s", err.Error())})
+	if err != nil || value == nil {
+		return false, err
 	}
-	return match
+	return checkPass(value, []byte(password))
 }
 
 func (s *MetaStore) Projects() ([]*MetaProject, error) {
@@ -351,10 +473,391 @@ func (s *MetaStore) Projects() ([]*MetaProject, error) {
 	return projects, err
 }
 
-/*
-AddProject (create a new project using POST)
-Only implemented on MySQL meta store
-*/
-func (s *MetaStore) AddProject(name string) error {
-	return errMySQLNotImplemented
+// DeleteObject removes the object and its membership in every project
+func (s *MetaStore) DeleteObject(oid string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		objects := tx.Bucket(objectsBucket)
+		if objects == nil {
+			return errNoBucket
+		}
+		meta, err := findObject(tx, oid)
+		if err != nil {
+			return err
+		}
+		for _, key := range meta.ProjectNames {
+			if err := updateProject(tx, key, func(p *MetaProject) {
+				p.Oids = without(p.Oids, oid)
+			}); err != nil {
+				return err
+			}
+		}
+		return objects.Delete([]byte(oid))
+	})
+}
+
+// DeleteProject removes the project, its locks and its membership in every object.
+// The objects themselves are left for garbage collection.
+func (s *MetaStore) DeleteProject(namespace, name string) error {
+	key := projectKey(namespace, name)
+	return s.db.Update(func(tx *bolt.Tx) error {
+		projects := tx.Bucket(projectsBucket)
+		if projects == nil {
+			return errNoBucket
+		}
+		val := projects.Get([]byte(key))
+		if len(val) == 0 {
+			return errProjectNotFound
+		}
+		var project MetaProject
+		dec := gob.NewDecoder(bytes.NewBuffer(val))
+		if err := dec.Decode(&project); err != nil {
+			return err
+		}
+
+		objects := tx.Bucket(objectsBucket)
+		for _, oid := range project.Oids {
+			meta, err := findObject(tx, oid)
+			if err == errObjectNotFound {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			meta.ProjectNames = without(meta.ProjectNames, key)
+			var buf bytes.Buffer
+			enc := gob.NewEncoder(&buf)
+			if err := enc.Encode(meta); err != nil {
+				return err
+			}
+			if err := objects.Put([]byte(oid), buf.Bytes()); err != nil {
+				return err
+			}
+		}
+
+		if locks := tx.Bucket(locksBucket); locks != nil && locks.Bucket([]byte(key)) != nil {
+			if err := locks.DeleteBucket([]byte(key)); err != nil {
+				return err
+			}
+		}
+		return projects.Delete([]byte(key))
+	})
+}
+
+// updateProject applies fn to the stored project with the namespace/repo key, if it exists
+func updateProject(tx *bolt.Tx, key string, fn func(*MetaProject)) error {
+	bucket := tx.Bucket(projectsBucket)
+	if bucket == nil {
+		return errNoBucket
+	}
+	val := bucket.Get([]byte(key))
+	if len(val) == 0 {
+		return nil
+	}
+	var project MetaProject
+	dec := gob.NewDecoder(bytes.NewBuffer(val))
+	if err := dec.Decode(&project); err != nil {
+		return err
+	}
+	fn(&project)
+	var buf bytes.Buffer
+	enc := gob.NewEncoder(&buf)
+	if err := enc.Encode(project); err != nil {
+		return err
+	}
+	return bucket.Put([]byte(key), buf.Bytes())
+}
+
+// without returns list with every occurrence of item removed
+func without(list []string, item string) []string {
+	var out []string
+	for _, l := range list {
+		if l != item {
+			out = append(out, l)
+		}
+	}
+	return out
+}
+
+// AddProject creates an empty project, failing with errProjectExists when it is already there
+func (s *MetaStore) AddProject(namespace, name string) error {
+	project := MetaProject{Namespace: namespace, Name: name}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(projectsBucket)
+		if bucket == nil {
+			return errNoBucket
+		}
+		if len(bucket.Get([]byte(project.Key()))) > 0 {
+			return errProjectExists
+		}
+
+		var buf bytes.Buffer
+		enc := gob.NewEncoder(&buf)
+		if err := enc.Encode(project); err != nil {
+			return err
+		}
+		return bucket.Put([]byte(project.Key()), buf.Bytes())
+	})
+}
+
+// RenameProject moves the project, the membership of its objects and its locks to
+// newNamespace/newName
+func (s *MetaStore) RenameProject(namespace, name, newNamespace, newName string) error {
+	oldKey, newKey := projectKey(namespace, name), projectKey(newNamespace, newName)
+	return s.db.Update(func(tx *bolt.Tx) error {
+		projects := tx.Bucket(projectsBucket)
+		if projects == nil {
+			return errNoBucket
+		}
+		val := projects.Get([]byte(oldKey))
+		if len(val) == 0 {
+			return errProjectNotFound
+		}
+		if len(projects.Get([]byte(newKey))) > 0 {
+			return errProjectExists
+		}
+		var project MetaProject
+		dec := gob.NewDecoder(bytes.NewBuffer(val))
+		if err := dec.Decode(&project); err != nil {
+			return err
+		}
+
+		objects := tx.Bucket(objectsBucket)
+		for _, oid := range project.Oids {
+			meta, err := findObject(tx, oid)
+			if err == errObjectNotFound {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			meta.ProjectNames = append(without(meta.ProjectNames, oldKey), newKey)
+			var buf bytes.Buffer
+			enc := gob.NewEncoder(&buf)
+			if err := enc.Encode(meta); err != nil {
+				return err
+			}
+			if err := objects.Put([]byte(oid), buf.Bytes()); err != nil {
+				return err
+			}
+		}
+
+		if err := renameLocks(tx, oldKey, newKey); err != nil {
+			return err
+		}
+
+		project.Namespace, project.Name = newNamespace, newName
+		var buf bytes.Buffer
+		enc := gob.NewEncoder(&buf)
+		if err := enc.Encode(project); err != nil {
+			return err
+		}
+		if err := projects.Put([]byte(newKey), buf.Bytes()); err != nil {
+			return err
+		}
+		return projects.Delete([]byte(oldKey))
+	})
+}
+
+// renameLocks moves the bucket of locks of the project oldKey to newKey
+func renameLocks(tx *bolt.Tx, oldKey, newKey string) error {
+	locks := tx.Bucket(locksBucket)
+	if locks == nil || locks.Bucket([]byte(oldKey)) == nil {
+		return nil
+	}
+	moved, err := locks.CreateBucketIfNotExists([]byte(newKey))
+	if err != nil {
+		return err
+	}
+	err = locks.Bucket([]byte(oldKey)).ForEach(func(k, val []byte) error {
+		var lock MetaLock
+		dec := gob.NewDecoder(bytes.NewBuffer(val))
+		if err := dec.Decode(&lock); err != nil {
+			return err
+		}
+		lock.Project = newKey
+		var buf bytes.Buffer
+		enc := gob.NewEncoder(&buf)
+		if err := enc.Encode(lock); err != nil {
+			return err
+		}
+		return moved.Put(k, buf.Bytes())
+	})
+	if err != nil {
+		return err
+	}
+	return locks.DeleteBucket([]byte(oldKey))
+}
+
+// AddLock stores a lock for the project in RequestVars. Each project has its own
+// bucket of locks inside the locks bucket, keyed by lock id.
+func (s *MetaStore) AddLock(v *RequestVars, lock *MetaLock) error {
+	lock.Project = v.Project()
+	return s.db.Update(func(tx *bolt.Tx) error {
+		locks := tx.Bucket(locksBucket)
+		if locks == nil {
+			return errNoBucket
+		}
+		bucket, err := locks.CreateBucketIfNotExists([]byte(v.Project()))
+		if err != nil {
+			return err
+		}
+
+		err = bucket.ForEach(func(k, val []byte) error {
+			var existing MetaLock
+			dec := gob.NewDecoder(bytes.NewBuffer(val))
+			if err := dec.Decode(&existing); err != nil {
+				return err
+			}
+			if existing.Path == lock.Path {
+				return errLockExists
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		var buf bytes.Buffer
+		enc := gob.NewEncoder(&buf)
+		if err := enc.Encode(lock); err != nil {
+			return err
+		}
+		return bucket.Put([]byte(lock.Id), buf.Bytes())
+	})
+}
+
+// Locks returns all locks for the project in RequestVars
+func (s *MetaStore) Locks(v *RequestVars) ([]*MetaLock, error) {
+	var locks []*MetaLock
+	err := s.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(locksBucket)
+		if bucket == nil {
+			return errNoBucket
+		}
+
+		project := bucket.Bucket([]byte(v.Project()))
+		if project == nil {
+			return nil
+		}
+
+		return project.ForEach(func(k, val []byte) error {
+			var lock MetaLock
+			dec := gob.NewDecoder(bytes.NewBuffer(val))
+			if err := dec.Decode(&lock); err != nil {
+				return err
+			}
+			locks = append(locks, &lock)
+			return nil
+		})
+	})
+	return locks, err
+}
+
+// DeleteLock removes the lock with the given id from the project in RequestVars
+func (s *MetaStore) DeleteLock(v *RequestVars, id string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(locksBucket)
+		if bucket == nil {
+			return errNoBucket
+		}
+
+		project := bucket.Bucket([]byte(v.Project()))
+		if project == nil || project.Get([]byte(id)) == nil {
+			return errLockNotFound
+		}
+		return project.Delete([]byte(id))
+	})
+}
+
+// AddToken stores a personal access token, keyed by its hash
+func (s *MetaStore) AddToken(token *MetaToken) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(tokensBucket)
+		if bucket == nil {
+			return errNoBucket
+		}
+
+		var buf bytes.Buffer
+		enc := gob.NewEncoder(&buf)
+		if err := enc.Encode(token); err != nil {
+			return err
+		}
+		return bucket.Put([]byte(token.Hash), buf.Bytes())
+	})
+}
+
+// Tokens returns the personal access tokens of user, or of all users when user is empty
+func (s *MetaStore) Tokens(user string) ([]*MetaToken, error) {
+	var tokens []*MetaToken
+	err := s.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(tokensBucket)
+		if bucket == nil {
+			return errNoBucket
+		}
+
+		return bucket.ForEach(func(k, val []byte) error {
+			var token MetaToken
+			dec := gob.NewDecoder(bytes.NewBuffer(val))
+			if err := dec.Decode(&token); err != nil {
+				return err
+			}
+			if user == "" || token.User == user {
+				tokens = append(tokens, &token)
+			}
+			return nil
+		})
+	})
+	return tokens, err
+}
+
+// FindToken returns the personal access token with the given hash
+func (s *MetaStore) FindToken(hash string) (*MetaToken, error) {
+	var token MetaToken
+	err := s.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(tokensBucket)
+		if bucket == nil {
+			return errNoBucket
+		}
+
+		val := bucket.Get([]byte(hash))
+		if val == nil {
+			return errTokenNotFound
+		}
+		dec := gob.NewDecoder(bytes.NewBuffer(val))
+		return dec.Decode(&token)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// DeleteToken revokes the personal access token with the given id
+func (s *MetaStore) DeleteToken(id string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(tokensBucket)
+		if bucket == nil {
+			return errNoBucket
+		}
+
+		var hash []byte
+		err := bucket.ForEach(func(k, val []byte) error {
+			var token MetaToken
+			dec := gob.NewDecoder(bytes.NewBuffer(val))
+			if err := dec.Decode(&token); err != nil {
+				return err
+			}
+			if token.Id == id {
+				hash = k
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if hash == nil {
+			return errTokenNotFound
+		}
+		return bucket.Delete(hash)
+	})
 }
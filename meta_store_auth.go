@@ -1,70 +1,211 @@
 package main
 
 import (
+	"bytes"
 	"crypto/tls"
+	"crypto/x509"
 	"errors"
 	"fmt"
 	"github.com/nmcclain/ldap"
+	"io/ioutil"
+	"net"
 	"net/url"
-	"strconv"
-	"strings"
+	"sync"
+	"time"
 )
 
 func ldapHost() (*url.URL, error) {
 	return url.Parse(Config.Ldap.Server)
 }
 
+// ldapTLSConfig verifies the server against Config.Ldap.CACert when set, otherwise
+// against the system roots
+func ldapTLSConfig(host string) (*tls.Config, error) {
+	cfg := &tls.Config{ServerName: host, InsecureSkipVerify: Config.Ldap.SkipVerify}
+	if Config.Ldap.CACert == "" {
+		return cfg, nil
+	}
+	pem, err := ioutil.ReadFile(Config.Ldap.CACert)
+	if err != nil {
+		return nil, err
+	}
+	cfg.RootCAs = x509.NewCertPool()
+	if !cfg.RootCAs.AppendCertsFromPEM(pem) {
+		return nil, errLdapCACert
+	}
+	return cfg, nil
+}
+
+// NewLdapConnection dials the LDAP server, upgrades to TLS with StartTLS when
+// configured and binds the service account, so searches work on directories that
+// forbid anonymous search
 func NewLdapConnection() (*ldap.Conn, error) {
-	var err error
 	lh, err := ldapHost()
 	if err != nil {
 		logger.Log(kv{"fn": "NewLdapConnection", "error": err.Error()})
+		return nil, err
 	}
-	hoster := strings.Split(lh.Host, ":")
-	port := func() uint16 {
-		if len(hoster) < 2 {
-			return uint16(389)
-		} else {
-			var e error
-			port, e := strconv.Atoi(hoster[1])
-			if e != nil {
-				panic(e)
-			}
-			return uint16(port)
+	host, port, err := net.SplitHostPort(lh.Host)
+	if err != nil {
+		host, port = lh.Host, "389"
+		if lh.Scheme == "ldaps" {
+			port = "636"
 		}
 	}
+	addr := net.JoinHostPort(host, port)
+
 	var ldapCon *ldap.Conn
-	if strings.Contains(lh.String(), "ldaps") {
-		ldapCon, err = ldap.DialTLS("tcp", fmt.Sprintf("%s:%d", hoster[0], port()), &tls.Config{InsecureSkipVerify: true})
+	if lh.Scheme == "ldaps" || Config.Ldap.StartTLS {
+		tlsConfig, err := ldapTLSConfig(host)
+		if err != nil {
+			logger.Log(kv{"fn": "NewLdapConnection", "error": err.Error()})
+			return nil, err
+		}
+		if lh.Scheme == "ldaps" {
+			ldapCon, err = dialLdapTLS(addr, tlsConfig)
+		} else {
+			ldapCon, err = ldap.DialTimeout("tcp", addr, Config.Ldap.Timeout)
+			if err == nil {
+				err = ldapTimeout(ldapCon, func() error { return ldapCon.StartTLS(tlsConfig) })
+			}
+		}
 	} else {
-		ldapCon, err = ldap.Dial("tcp", fmt.Sprintf("%s:%d", hoster[0], port()))
+		ldapCon, err = ldap.DialTimeout("tcp", addr, Config.Ldap.Timeout)
+	}
+	if err == nil {
+		err = bindServiceAccount(ldapCon)
 	}
 	if err != nil {
+		if ldapCon != nil {
+			ldapCon.Close()
+		}
 		logger.Log(kv{"fn": "NewLdapConnection", "error": err.Error()})
 		return nil, err
 	}
 	return ldapCon, nil
 }
 
+// dialLdapTLS is ldap.DialTLS giving up after Config.Ldap.Timeout
+func dialLdapTLS(addr string, tlsConfig *tls.Config) (*ldap.Conn, error) {
+	type dialed struct {
+		conn *ldap.Conn
+		err  error
+	}
+	done := make(chan dialed, 1)
+	go func() {
+		ldapCon, err := ldap.DialTLS("tcp", addr, tlsConfig)
+		done <- dialed{ldapCon, err}
+	}()
+	if Config.Ldap.Timeout <= 0 {
+		d := <-done
+		return d.conn, d.err
+	}
+	select {
+	case d := <-done:
+		return d.conn, d.err
+	case <-time.After(Config.Ldap.Timeout):
+		// close the connection should it still come up
+		go func() {
+			if d := <-done; d.conn != nil {
+				d.conn.Close()
+			}
+		}()
+		return nil, errLdapTimeout
+	}
+}
+
+// bindServiceAccount binds Config.Ldap.BindDn, when a service account is configured
+func bindServiceAccount(ldapCon *ldap.Conn) error {
+	if (len(Config.Ldap.BindDn) + len(Config.Ldap.BindPass)) == 0 {
+		return nil
+	}
+	return ldapTimeout(ldapCon, func() error { return ldapCon.Bind(Config.Ldap.BindDn, Config.Ldap.BindPass) })
+}
+
+// ldapTimeout runs op, giving up after Config.Ldap.Timeout. The connection is closed
+// on timeout, so it can't be reused half way through a request.
+func ldapTimeout(ldapCon *ldap.Conn, op func() error) error {
+	if Config.Ldap.Timeout <= 0 {
+		return op()
+	}
+	done := make(chan error, 1)
+	go func() { done <- op() }()
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(Config.Ldap.Timeout):
+		if ldapCon != nil {
+			ldapCon.Close()
+		}
+		return errLdapTimeout
+	}
+}
+
+// ldapPool keeps up to Config.Ldap.PoolSize idle connections bound as the service account
+type ldapPool struct {
+	mu     sync.Mutex
+	config *LdapConfig
+	idle   []*ldap.Conn
+}
+
+var ldapConns = &ldapPool{}
+
+// get returns an idle connection, or dials a new one
+func (p *ldapPool) get() (*ldap.Conn, error) {
+	p.mu.Lock()
+	if p.config != Config.Ldap {
+		// the configuration was replaced, connections to the old server are of no use
+		p.closeIdle()
+		p.config = Config.Ldap
+	}
+	if n := len(p.idle); n > 0 {
+		ldapCon := p.idle[n-1]
+		p.idle = p.idle[:n-1]
+		p.mu.Unlock()
+		return ldapCon, nil
+	}
+	p.mu.Unlock()
+	return NewLdapConnection()
+}
+
+// put returns a connection bound as the service account to the pool
+func (p *ldapPool) put(ldapCon *ldap.Conn) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.config != Config.Ldap || len(p.idle) >= Config.Ldap.PoolSize {
+		ldapCon.Close()
+		return
+	}
+	p.idle = append(p.idle, ldapCon)
+}
+
+func (p *ldapPool) closeIdle() {
+	for _, ldapCon := range p.idle {
+		ldapCon.Close()
+	}
+	p.idle = nil
+}
+
 func LdapSearch(search *ldap.SearchRequest) (*ldap.SearchResult, error) {
-	ldapCon, err := NewLdapConnection()
+	ldapCon, err := ldapConns.get()
 	if err != nil {
 		logger.Log(kv{"fn": "LdapSearch", "search error": err.Error()})
 		return nil, err
 	}
-	s, err := ldapCon.Search(search)
-	defer ldapCon.Close()
+	if Config.Ldap.Timeout > 0 && search.TimeLimit == 0 {
+		search.TimeLimit = int(Config.Ldap.Timeout.Seconds())
+	}
+	var s *ldap.SearchResult
+	err = ldapTimeout(ldapCon, func() (err error) {
+		s, err = ldapCon.Search(search)
+		return err
+	})
 	if err != nil {
+		ldapCon.Close()
 		logger.Log(kv{"fn": "meta_store_auth.LdapSearch", "error": err.Error()})
 		return nil, err
 	}
-	if (len(Config.Ldap.BindDn) + len(Config.Ldap.BindPass)) > 0 {
-		err = ldapCon.Bind(Config.Ldap.BindDn, Config.Ldap.BindPass)
-		if err != nil {
-			logger.Log(kv{"fn": "LdapSearch", "Bind error": err.Error()})
-			return nil, err
-		}
-	}
+	ldapConns.put(ldapCon)
 	if len(s.Entries) == 0 {
 		return nil, errNoLdapSearchResults
 	}
@@ -73,36 +214,54 @@ func LdapSearch(search *ldap.SearchRequest) (*ldap.SearchResult, error) {
 
 // boolean bind request
 func LdapBind(user string, password string) bool {
-	ldapCon, err := NewLdapConnection()
+	ok, err := ldapBind(user, password)
 	if err != nil {
 		logger.Log(kv{"fn": "LdapBind", "error": err.Error()})
-		return false
 	}
-	reqE := ldapCon.Bind(user, password)
-	defer ldapCon.Close()
-	resp := false
-	if reqE == nil {
-		resp = true
+	return ok
+}
+
+// ldapBind binds as user on a pooled connection. The error is only set when the
+// server could not be reached, a wrong password just returns false.
+func ldapBind(user, password string) (bool, error) {
+	// an empty password would be an anonymous bind, which LDAP servers accept
+	if password == "" {
+		return false, nil
+	}
+	ldapCon, err := ldapConns.get()
+	if err != nil {
+		return false, err
+	}
+	bindErr := ldapTimeout(ldapCon, func() error { return ldapCon.Bind(user, password) })
+	if bindErr == errLdapTimeout {
+		return false, bindErr
 	}
-	return resp
+
+	// the connection is bound as the user now, it goes back to the pool as the service account
+	if (len(Config.Ldap.BindDn)+len(Config.Ldap.BindPass)) > 0 && bindServiceAccount(ldapCon) == nil {
+		ldapConns.put(ldapCon)
+	} else {
+		ldapCon.Close()
+	}
+	return bindErr == nil, nil
 }
 
-// authenticate uses the authorization string to determine whether
-// or not to proceed. This server assumes an HTTP Basic auth format.
-func authenticateLdap(user, password string) bool {
+// authenticateLdap looks the user up with the service account, then binds as it
+// to check the password
+func authenticateLdap(user, password string) (bool, error) {
 	dn, err := findUserDn(user)
+	if err == errLdapUserNotFound || err == errNoLdapSearchResults {
+		return false, nil
+	}
 	if err != nil {
 		logger.Log(kv{"fn": "meta_store_auth", "error": err.Error()})
-		return false
+		return false, err
 	}
-	return LdapBind(dn, password)
+	return ldapBind(dn, password)
 }
 
 func findUserDn(user string) (string, error) {
-	//	fmt.Printf("Looking for user '%s'\n", user)
-	fltr := fmt.Sprintf("(&(objectclass=%s)(%s=%s))", Config.Ldap.UserObjectClass, Config.Ldap.UserCn, user)
-	//	m := fmt.Sprintf("LDAP Search \"ldapsearch -x -H '%s' -b '%s' '%s'\"\n", Config.Ldap.Server, Config.Ldap.Base, fltr)
-	//	logger.Log(kv{"fn": "meta_store_auth.findUserDn", "msg": m})
+	fltr := fmt.Sprintf("(&(objectclass=%s)(%s=%s))", Config.Ldap.UserObjectClass, Config.Ldap.UserCn, ldapEscape(user))
 	search := &ldap.SearchRequest{
 		BaseDN:     Config.Ldap.Base,
 		Filter:     fltr,
@@ -120,6 +279,21 @@ func findUserDn(user string) (string, error) {
 	return "", errLdapUserNotFound
 }
 
+// ldapEscape escapes the special characters of a filter value (RFC 4515), so user
+// names can't change the search
+func ldapEscape(s string) string {
+	var b bytes.Buffer
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '\\', '*', '(', ')', 0:
+			fmt.Fprintf(&b, "\\%02x", c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
 type authError struct {
 	error
 }
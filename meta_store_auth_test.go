@@ -1,12 +1,18 @@
 package main
 
 import (
+	"encoding/pem"
 	"fmt"
 	"github.com/nmcclain/ldap"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"os/exec"
 	"strings"
 	"testing"
+	"time"
 )
 
 var ()
@@ -73,16 +79,120 @@ func TestLdapSearch(t *testing.T) {
 	}
 }
 
+func TestLdapPoolReusesConnections(t *testing.T) {
+	setupMetaAuth()
+	defer tearDownMetaAuth()
+
+	for i := 0; i < 3; i++ {
+		if ok, err := authenticateLdap(testUser, testPass); !ok || err != nil {
+			t.Fatalf("expected %s to authenticate, got: %t %v", testUser, ok, err)
+		}
+	}
+	ldapConns.mu.Lock()
+	idle := len(ldapConns.idle)
+	ldapConns.mu.Unlock()
+	if idle != 1 {
+		t.Errorf("expected the search and bind to share one pooled connection, got %d", idle)
+	}
+
+	if ok, _ := authenticateLdap(testUser, "badpass"); ok {
+		t.Errorf("expected a bad password to be rejected")
+	}
+	if ok, err := authenticateLdap(testUser, ""); ok || err != nil {
+		t.Errorf("expected an empty password to be rejected, got: %t %v", ok, err)
+	}
+}
+
+func TestLdapSearchBindsServiceAccount(t *testing.T) {
+	setupMetaAuth()
+	defer tearDownMetaAuth()
+
+	if _, err := findUserDn(testUser); err != nil {
+		t.Errorf("expected the service account to be allowed to search, got: %s", err)
+	}
+
+	// the test server refuses anonymous searches
+	Config.Ldap = &LdapConfig{Enabled: true, Server: "ldap://localhost:1389", Base: "o=company",
+		UserObjectClass: "posixaccount", UserCn: "uid", Timeout: 5 * time.Second}
+	if _, err := findUserDn(testUser); err == nil {
+		t.Errorf("expected an anonymous search to fail")
+	}
+}
+
+func TestLdapTimeout(t *testing.T) {
+	// a server that accepts connections but never answers
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	old := Config.Ldap
+	defer func() { Config.Ldap = old }()
+	Config.Ldap = &LdapConfig{Enabled: true, Server: "ldap://" + l.Addr().String(), BindDn: "cn=admin", BindPass: "admin",
+		Timeout: 100 * time.Millisecond}
+
+	start := time.Now()
+	if _, err := NewLdapConnection(); err != errLdapTimeout {
+		t.Errorf("expected errLdapTimeout, got: %v", err)
+	}
+	if time.Since(start) > 2*time.Second {
+		t.Errorf("expected the bind to give up after the timeout, took %s", time.Since(start))
+	}
+}
+
+func TestLdapTLSConfig(t *testing.T) {
+	old := Config.Ldap
+	defer func() { Config.Ldap = old }()
+
+	srv := httptest.NewTLSServer(http.NotFoundHandler())
+	defer srv.Close()
+	bundle, _ := ioutil.TempFile("", "ldap-ca")
+	defer os.Remove(bundle.Name())
+	pem.Encode(bundle, &pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	bundle.Close()
+
+	Config.Ldap = &LdapConfig{CACert: bundle.Name()}
+	cfg, err := ldapTLSConfig("ldap.example.com")
+	if err != nil {
+		t.Fatalf("expected a tls config, got: %s", err)
+	}
+	if cfg.RootCAs == nil || cfg.InsecureSkipVerify || cfg.ServerName != "ldap.example.com" {
+		t.Errorf("expected the server to be verified against the CA bundle, got: %+v", cfg)
+	}
+
+	ioutil.WriteFile(bundle.Name(), []byte("not a certificate"), 0644)
+	if _, err := ldapTLSConfig("ldap.example.com"); err != errLdapCACert {
+		t.Errorf("expected errLdapCACert, got: %v", err)
+	}
+}
+
+func TestLdapEscape(t *testing.T) {
+	if got := ldapEscape("admin)(uid=*"); got != `admin\29\28uid=\2a` {
+		t.Errorf("expected the filter characters to be escaped, got %s", got)
+	}
+}
+
 func tearDownMetaAuth() error {
 	// Set back to defaults
 	Config.Ldap = &LdapConfig{Enabled: false, Server: "ldap://localhost:1389", Base: "dc=testers,c=test,o=company",
-		UserObjectClass: "objectclass=person", UserCn: "uid"}
+		UserObjectClass: "objectclass=person", UserCn: "uid", Timeout: 10 * time.Second, PoolSize: 4}
 	exec.Command("pkill test_ldap_server").Run()
 	return nil
 }
 func setupMetaAuth() error {
 	Config.Ldap = &LdapConfig{Enabled: true, Server: "ldap://localhost:1389", Base: "o=company",
-		UserObjectClass: "posixaccount", UserCn: "uid", BindPass: "admin"}
+		UserObjectClass: "posixaccount", UserCn: "uid", BindDn: "cn=admin,o=testers,o=company", BindPass: "admin",
+		Timeout: 5 * time.Second, PoolSize: 2}
 	rme := exec.Command("test_ldap_server/test_ldap_server")
 	wd, _ := os.Getwd()
 	rme.Dir = wd
@@ -1,9 +1,14 @@
 package main
 
 import (
+	"bytes"
+	"encoding/gob"
 	"fmt"
 	"os"
 	"testing"
+	"time"
+
+	"github.com/boltdb/bolt"
 )
 
 var (
@@ -14,7 +19,7 @@ func TestGetWithAuth(t *testing.T) {
 	setupMeta()
 	defer teardownMeta()
 
-	meta, err := metaStoreTest.Get(&RequestVars{Authorization: testAuth, Oid: contentOid})
+	meta, err := metaStoreTest.Get(&RequestVars{Authorization: testAuth, Oid: contentOid, Namespace: testNamespace, Repo: testRepo})
 	if err != nil {
 		t.Fatalf("Error retreiving meta: %s", err)
 	}
@@ -28,13 +33,20 @@ func TestGetWithAuth(t *testing.T) {
 	}
 }
 
-func TestGetWithoutAuth(t *testing.T) {
+func TestCheckPassword(t *testing.T) {
 	setupMeta()
 	defer teardownMeta()
 
-	_, err := metaStoreTest.Get(&RequestVars{Authorization: badAuth, Oid: contentOid})
-	if !isAuthError(err) {
-		t.Errorf("expected auth error, got: %s", err)
+	if ok, err := metaStoreTest.CheckPassword(testUser, testPass); !ok || err != nil {
+		t.Errorf("expected the password to match, got: %t %v", ok, err)
+	}
+
+	if ok, _ := metaStoreTest.CheckPassword(testUser, "wrong"); ok {
+		t.Errorf("expected a wrong password not to match")
+	}
+
+	if ok, err := metaStoreTest.CheckPassword("nobody", testPass); ok || err != nil {
+		t.Errorf("expected an unknown user not to match, got: %t %v", ok, err)
 	}
 }
 
@@ -42,7 +54,7 @@ func TestPutWithAuth(t *testing.T) {
 	setupMeta()
 	defer teardownMeta()
 
-	meta, err := metaStoreTest.Put(&RequestVars{Authorization: testAuth, Oid: nonexistingOid, Size: 42})
+	meta, err := metaStoreTest.Put(&RequestVars{Authorization: testAuth, Oid: nonexistingOid, Size: 42, Namespace: testNamespace, Repo: testRepo})
 	if err != nil {
 		t.Errorf("expected put to succeed, got : %s", err)
 	}
@@ -51,7 +63,7 @@ func TestPutWithAuth(t *testing.T) {
 		t.Errorf("expected meta to not have existed")
 	}
 
-	meta, err = metaStoreTest.Get(&RequestVars{Authorization: testAuth, Oid: nonexistingOid})
+	meta, err = metaStoreTest.Get(&RequestVars{Authorization: testAuth, Oid: nonexistingOid, Namespace: testNamespace, Repo: testRepo})
 	if err != nil {
 		t.Errorf("expected to be able to retreive new put, got : %s", err)
 	}
@@ -64,7 +76,7 @@ func TestPutWithAuth(t *testing.T) {
 		t.Errorf("expected sizes to match, got: %d", meta.Size)
 	}
 
-	meta, err = metaStoreTest.Put(&RequestVars{Authorization: testAuth, Oid: nonexistingOid, Size: 42})
+	meta, err = metaStoreTest.Put(&RequestVars{Authorization: testAuth, Oid: nonexistingOid, Size: 42, Namespace: testNamespace, Repo: testRepo})
 	if err != nil {
 		t.Errorf("expected put to succeed, got : %s", err)
 	}
@@ -74,13 +86,341 @@ func TestPutWithAuth(t *testing.T) {
 	}
 }
 
-func TestPuthWithoutAuth(t *testing.T) {
+func TestGetMetaFromOtherProject(t *testing.T) {
+	setupMeta()
+	defer teardownMeta()
+
+	_, err := metaStoreTest.Get(&RequestVars{Authorization: testAuth, Oid: contentOid, Namespace: testNamespace, Repo: extraRepo})
+	if err != errObjectNotFound {
+		t.Errorf("expected object not found in %s, got: %v", extraRepo, err)
+	}
+
+	meta, err := metaStoreTest.Put(&RequestVars{Authorization: testAuth, Oid: contentOid, Size: contentSize, Namespace: testNamespace, Repo: extraRepo})
+	if err != nil {
+		t.Fatalf("expected put to succeed, got : %s", err)
+	}
+
+	if !meta.Existing {
+		t.Errorf("expected meta to exist")
+	}
+
+	if _, err := metaStoreTest.Get(&RequestVars{Authorization: testAuth, Oid: contentOid, Namespace: testNamespace, Repo: extraRepo}); err != nil {
+		t.Errorf("expected object to be linked into %s, got: %s", extraRepo, err)
+	}
+
+	project, err := metaStoreTest.findProject(testNamespace, extraRepo)
+	if err != nil || len(project.Oids) != 1 || project.Oids[0] != contentOid {
+		t.Errorf("expected %s to hold the content oid, got: %+v %v", extraRepo, project, err)
+	}
+}
+
+func TestProjectsKeyedByNamespace(t *testing.T) {
 	setupMeta()
 	defer teardownMeta()
 
-	_, err := metaStoreTest.Put(&RequestVars{Authorization: badAuth, Oid: contentOid, Size: 42})
-	if !isAuthError(err) {
-		t.Errorf("expected auth error, got: %s", err)
+	_, err := metaStoreTest.Get(&RequestVars{Authorization: testAuth, Oid: contentOid, Namespace: "other", Repo: testRepo})
+	if err != errObjectNotFound {
+		t.Errorf("expected object not found in other/%s, got: %v", testRepo, err)
+	}
+
+	if _, err := metaStoreTest.Put(&RequestVars{Authorization: testAuth, Oid: nonexistingOid, Size: 42, Namespace: "other", Repo: testRepo}); err != nil {
+		t.Fatalf("expected put to succeed, got : %s", err)
+	}
+
+	projects, err := metaStoreTest.Projects()
+	if err != nil || len(projects) != 2 {
+		t.Fatalf("expected 2 projects, got: %d %v", len(projects), err)
+	}
+
+	for _, p := range projects {
+		if len(p.Oids) != 1 {
+			t.Errorf("expected %s to hold 1 oid, got: %v", p.Key(), p.Oids)
+		}
+	}
+}
+
+func TestDeleteProject(t *testing.T) {
+	setupMeta()
+	defer teardownMeta()
+
+	if err := metaStoreTest.DeleteProject(testNamespace, testRepo); err != nil {
+		t.Fatalf("expected delete to succeed, got: %s", err)
+	}
+
+	if _, err := metaStoreTest.findProject(testNamespace, testRepo); err != errProjectNotFound {
+		t.Errorf("expected project to be removed, got: %v", err)
+	}
+
+	if _, err := metaStoreTest.Get(&RequestVars{Authorization: testAuth, Oid: contentOid, Namespace: testNamespace, Repo: testRepo}); err != errObjectNotFound {
+		t.Errorf("expected object to leave the project, got: %v", err)
+	}
+
+	if err := metaStoreTest.DeleteProject(testNamespace, testRepo); err != errProjectNotFound {
+		t.Errorf("expected errProjectNotFound, got: %v", err)
+	}
+}
+
+func TestDeleteObject(t *testing.T) {
+	setupMeta()
+	defer teardownMeta()
+
+	if err := metaStoreTest.DeleteObject(contentOid); err != nil {
+		t.Fatalf("expected delete to succeed, got: %s", err)
+	}
+
+	if objects, _ := metaStoreTest.Objects(); len(objects) != 0 {
+		t.Errorf("expected no objects, got: %d", len(objects))
+	}
+
+	project, err := metaStoreTest.findProject(testNamespace, testRepo)
+	if err != nil || len(project.Oids) != 0 {
+		t.Errorf("expected project to no longer hold the oid, got: %+v %v", project, err)
+	}
+}
+
+func TestLocksWithAuth(t *testing.T) {
+	setupMeta()
+	defer teardownMeta()
+
+	rv := &RequestVars{Authorization: testAuth, Namespace: testNamespace, Repo: testRepo}
+	lock := &MetaLock{Id: "lock1", Path: "a/b.bin", Owner: testUser}
+	if err := metaStoreTest.AddLock(rv, lock); err != nil {
+		t.Fatalf("expected add lock to succeed, got: %s", err)
+	}
+
+	if err := metaStoreTest.AddLock(rv, &MetaLock{Id: "lock2", Path: "a/b.bin", Owner: testUser}); err != errLockExists {
+		t.Errorf("expected errLockExists, got: %v", err)
+	}
+
+	locks, err := metaStoreTest.Locks(rv)
+	if err != nil {
+		t.Fatalf("expected locks to succeed, got: %s", err)
+	}
+	if len(locks) != 1 || locks[0].Id != "lock1" || locks[0].Project != projectKey(testNamespace, testRepo) {
+		t.Errorf("expected lock1 in %s, got: %+v", testRepo, locks)
+	}
+
+	if locks, _ := metaStoreTest.Locks(&RequestVars{Authorization: testAuth, Namespace: testNamespace, Repo: extraRepo}); len(locks) != 0 {
+		t.Errorf("expected no locks in %s, got: %d", extraRepo, len(locks))
+	}
+
+	if err := metaStoreTest.DeleteLock(rv, "lock1"); err != nil {
+		t.Errorf("expected delete lock to succeed, got: %s", err)
+	}
+
+	if err := metaStoreTest.DeleteLock(rv, "lock1"); err != errLockNotFound {
+		t.Errorf("expected errLockNotFound, got: %v", err)
+	}
+}
+
+func TestTokens(t *testing.T) {
+	setupMeta()
+	defer teardownMeta()
+
+	testTokenStore(t, metaStoreTest)
+}
+
+// testTokenStore checks the personal access token methods of a meta store
+func testTokenStore(t *testing.T, store GenericMetaStore) {
+	token, secret, err := newAccessToken(testUser, "ci", "write", []string{projectKey(testNamespace, testRepo)})
+	if err != nil {
+		t.Fatalf("expected a new token, got: %s", err)
+	}
+	if err := store.AddToken(token); err != nil {
+		t.Fatalf("expected add token to succeed, got: %s", err)
+	}
+
+	found, err := store.FindToken(hashAccessToken(secret))
+	if err != nil {
+		t.Fatalf("expected find token to succeed, got: %s", err)
+	}
+	if found.Id != token.Id || found.User != testUser || found.Scope != "write" || len(found.Projects) != 1 {
+		t.Errorf("expected token to round trip, got: %+v", found)
+	}
+
+	if tokens, _ := store.Tokens(testUser); len(tokens) != 1 || tokens[0].Id != token.Id {
+		t.Errorf("expected 1 token for %s, got: %+v", testUser, tokens)
+	}
+	if tokens, _ := store.Tokens("someoneelse"); len(tokens) != 0 {
+		t.Errorf("expected no tokens for someoneelse, got: %d", len(tokens))
+	}
+
+	if err := store.DeleteToken(token.Id); err != nil {
+		t.Errorf("expected delete token to succeed, got: %s", err)
+	}
+	if _, err := store.FindToken(hashAccessToken(secret)); err != errTokenNotFound {
+		t.Errorf("expected errTokenNotFound, got: %v", err)
+	}
+	if err := store.DeleteToken(token.Id); err != errTokenNotFound {
+		t.Errorf("expected errTokenNotFound, got: %v", err)
+	}
+
+	// deleting a user revokes their tokens
+	defer func(enabled bool) { Config.Ldap.Enabled = enabled }(Config.Ldap.Enabled)
+	Config.Ldap.Enabled = false
+	token, secret, _ = newAccessToken("tokenowner", "ci", "read", nil)
+	if err := store.AddToken(token); err != nil {
+		t.Fatalf("expected add token to succeed, got: %s", err)
+	}
+	if err := store.DeleteUser("tokenowner"); err != nil {
+		t.Errorf("expected delete user to succeed, got: %s", err)
+	}
+	if _, err := store.FindToken(hashAccessToken(secret)); err != errTokenNotFound {
+		t.Errorf("expected the tokens of a deleted user to be revoked, got: %v", err)
+	}
+}
+
+func TestProjectConformance(t *testing.T) {
+	setupMeta()
+	defer teardownMeta()
+
+	testProjectStore(t, metaStoreTest)
+}
+
+// testProjectStore checks that a meta store adds, renames and deletes projects like the others do
+func testProjectStore(t *testing.T, store GenericMetaStore) {
+	const namespace = "conformance"
+	findProject := func(name string) *MetaProject {
+		projects, _ := store.Projects()
+		for _, p := range projects {
+			if p.Namespace == namespace && p.Name == name {
+				return p
+			}
+		}
+		return nil
+	}
+
+	if err := store.AddProject(namespace, "empty"); err != nil {
+		t.Fatalf("expected add project to succeed, got: %s", err)
+	}
+	if err := store.AddProject(namespace, "empty"); err != errProjectExists {
+		t.Errorf("expected errProjectExists, got: %v", err)
+	}
+	if p := findProject("empty"); p == nil || len(p.Oids) != 0 {
+		t.Errorf("expected an empty project to be listed, got: %+v", p)
+	}
+
+	// pushing to an unknown project creates it
+	rv := &RequestVars{Authorization: testAuth, Oid: contentOid, Size: contentSize, Namespace: namespace, Repo: "old"}
+	if _, err := store.Put(rv); err != nil {
+		t.Fatalf("expected put to succeed, got: %s", err)
+	}
+	if err := store.AddLock(rv, &MetaLock{Id: "conformance-lock", Path: "a.bin", Owner: testUser}); err != nil {
+		t.Fatalf("expected add lock to succeed, got: %s", err)
+	}
+
+	if err := store.RenameProject(namespace, "old", namespace, "empty"); err != errProjectExists {
+		t.Errorf("expected errProjectExists renaming onto a project, got: %v", err)
+	}
+	if err := store.RenameProject(namespace, "missing", namespace, "other"); err != errProjectNotFound {
+		t.Errorf("expected errProjectNotFound, got: %v", err)
+	}
+	if err := store.RenameProject(namespace, "old", "renamed", "new"); err != nil {
+		t.Fatalf("expected rename project to succeed, got: %s", err)
+	}
+
+	if p := findProject("old"); p != nil {
+		t.Errorf("expected the old project to be gone, got: %+v", p)
+	}
+	projects, _ := store.Projects()
+	var renamed *MetaProject
+	for _, p := range projects {
+		if p.Namespace == "renamed" && p.Name == "new" {
+			renamed = p
+		}
+	}
+	if renamed == nil || len(renamed.Oids) != 1 || renamed.Oids[0] != contentOid {
+		t.Errorf("expected the object to move with the project, got: %+v", renamed)
+	}
+
+	newRv := &RequestVars{Authorization: testAuth, Namespace: "renamed", Repo: "new"}
+	locks, err := store.Locks(newRv)
+	if err != nil || len(locks) != 1 || locks[0].Id != "conformance-lock" || locks[0].Project != projectKey("renamed", "new") {
+		t.Errorf("expected the lock to move with the project, got: %+v %v", locks, err)
+	}
+	if locks, _ := store.Locks(rv); len(locks) != 0 {
+		t.Errorf("expected no locks left behind, got: %+v", locks)
+	}
+
+	// names from the URL are data, never part of a query
+	quoted := &RequestVars{Authorization: testAuth, Oid: contentOid, Size: contentSize, Namespace: namespace, Repo: "o'brien"}
+	if _, err := store.Put(quoted); err != nil {
+		t.Fatalf("expected put to a quoted repo to succeed, got: %s", err)
+	}
+	if p := findProject("o'brien"); p == nil || len(p.Oids) != 1 || p.Oids[0] != contentOid {
+		t.Errorf("expected the quoted repo to hold the object, got: %+v", p)
+	}
+
+	for _, p := range []struct{ namespace, name string }{{namespace, "empty"}, {"renamed", "new"}, {namespace, "o'brien"}} {
+		if err := store.DeleteProject(p.namespace, p.name); err != nil {
+			t.Errorf("expected delete project to succeed, got: %s", err)
+		}
+		if err := store.DeleteProject(p.namespace, p.name); err != errProjectNotFound {
+			t.Errorf("expected errProjectNotFound, got: %v", err)
+		}
+	}
+	store.DeleteLock(newRv, "conformance-lock")
+}
+
+func TestUpgradeLegacyProjects(t *testing.T) {
+	defer func(namespace string) { Config.LegacyNamespace = namespace }(Config.LegacyNamespace)
+	Config.LegacyNamespace = testNamespace
+	defer os.RemoveAll("test-legacy-meta-store.db")
+
+	// the layout from before namespaces, projects and object memberships by repo alone
+	db, err := bolt.Open("test-legacy-meta-store.db", 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		t.Fatalf("expected to open the database, got: %s", err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		values := map[string]map[string]interface{}{
+			"projects": {testRepo: struct {
+				Name string
+				Oids []string
+			}{testRepo, []string{contentOid}}},
+			"objects": {contentOid: struct {
+				Oid          string
+				Size         int64
+				ProjectNames []string
+			}{contentOid, contentSize, []string{testRepo}}},
+		}
+		for name, entries := range values {
+			bucket, err := tx.CreateBucket([]byte(name))
+			if err != nil {
+				return err
+			}
+			for k, v := range entries {
+				var buf bytes.Buffer
+				if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+					return err
+				}
+				if err := bucket.Put([]byte(k), buf.Bytes()); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	db.Close()
+	if err != nil {
+		t.Fatalf("expected to write the legacy layout, got: %s", err)
+	}
+
+	// upgrading twice leaves the upgraded projects alone
+	for i := 0; i < 2; i++ {
+		store, err := NewMetaStore("test-legacy-meta-store.db")
+		if err != nil {
+			t.Fatalf("expected the legacy database to be upgraded, got: %s", err)
+		}
+		meta, err := store.Get(&RequestVars{Authorization: testAuth, Oid: contentOid, Namespace: testNamespace, Repo: testRepo})
+		if err != nil || meta.Size != contentSize {
+			t.Errorf("expected the object to be found in %s/%s, got: %v %v", testNamespace, testRepo, meta, err)
+		}
+		projects, err := store.Projects()
+		if err != nil || len(projects) != 1 || projects[0].Key() != projectKey(testNamespace, testRepo) || len(projects[0].Oids) != 1 {
+			t.Errorf("expected the project to be moved into %s, got: %v %v", testNamespace, projects, err)
+		}
+		store.Close()
 	}
 }
 
@@ -99,7 +439,7 @@ func setupMeta() {
 		os.Exit(1)
 	}
 
-	rv := &RequestVars{Authorization: testAuth, Oid: contentOid, Size: contentSize}
+	rv := &RequestVars{Authorization: testAuth, Oid: contentOid, Size: contentSize, Namespace: testNamespace, Repo: testRepo}
 	if _, err := metaStoreTest.Put(rv); err != nil {
 		teardownMeta()
 		fmt.Printf("error seeding test meta store: %s\n", err)
@@ -8,7 +8,10 @@ import (
 	"html/template"
 	"io"
 	"net/http"
+	"net/url"
+	"sort"
 	"strings"
+	"time"
 )
 
 var (
@@ -24,6 +27,18 @@ type pageData struct {
 	Users      []*MetaUser
 	Objects    []*MetaObject
 	Projects   []*MetaProject
+	Namespace  string
+	Namespaces []string
+	GC         *GCResult
+	Tokens     []*MetaToken
+	NewToken   string
+}
+
+// createdToken is the mgmt response to creating a personal access token, the only
+// time the token itself is shown
+type createdToken struct {
+	*MetaToken
+	Token string `json:"token"`
 }
 
 func (a *App) addMgmt(r *mux.Router) {
@@ -31,9 +46,15 @@ func (a *App) addMgmt(r *mux.Router) {
 	r.HandleFunc("/mgmt/objects", basicAuth(a.objectsHandler)).Methods("GET")
 	r.HandleFunc("/mgmt/projects", basicAuth(a.projectsHandler)).Methods("GET")
 	r.HandleFunc("/mgmt/addProject", basicAuth(a.addProject)).Methods("POST")
+	r.HandleFunc("/mgmt/delProject", basicAuth(a.delProjectHandler)).Methods("POST")
+	r.HandleFunc("/mgmt/renameProject", basicAuth(a.renameProjectHandler)).Methods("POST")
+	r.HandleFunc("/mgmt/gc", basicAuth(a.gcHandler)).Methods("POST")
 	r.HandleFunc("/mgmt/users", basicAuth(a.usersHandler)).Methods("GET")
 	r.HandleFunc("/mgmt/add", basicAuth(a.addUserHandler)).Methods("POST")
 	r.HandleFunc("/mgmt/del", basicAuth(a.delUserHandler)).Methods("POST")
+	r.HandleFunc("/mgmt/tokens", basicAuth(a.tokensHandler)).Methods("GET")
+	r.HandleFunc("/mgmt/addToken", basicAuth(a.addTokenHandler)).Methods("POST")
+	r.HandleFunc("/mgmt/delToken", basicAuth(a.delTokenHandler)).Methods("POST")
 	r.HandleFunc("/mgmt/searchOid", basicAuth(a.searchOidHandler)).Methods("GET")
 
 	cssBox = rice.MustFindBox("mgmt/css")
@@ -115,14 +136,16 @@ func (a *App) searchOidHandler(w http.ResponseWriter, r *http.Request) {
 	searchedOid := r.URL.Query().Get("oid")
 	if len(searchedOid) < 1 {
 		writeStatus(w, r, 404)
+		return
 	}
-	cs, err := FindMetaStore()
-	if err != nil {
-		writeStatus(w, r, 404)
+	oids, err := a.metaStore.Objects()
+	if isUnavailable(err) {
+		writeUnavailable(w, r, err)
+		return
 	}
-	oids, err := cs.Objects()
 	if err != nil {
 		writeStatus(w, r, 404)
+		return
 	}
 	for _, oid := range oids {
 		if strings.Contains(oid.Oid, searchedOid) {
@@ -142,6 +165,16 @@ func (a *App) objectsHandler(w http.ResponseWriter, r *http.Request) {
 		fmt.Fprintf(w, "Error retrieving objects: %s", err)
 		return
 	}
+	namespace := r.URL.Query().Get("namespace")
+	var projects []*MetaProject
+	if namespace != "" {
+		projects, err = a.metaStore.Projects()
+		if err != nil && err != errProjectNotFound {
+			fmt.Fprintf(w, "Error retrieving projects: %s", err)
+			return
+		}
+		objects = filterObjects(objects, filterProjects(projects, namespace))
+	}
 	if isJson(r) {
 		// fmt.Println(r.Header)
 		w.Header().Set("Content-Type", "application/json")
@@ -151,7 +184,7 @@ func (a *App) objectsHandler(w http.ResponseWriter, r *http.Request) {
 		}
 		w.Write(_json)
 	} else {
-		if err := render(w, "objects.tmpl", pageData{Name: "objects", Objects: objects}); err != nil {
+		if err := render(w, "objects.tmpl", pageData{Name: "objects", Objects: objects, Namespace: namespace}); err != nil {
 			writeStatus(w, r, 404)
 		}
 	}
@@ -163,6 +196,9 @@ func (a *App) projectsHandler(w http.ResponseWriter, r *http.Request) {
 		fmt.Fprintf(w, "Error retrieving objects: %s", err)
 		return
 	}
+	namespace := r.URL.Query().Get("namespace")
+	all := projects
+	projects = filterProjects(projects, namespace)
 	if isJson(r) {
 		w.Header().Set("Content-Type", "application/json")
 		_json, err := json.Marshal(projects)
@@ -171,7 +207,8 @@ func (a *App) projectsHandler(w http.ResponseWriter, r *http.Request) {
 		}
 		w.Write(_json)
 	} else {
-		if err := render(w, "projects.tmpl", pageData{Name: "projects", Projects: projects}); err != nil {
+		data := pageData{Name: "projects", Projects: projects, Namespace: namespace, Namespaces: namespaces(all)}
+		if err := render(w, "projects.tmpl", data); err != nil {
 			writeStatus(w, r, 404)
 		}
 	}
@@ -199,18 +236,157 @@ func (a *App) usersHandler(w http.ResponseWriter, r *http.Request) {
 }
 
 func (a *App) addProject(w http.ResponseWriter, r *http.Request) {
+	namespace := r.FormValue("namespace")
 	projectName := r.FormValue("name")
-	if projectName == "" {
-		fmt.Fprintf(w, "Invalid project name: %s", projectName)
+	if namespace == "" || projectName == "" {
+		fmt.Fprintf(w, "Invalid project name: %s/%s", namespace, projectName)
 		return
 	}
 
-	if err := a.metaStore.AddProject(projectName); err != nil {
+	if err := a.metaStore.AddProject(namespace, projectName); err != nil {
 		fmt.Fprintf(w, "Error adding project: %s", err)
 		return
 	}
 
-	http.Redirect(w, r, "/mgmt/projects", 302)
+	http.Redirect(w, r, "/mgmt/projects?namespace="+url.QueryEscape(namespace), 302)
+}
+
+// filterProjects returns the projects in namespace, or all projects when namespace is empty
+func filterProjects(projects []*MetaProject, namespace string) []*MetaProject {
+	if namespace == "" {
+		return projects
+	}
+	filtered := []*MetaProject{}
+	for _, p := range projects {
+		if p.Namespace == namespace {
+			filtered = append(filtered, p)
+		}
+	}
+	return filtered
+}
+
+// filterObjects returns the objects that belong to any of the projects
+func filterObjects(objects []*MetaObject, projects []*MetaProject) []*MetaObject {
+	oids := make(map[string]bool)
+	for _, p := range projects {
+		for _, oid := range p.Oids {
+			oids[oid] = true
+		}
+	}
+	filtered := []*MetaObject{}
+	for _, o := range objects {
+		if oids[o.Oid] {
+			filtered = append(filtered, o)
+		}
+	}
+	return filtered
+}
+
+// namespaces returns the sorted, distinct namespaces of the projects
+func namespaces(projects []*MetaProject) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, p := range projects {
+		if !seen[p.Namespace] {
+			seen[p.Namespace] = true
+			names = append(names, p.Namespace)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (a *App) delProjectHandler(w http.ResponseWriter, r *http.Request) {
+	namespace := r.FormValue("namespace")
+	projectName := r.FormValue("name")
+	if projectName == "" {
+		fmt.Fprintf(w, "Invalid project name: %s/%s", namespace, projectName)
+		return
+	}
+
+	if err := a.metaStore.DeleteProject(namespace, projectName); err != nil {
+		if isJson(r) {
+			writeStatusMessage(w, r, 404, err.Error())
+			return
+		}
+		fmt.Fprintf(w, "Error deleting project: %s", err)
+		return
+	}
+
+	if isJson(r) {
+		writeStatus(w, r, 200)
+		return
+	}
+	http.Redirect(w, r, "/mgmt/projects?namespace="+url.QueryEscape(namespace), 302)
+}
+
+// renameProjectHandler moves the project namespace/name, with its objects and locks, to
+// new_namespace/new_name. new_namespace defaults to the current namespace.
+func (a *App) renameProjectHandler(w http.ResponseWriter, r *http.Request) {
+	namespace := r.FormValue("namespace")
+	projectName := r.FormValue("name")
+	newNamespace := r.FormValue("new_namespace")
+	newName := r.FormValue("new_name")
+	if newNamespace == "" {
+		newNamespace = namespace
+	}
+	if projectName == "" || newName == "" {
+		fmt.Fprintf(w, "Invalid project name: %s/%s", newNamespace, newName)
+		return
+	}
+
+	if err := a.metaStore.RenameProject(namespace, projectName, newNamespace, newName); err != nil {
+		if isJson(r) {
+			status := 404
+			if err == errProjectExists {
+				status = 409
+			}
+			writeStatusMessage(w, r, status, err.Error())
+			return
+		}
+		fmt.Fprintf(w, "Error renaming project: %s", err)
+		return
+	}
+
+	if isJson(r) {
+		writeStatus(w, r, 200)
+		return
+	}
+	http.Redirect(w, r, "/mgmt/projects?namespace="+url.QueryEscape(newNamespace), 302)
+}
+
+// gcHandler runs garbage collection, only reporting what would be removed when dry_run is set.
+// The grace period defaults to Config.GCGracePeriod and can be overridden with grace, e.g. grace=1h
+func (a *App) gcHandler(w http.ResponseWriter, r *http.Request) {
+	dryRun := r.FormValue("dry_run") == "true" || r.FormValue("dry_run") == "on"
+	grace := Config.GCGracePeriod
+	if g := r.FormValue("grace"); g != "" {
+		d, err := time.ParseDuration(g)
+		if err != nil || d < 0 {
+			fmt.Fprintf(w, "Invalid grace period: %s", g)
+			return
+		}
+		grace = d
+	}
+
+	result, err := collectGarbage(a.metaStore, a.contentStore, grace, dryRun)
+	if err != nil {
+		fmt.Fprintf(w, "Error collecting garbage: %s", err)
+		return
+	}
+
+	if isJson(r) {
+		w.Header().Set("Content-Type", "application/json")
+		_json, err := json.Marshal(result)
+		if err != nil {
+			writeStatus(w, r, 500)
+		}
+		w.Write(_json)
+	} else {
+		if err := render(w, "gc.tmpl", pageData{Name: "objects", GC: result}); err != nil {
+			writeStatus(w, r, 404)
+		}
+	}
 }
 
 func (a *App) addUserHandler(w http.ResponseWriter, r *http.Request) {
@@ -225,6 +401,7 @@ func (a *App) addUserHandler(w http.ResponseWriter, r *http.Request) {
 		fmt.Fprintf(w, "Error adding user: %s", err)
 		return
 	}
+	a.forgetUser(user)
 
 	http.Redirect(w, r, "/mgmt/users", 302)
 }
@@ -240,10 +417,96 @@ func (a *App) delUserHandler(w http.ResponseWriter, r *http.Request) {
 		fmt.Fprintf(w, "Error deleting user: %s", err)
 		return
 	}
+	a.forgetUser(user)
 
 	http.Redirect(w, r, "/mgmt/users", 302)
 }
 
+// tokensHandler lists personal access tokens, only those of user when given
+func (a *App) tokensHandler(w http.ResponseWriter, r *http.Request) {
+	tokens, err := a.metaStore.Tokens(r.FormValue("user"))
+	if err != nil {
+		fmt.Fprintf(w, "Error retrieving tokens: %s", err)
+		return
+	}
+
+	if isJson(r) {
+		w.Header().Set("Content-Type", "application/json")
+		_json, err := json.Marshal(tokens)
+		if err != nil {
+			writeStatus(w, r, 500)
+		}
+		w.Write(_json)
+	} else {
+		if err := render(w, "tokens.tmpl", pageData{Name: "tokens", Tokens: tokens}); err != nil {
+			writeStatus(w, r, 404)
+		}
+	}
+}
+
+// addTokenHandler creates a personal access token for user with a scope of read or
+// write, limited to the comma separated namespace/repo in projects when given
+func (a *App) addTokenHandler(w http.ResponseWriter, r *http.Request) {
+	user := r.FormValue("user")
+	if user == "" {
+		fmt.Fprintf(w, "Invalid username")
+		return
+	}
+	if found, err := a.knowsUser(user); !found {
+		if err == nil {
+			err = errUserNotFound
+		}
+		if isJson(r) {
+			writeStatusMessage(w, r, 422, err.Error())
+			return
+		}
+		fmt.Fprintf(w, "Error adding token: %s", err)
+		return
+	}
+
+	token, secret, err := newAccessToken(user, r.FormValue("name"), r.FormValue("scope"), splitProjects(r.FormValue("projects")))
+	if err == nil {
+		err = a.metaStore.AddToken(token)
+	}
+	if err != nil {
+		if isJson(r) {
+			writeStatusMessage(w, r, 422, err.Error())
+			return
+		}
+		fmt.Fprintf(w, "Error adding token: %s", err)
+		return
+	}
+
+	if isJson(r) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(201)
+		json.NewEncoder(w).Encode(&createdToken{MetaToken: token, Token: secret})
+		return
+	}
+	tokens, _ := a.metaStore.Tokens("")
+	if err := render(w, "tokens.tmpl", pageData{Name: "tokens", Tokens: tokens, NewToken: secret}); err != nil {
+		writeStatus(w, r, 404)
+	}
+}
+
+func (a *App) delTokenHandler(w http.ResponseWriter, r *http.Request) {
+	id := r.FormValue("id")
+	if err := a.metaStore.DeleteToken(id); err != nil {
+		if isJson(r) {
+			writeStatusMessage(w, r, 404, err.Error())
+			return
+		}
+		fmt.Fprintf(w, "Error deleting token: %s", err)
+		return
+	}
+
+	if isJson(r) {
+		writeStatus(w, r, 200)
+		return
+	}
+	http.Redirect(w, r, "/mgmt/tokens", 302)
+}
+
 func render(w http.ResponseWriter, tmpl string, data pageData) error {
 	bodyString, err := templateBox.String("body.tmpl")
 	if err != nil {
@@ -255,8 +518,13 @@ func render(w http.ResponseWriter, tmpl string, data pageData) error {
 		return err
 	}
 
-	t := template.Must(template.New("main").Parse(bodyString))
-	t.New("content").Parse(contentString)
+	t, err := template.New("main").Parse(bodyString)
+	if err != nil {
+		return err
+	}
+	if _, err := t.New("content").Parse(contentString); err != nil {
+		return err
+	}
 
 	return t.Execute(w, data)
 }
@@ -5,7 +5,12 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/GeertJohan/go.rice"
 )
 
 type values map[string]string
@@ -63,7 +68,7 @@ func TestMgmtGetObjects_Json(t *testing.T) {
 	}
 }
 func TestMgmtGetProjects_Json(t *testing.T) {
-	_, err := testMetaStore.Put(&RequestVars{Repo: testRepo, User: testUser, Oid: contentOid, Authorization: testAuth})
+	_, err := testMetaStore.Put(&RequestVars{Namespace: testNamespace, Repo: testRepo, User: testUser, Oid: contentOid, Authorization: testAuth})
 	if err != nil {
 		fmt.Println("got an err", err.Error())
 	}
@@ -96,6 +101,152 @@ func TestMgmtGetProjects_Json(t *testing.T) {
 
 }
 
+func TestMgmtGetProjectsByNamespace_Json(t *testing.T) {
+	otherOid := strings.Repeat("c", 64)
+	_, err := testMetaStore.Put(&RequestVars{Namespace: "otherspace", Repo: testRepo, User: testUser, Oid: otherOid, Size: 42, Authorization: testAuth})
+	if err != nil {
+		t.Fatalf("got an err: %s", err)
+	}
+
+	var projects []*MetaProject
+	mgmtGetJson(t, "/mgmt/projects?namespace=otherspace", &projects)
+	if len(projects) != 1 || projects[0].Namespace != "otherspace" || projects[0].Name != testRepo {
+		t.Errorf("expected only otherspace/%s, got %+v", testRepo, projects)
+	}
+
+	var objects []*MetaObject
+	mgmtGetJson(t, "/mgmt/objects?namespace=otherspace", &objects)
+	if len(objects) != 1 || objects[0].Oid != otherOid {
+		t.Errorf("expected only %s in otherspace, got %+v", otherOid, objects)
+	}
+
+	objects = nil
+	mgmtGetJson(t, "/mgmt/objects?namespace="+testNamespace, &objects)
+	for _, o := range objects {
+		if o.Oid == otherOid {
+			t.Errorf("expected %s to not be in %s", otherOid, testNamespace)
+		}
+	}
+}
+
+func TestMgmtGCDryRun_Json(t *testing.T) {
+	req, err := http.NewRequest("POST", lfsServer.URL+"/mgmt/gc?dry_run=true", nil)
+	if err != nil {
+		t.Fatalf("request error: %s", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(Config.AdminUser, Config.AdminPass)
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("response error: %s", err)
+	}
+	var result GCResult
+	json.NewDecoder(res.Body).Decode(&result)
+	if !result.DryRun {
+		t.Errorf("expected a dry run, got %+v", result)
+	}
+	for _, m := range result.Deleted {
+		if m.Oid == contentOid {
+			t.Errorf("expected referenced oid %s to be kept", contentOid)
+		}
+	}
+	if !testContentStore.Exists(&MetaObject{Oid: contentOid}) {
+		t.Errorf("expected dry run to keep content")
+	}
+}
+
+func TestMgmtTokens_Json(t *testing.T) {
+	req, err := http.NewRequest("POST", lfsServer.URL+"/mgmt/addToken", strings.NewReader("user="+testUser+"&name=ci&scope=read&projects=namespace/repo"))
+	if err != nil {
+		t.Fatalf("request error: %s", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(Config.AdminUser, Config.AdminPass)
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("response error: %s", err)
+	}
+	if res.StatusCode != 201 {
+		t.Fatalf("expected status 201, got %d", res.StatusCode)
+	}
+	var created struct {
+		Id    string `json:"id"`
+		Token string `json:"token"`
+	}
+	json.NewDecoder(res.Body).Decode(&created)
+	if !strings.HasPrefix(created.Token, accessTokenPrefix) {
+		t.Fatalf("expected the token to be returned, got %+v", created)
+	}
+
+	var tokens []map[string]interface{}
+	mgmtGetJson(t, "/mgmt/tokens?user="+testUser, &tokens)
+	if len(tokens) != 1 || tokens[0]["id"] != created.Id || tokens[0]["scope"] != "read" {
+		t.Errorf("expected the new token to be listed, got %+v", tokens)
+	}
+	if _, ok := tokens[0]["hash"]; ok {
+		t.Errorf("expected the token hash to not be listed")
+	}
+
+	req, _ = http.NewRequest("POST", lfsServer.URL+"/mgmt/delToken?id="+created.Id, nil)
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(Config.AdminUser, Config.AdminPass)
+	res, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("response error: %s", err)
+	}
+	if res.StatusCode != 200 {
+		t.Errorf("expected status 200, got %d", res.StatusCode)
+	}
+
+	req, _ = http.NewRequest("POST", lfsServer.URL+"/mgmt/addToken", strings.NewReader("user=nobody&name=ci&scope=read"))
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(Config.AdminUser, Config.AdminPass)
+	res, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("response error: %s", err)
+	}
+	if res.StatusCode != 422 {
+		t.Errorf("expected a token for an unknown user to be refused with 422, got %d", res.StatusCode)
+	}
+}
+
+func TestMgmtAddProjectRedirectEscapes(t *testing.T) {
+	req, _ := http.NewRequest("POST", lfsServer.URL+"/mgmt/addProject", strings.NewReader("namespace=a%26b%3D1&name=escaped"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(Config.AdminUser, Config.AdminPass)
+	res, err := http.DefaultTransport.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("response error: %s", err)
+	}
+	res.Body.Close()
+	defer testMetaStore.DeleteProject("a&b=1", "escaped")
+
+	if location := res.Header.Get("Location"); location != "/mgmt/projects?namespace=a%26b%3D1" {
+		t.Errorf("expected the namespace to be escaped in the redirect, got: %s", location)
+	}
+}
+
+func mgmtGetJson(t *testing.T, path string, v interface{}) {
+	req, err := http.NewRequest("GET", lfsServer.URL+path, nil)
+	if err != nil {
+		t.Fatalf("request error: %s", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(Config.AdminUser, Config.AdminPass)
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("response error: %s", err)
+	}
+	if res.StatusCode != 200 {
+		t.Fatalf("response code failed. Expected 200, got %d", res.StatusCode)
+	}
+	if err := json.NewDecoder(res.Body).Decode(v); err != nil {
+		t.Fatalf("decode error: %s", err)
+	}
+}
+
 func TestMgmtGetUsers_Json(t *testing.T) {
 	err := testMetaStore.AddUser(testUser, testPass)
 	if err != nil {
@@ -128,3 +279,73 @@ func TestMgmtGetUsers_Json(t *testing.T) {
 		t.Errorf("expected project name to be %+v, got %+v", testRepo, meta)
 	}
 }
+
+func TestMgmtDelUserForgetsCredentials(t *testing.T) {
+	if err := testMetaStore.AddUser("painter", "easel"); err != nil {
+		t.Fatalf("error adding user: %s", err)
+	}
+	app := NewApp(testContentStore, testMetaStore)
+	server := httptest.NewServer(app)
+	defer server.Close()
+
+	if ok, _ := app.authenticator.Authenticate("painter", "easel"); !ok {
+		t.Fatalf("expected the user to be accepted")
+	}
+
+	req, _ := http.NewRequest("POST", server.URL+"/mgmt/del?name=painter", nil)
+	req.SetBasicAuth(Config.AdminUser, Config.AdminPass)
+	res, err := http.DefaultTransport.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("response error: %s", err)
+	}
+	res.Body.Close()
+
+	if ok, _ := app.authenticator.Authenticate("painter", "easel"); ok {
+		t.Errorf("expected the deleted user to be rejected despite the cache")
+	}
+}
+
+func TestMgmtTemplatesEmbedded(t *testing.T) {
+	box := rice.MustFindBox("mgmt/templates")
+	if !box.IsEmbedded() {
+		t.Fatalf("expected the mgmt templates to be embedded, run scripts/ricer.sh")
+	}
+	defer func(b *rice.Box) { templateBox = b }(templateBox)
+	templateBox = box
+
+	files, err := ioutil.ReadDir("mgmt/templates")
+	if err != nil {
+		t.Fatalf("expected to list the templates, got: %s", err)
+	}
+	data := pageData{
+		Name:       "objects",
+		Config:     Config,
+		ConfigDump: Config.DumpConfig(),
+		Users:      []*MetaUser{{Name: testUser}},
+		Objects:    []*MetaObject{{Oid: contentOid, Size: contentSize}},
+		Projects:   []*MetaProject{{Namespace: testNamespace, Name: testRepo, Oids: []string{contentOid}}},
+		Namespace:  testNamespace,
+		Namespaces: []string{testNamespace},
+		GC:         &GCResult{DryRun: true, Deleted: []*MetaObject{{Oid: contentOid, Size: contentSize}}},
+		Tokens:     []*MetaToken{{Id: "id", User: testUser, Name: "ci", Scope: "read", CreatedAt: time.Now()}},
+		NewToken:   "lfs_secret",
+	}
+	for _, f := range files {
+		onDisk, err := ioutil.ReadFile("mgmt/templates/" + f.Name())
+		if err != nil {
+			t.Fatalf("expected to read %s, got: %s", f.Name(), err)
+		}
+		embedded, err := box.String(f.Name())
+		if err != nil || embedded != string(onDisk) {
+			t.Errorf("expected the box to embed the current %s, run scripts/ricer.sh", f.Name())
+			continue
+		}
+		if f.Name() == "body.tmpl" {
+			continue
+		}
+		w := httptest.NewRecorder()
+		if err := render(w, f.Name(), data); err != nil {
+			t.Errorf("expected %s to render, got: %s", f.Name(), err)
+		}
+	}
+}
@@ -0,0 +1,318 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// migration is one step of the schema of a SQL meta store. Migrations are applied in
+// order of version and recorded in the schema_version table; once released, a
+// migration must never change, later changes go in a new migration.
+// upgrade, when set, runs after the statements for changes that depend on the data.
+type migration struct {
+	version     int
+	description string
+	statements  []string
+	upgrade     func(tx *sql.Tx) error
+}
+
+// placeholder returns the bind parameter n (counting from 1) of a SQL driver
+type placeholder func(n int) string
+
+func dollarNumber(n int) string {
+	return fmt.Sprintf("$%d", n)
+}
+
+func questionMark(n int) string {
+	return "?"
+}
+
+// sqlCatalog holds the queries reading the schema of a MySQL or SQLite database during a
+// migration, each taking the table name as its only parameter
+type sqlCatalog struct {
+	columns string // the name of every column and whether it allows nulls
+	indexes string // the name of every index
+}
+
+// sqlMigrations returns the schema shared by the MySQL and SQLite meta stores, serial being
+// the column definition of a generated primary key. Version 1 must only ever create tables
+// that don't exist, the tables created before the schema was versioned are upgraded by
+// version 3.
+func sqlMigrations(serial string, catalog sqlCatalog) []migration {
+	return []migration{
+		{1, "create projects, oids, locks, tokens and users", []string{
+			"create table if not exists projects (id " + serial + ", namespace varchar(255) not null, name varchar(255) not null, unique (namespace, name))",
+			"create table if not exists oids (oid varchar(255) not null primary key, size bigint not null)",
+			"create table if not exists oid_maps (oid varchar(255) not null, projectID bigint not null)",
+			"create table if not exists locks (id varchar(255) not null primary key, project varchar(255) not null, path varchar(255) not null, owner varchar(255) not null, lockedAt datetime not null, unique (project, path))",
+			"create table if not exists tokens (hash varchar(255) not null primary key, id varchar(255) not null, username varchar(255) not null, name varchar(255) not null, scope varchar(255) not null, projects text not null, createdAt datetime not null)",
+			"create table if not exists users (name varchar(255) not null primary key, password varchar(255) not null)",
+		}, nil},
+		{2, "index project memberships and tokens", []string{
+			"create index oid_maps_project on oid_maps (projectID)",
+			"create index oid_maps_oid on oid_maps (oid)",
+			"create index tokens_id on tokens (id)",
+			"create index tokens_username on tokens (username)",
+		}, nil},
+		{3, "move projects from before namespaces into the legacy namespace", nil, upgradeLegacyTables(serial, catalog)},
+	}
+}
+
+// legacyTable is a table gorp created before namespaces, which is rebuilt through a copy
+type legacyTable struct {
+	name   string
+	copy   string
+	legacy func(columns map[string]bool) bool
+	create string // creates the copy
+	fill   string // fills the copy from the table
+}
+
+// upgradeLegacyTables rebuilds the tables gorp created before namespaces: projects with
+// a unique name alone, oids and oid_maps allowing nulls. Projects keep their ids, and so
+// their objects, and are moved into Config.LegacyNamespace. Tables already in the current
+// shape are left alone. MySQL commits every schema change on its own, so each table is
+// rebuilt separately and a run that stopped half way is picked up where it stopped.
+func upgradeLegacyTables(serial string, catalog sqlCatalog) func(tx *sql.Tx) error {
+	tables := []legacyTable{
+		{"projects", "projects_namespaced",
+			func(columns map[string]bool) bool {
+				_, namespaced := columns["namespace"]
+				return !namespaced
+			},
+			"create table projects_namespaced (id " + serial + ", namespace varchar(255) not null, name varchar(255) not null, unique (namespace, name))",
+			"insert into projects_namespaced (id, namespace, name) select id, ?, name from projects where name is not null"},
+		{"oids", "oids_upgraded",
+			func(columns map[string]bool) bool { return columns["oid"] || columns["size"] },
+			"create table oids_upgraded (oid varchar(255) not null primary key, size bigint not null)",
+			"insert into oids_upgraded (oid, size) select oid, coalesce(size, 0) from oids where oid is not null"},
+		{"oid_maps", "oid_maps_upgraded",
+			func(columns map[string]bool) bool { return columns["oid"] || columns["projectID"] },
+			"create table oid_maps_upgraded (oid varchar(255) not null, projectID bigint not null)",
+			"insert into oid_maps_upgraded (oid, projectID) select distinct oid, projectID from oid_maps where oid is not null and projectID is not null"},
+	}
+
+	return func(tx *sql.Tx) error {
+		for _, table := range tables {
+			if err := rebuildLegacyTable(tx, catalog, table); err != nil {
+				return err
+			}
+		}
+
+		// a rebuilt oid_maps lost the indexes of migration 2
+		indexes, err := tableIndexes(tx, catalog, "oid_maps")
+		if err != nil {
+			return err
+		}
+		for _, index := range [][2]string{{"oid_maps_project", "projectID"}, {"oid_maps_oid", "oid"}} {
+			if indexes[index[0]] {
+				continue
+			}
+			if _, err := tx.Exec("create index " + index[0] + " on oid_maps (" + index[1] + ")"); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// rebuildLegacyTable replaces table by its copy when the table has the legacy shape. A copy
+// an earlier run left behind is finished when the table is already gone, and dropped otherwise.
+func rebuildLegacyTable(tx *sql.Tx, catalog sqlCatalog, table legacyTable) error {
+	columns, err := tableColumns(tx, catalog, table.name)
+	if err != nil {
+		return err
+	}
+	copied, err := tableColumns(tx, catalog, table.copy)
+	if err != nil {
+		return err
+	}
+
+	rename := "alter table " + table.copy + " rename to " + table.name
+	var statements []string
+	switch {
+	case len(columns) == 0 && len(copied) == 0:
+		// nothing left to upgrade, start over empty
+		statements = []string{table.create, rename}
+	case len(columns) == 0:
+		// the table was dropped after its copy was filled
+		statements = []string{rename}
+	case !table.legacy(columns):
+		if len(copied) == 0 {
+			return nil
+		}
+		statements = []string{"drop table " + table.copy}
+	default:
+		if len(copied) > 0 {
+			statements = append(statements, "drop table "+table.copy)
+		}
+		statements = append(statements, table.create, table.fill, "drop table "+table.name, rename)
+	}
+
+	for _, statement := range statements {
+		var args []interface{}
+		if strings.Contains(statement, "?") {
+			args = append(args, Config.LegacyNamespace)
+		}
+		if _, err := tx.Exec(statement, args...); err != nil {
+			return err
+		}
+	}
+	logger.Log(kv{"fn": "upgradeLegacyTables", "table": table.name, "namespace": Config.LegacyNamespace})
+	return nil
+}
+
+// tableColumns returns whether each column of table allows nulls, by name. Tables that
+// don't exist have no columns.
+func tableColumns(tx *sql.Tx, catalog sqlCatalog, table string) (map[string]bool, error) {
+	rows, err := tx.Query(catalog.columns, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		var nullable bool
+		if err := rows.Scan(&name, &nullable); err != nil {
+			return nil, err
+		}
+		columns[name] = nullable
+	}
+	return columns, rows.Err()
+}
+
+// tableIndexes returns the names of the indexes on table
+func tableIndexes(tx *sql.Tx, catalog sqlCatalog, table string) (map[string]bool, error) {
+	rows, err := tx.Query(catalog.indexes, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	indexes := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		indexes[name] = true
+	}
+	return indexes, rows.Err()
+}
+
+const createSchemaVersion = "create table if not exists schema_version (version integer not null primary key, description varchar(255) not null, applied_at timestamp not null)"
+
+// schemaVersion returns the version of the newest migration applied to db, 0 when none are
+func schemaVersion(db *sql.DB) (int, error) {
+	if _, err := db.Exec(createSchemaVersion); err != nil {
+		return 0, err
+	}
+	var version sql.NullInt64
+	if err := db.QueryRow("select max(version) from schema_version").Scan(&version); err != nil {
+		return 0, err
+	}
+	return int(version.Int64), nil
+}
+
+// migrate applies the migrations newer than the schema of db, each in a transaction with
+// its schema_version row. Returns the number of migrations applied.
+// MySQL commits schema changes implicitly, so there a failed migration can be left half
+// applied; it runs again on the next start and has to pick up where it stopped.
+func migrate(db *sql.DB, migrations []migration, bind placeholder) (int, error) {
+	current, err := schemaVersion(db)
+	if err != nil {
+		return 0, err
+	}
+	if latest := latestVersion(migrations); current > latest {
+		logger.Log(kv{"fn": "migrate", "version": current, "latest": latest, "msg": errSchemaTooNew.Error()})
+		return 0, errSchemaTooNew
+	}
+
+	applied := 0
+	for _, m := range migrations {
+		if m.version <= current {
+			continue
+		}
+		tx, err := db.Begin()
+		if err != nil {
+			return applied, err
+		}
+		for _, statement := range m.statements {
+			if _, err := tx.Exec(statement); err != nil {
+				tx.Rollback()
+				return applied, fmt.Errorf("Migration %d (%s) failed: %s", m.version, m.description, err)
+			}
+		}
+		if m.upgrade != nil {
+			if err := m.upgrade(tx); err != nil {
+				tx.Rollback()
+				return applied, fmt.Errorf("Migration %d (%s) failed: %s", m.version, m.description, err)
+			}
+		}
+		insert := fmt.Sprintf("insert into schema_version (version, description, applied_at) values (%s, %s, current_timestamp)", bind(1), bind(2))
+		if _, err := tx.Exec(insert, m.version, m.description); err != nil {
+			tx.Rollback()
+			return applied, err
+		}
+		if err := tx.Commit(); err != nil {
+			return applied, err
+		}
+		logger.Log(kv{"fn": "migrate", "version": m.version, "msg": m.description})
+		applied++
+	}
+	return applied, nil
+}
+
+// latestVersion returns the version the migrations bring a schema to
+func latestVersion(migrations []migration) int {
+	latest := 0
+	for _, m := range migrations {
+		if m.version > latest {
+			latest = m.version
+		}
+	}
+	return latest
+}
+
+// appliedMigrations returns when each migration applied to db was applied, by version
+func appliedMigrations(db *sql.DB) (map[int]string, error) {
+	if _, err := db.Exec(createSchemaVersion); err != nil {
+		return nil, err
+	}
+	rows, err := db.Query("select version, applied_at from schema_version")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[int]string)
+	for rows.Next() {
+		var version int
+		var appliedAt string
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		applied[version] = appliedAt
+	}
+	return applied, rows.Err()
+}
+
+// openSchema opens the database of the SQL meta store in Config.BackingStore without
+// migrating it, returning the migrations and placeholder of the database as well
+func openSchema() (*sql.DB, []migration, placeholder, error) {
+	switch Config.BackingStore {
+	case "mysql":
+		db, err := openMySQL()
+		return db, sqlMigrations(mysqlSerial, mysqlCatalog), questionMark, err
+	case "postgres":
+		db, err := openPostgres()
+		return db, postgresMigrations, dollarNumber, err
+	case "sqlite":
+		db, err := openSQLite()
+		return db, sqlMigrations(sqliteSerial, sqliteCatalog), questionMark, err
+	default:
+		return nil, nil, nil, errNoSchema
+	}
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"database/sql"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var testMigrations = []migration{
+	{1, "create a", []string{"create table a (id integer primary key)"}, nil},
+	{2, "create b", []string{"create table b (id integer primary key)"}, nil},
+}
+
+func TestMigrateAppliesInOrder(t *testing.T) {
+	db, teardown := setupSchemaDB(t)
+	defer teardown()
+
+	applied, err := migrate(db, testMigrations[:1], questionMark)
+	if err != nil || applied != 1 {
+		t.Fatalf("expected 1 migration applied, got: %d %v", applied, err)
+	}
+	applied, err = migrate(db, testMigrations, questionMark)
+	if err != nil || applied != 1 {
+		t.Fatalf("expected only the new migration applied, got: %d %v", applied, err)
+	}
+
+	if version, err := schemaVersion(db); err != nil || version != 2 {
+		t.Errorf("expected schema version 2, got: %d %v", version, err)
+	}
+	if _, err := db.Exec("insert into b (id) values (1)"); err != nil {
+		t.Errorf("expected table b to exist, got: %s", err)
+	}
+	versions, err := appliedMigrations(db)
+	if err != nil || len(versions) != 2 || versions[1] == "" || versions[2] == "" {
+		t.Errorf("expected both migrations recorded, got: %v %v", versions, err)
+	}
+}
+
+func TestMigrateRollsBackFailedMigration(t *testing.T) {
+	db, teardown := setupSchemaDB(t)
+	defer teardown()
+
+	broken := append(testMigrations[:1:1], migration{2, "broken", []string{
+		"create table c (id integer primary key)",
+		"create table nonsense (",
+	}, nil})
+	applied, err := migrate(db, broken, questionMark)
+	if err == nil || applied != 1 {
+		t.Fatalf("expected the second migration to fail, got: %d %v", applied, err)
+	}
+
+	if version, _ := schemaVersion(db); version != 1 {
+		t.Errorf("expected schema version 1, got: %d", version)
+	}
+	if _, err := db.Exec("insert into c (id) values (1)"); err == nil {
+		t.Errorf("expected table c to be rolled back")
+	}
+}
+
+func TestMigrateRefusesNewerSchema(t *testing.T) {
+	db, teardown := setupSchemaDB(t)
+	defer teardown()
+
+	if _, err := migrate(db, testMigrations, questionMark); err != nil {
+		t.Fatalf("expected migrations to succeed, got: %s", err)
+	}
+	if _, err := migrate(db, testMigrations[:1], questionMark); err != errSchemaTooNew {
+		t.Errorf("expected errSchemaTooNew from an older server, got: %v", err)
+	}
+}
+
+func TestMigrateRefusesToStart(t *testing.T) {
+	db, teardown := setupSchemaDB(t)
+	defer teardown()
+
+	if _, err := migrate(db, sqlMigrations(sqliteSerial, sqliteCatalog), questionMark); err != nil {
+		t.Fatalf("expected migrations to succeed, got: %s", err)
+	}
+	if _, err := db.Exec("insert into schema_version (version, description, applied_at) values (99, 'from the future', current_timestamp)"); err != nil {
+		t.Fatalf("expected insert to succeed, got: %s", err)
+	}
+
+	if store, err := NewSQLiteMetaStore(); store != nil || err != errSchemaTooNew {
+		t.Errorf("expected the meta store to refuse a newer schema, got: %v", err)
+	}
+	if isUnavailable(errSchemaTooNew) {
+		t.Errorf("expected a newer schema not to be retried")
+	}
+}
+
+func TestMigrateSchemaCommand(t *testing.T) {
+	_, teardown := setupSchemaDB(t)
+	defer teardown()
+	defer func(store string) { Config.BackingStore = store }(Config.BackingStore)
+
+	Config.BackingStore = "sqlite"
+	if code := migrateSchema([]string{"down"}); code != 2 {
+		t.Errorf("expected usage error, got exit code %d", code)
+	}
+	if code := migrateSchema([]string{"status"}); code != 0 {
+		t.Errorf("expected status to succeed, got exit code %d", code)
+	}
+	if code := migrateSchema([]string{"up"}); code != 0 {
+		t.Errorf("expected up to succeed, got exit code %d", code)
+	}
+
+	db, _, _, err := openSchema()
+	if err != nil {
+		t.Fatalf("expected to open the schema, got: %s", err)
+	}
+	defer db.Close()
+	if version, _ := schemaVersion(db); version != latestVersion(sqlMigrations(sqliteSerial, sqliteCatalog)) {
+		t.Errorf("expected up to migrate to the latest version, got: %d", version)
+	}
+
+	Config.BackingStore = "bolt"
+	if code := migrateSchema([]string{"status"}); code != 1 {
+		t.Errorf("expected bolt to have no schema, got exit code %d", code)
+	}
+}
+
+// setupSchemaDB opens an empty SQLite database, which Config.SQLiteDB points at
+func setupSchemaDB(t *testing.T) (*sql.DB, func()) {
+	dir, err := ioutil.TempDir("", "lfs-schema")
+	if err != nil {
+		t.Fatalf("expected a temp dir, got: %s", err)
+	}
+	path := Config.SQLiteDB
+	Config.SQLiteDB = filepath.Join(dir, "schema.sqlite")
+
+	db, err := openSQLite()
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatalf("expected to open %s, got: %s", Config.SQLiteDB, err)
+	}
+	return db, func() {
+		db.Close()
+		os.RemoveAll(dir)
+		Config.SQLiteDB = path
+	}
+}
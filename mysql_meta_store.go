@@ -1,14 +1,16 @@
 package main
 
 import (
-	"bytes"
 	"database/sql"
-	"encoding/base64"
-	"encoding/gob"
 	"fmt"
 	"strings"
+
+	"github.com/go-sql-driver/mysql"
 )
 
+// mysqlErrDuplicateEntry is the MySQL error number for a unique key violation
+const mysqlErrDuplicateEntry = 1062
+
 /*
 MySQLMetaStore struct.
 */
@@ -22,15 +24,28 @@ NewMySQLMetaStore (method update the MySQLMetaStore struct)
 */
 func NewMySQLMetaStore(mysqlService ...*MySQLService) (*MySQLMetaStore, error) {
 	if len(mysqlService) == 0 {
-		mysqlService = append(mysqlService, NewMySQLSession())
+		session, err := NewMySQLSession()
+		if err != nil {
+			return nil, err
+		}
+		mysqlService = append(mysqlService, session)
 	}
 
 	mysql := mysqlService[0]
+	return &MySQLMetaStore{mysqlService: mysql, client: mysql.Client}, nil
+}
 
-	if mysql.Fail {
-		return nil, errMissingParams
+/*
+mysqlError (wrap err in a store error, unavailable when MySQL can't be reached, so requests get a 503)
+*/
+func mysqlError(op string, err error) error {
+	if err == nil || isStoreError(err) {
+		return err
 	}
-	return &MySQLMetaStore{mysqlService: mysql, client: mysql.Client}, nil
+	if err == mysql.ErrInvalidConn {
+		return newUnavailableError("mysql", op, err)
+	}
+	return newStoreError("mysql", op, err)
 }
 
 /*
@@ -45,7 +60,11 @@ func (m *MySQLMetaStore) Close() {
 Oid finder - returns a []*MetaObject
 */
 func (m *MySQLMetaStore) findAllOids() ([]*MetaObject, error) {
-	rows, _ := m.client.Query("select oid, size from oids;")
+	rows, err := m.client.Query("select oid, size from oids;")
+	if err != nil {
+		return nil, mysqlError("list oids", err)
+	}
+	defer rows.Close()
 
 	var oid string
 	var size int64
@@ -59,9 +78,7 @@ func (m *MySQLMetaStore) findAllOids() ([]*MetaObject, error) {
 		}
 		oidList = append(oidList, &MetaObject{Oid: oid, Size: size})
 	}
-
-	defer rows.Close()
-	return oidList, nil
+	return oidList, mysqlError("list oids", rows.Err())
 }
 
 /*
@@ -77,14 +94,14 @@ func (m *MySQLMetaStore) mapOid(id int64) ([]string, error) {
 
 	if err != nil {
 		logger.Log(kv{"fn": "findProject", "msg": fmt.Sprintf("Oid not found %s", err)})
-		return nil, err
+		return nil, mysqlError("find oids", err)
 	}
 
 	for rows.Next() {
 		err := rows.Scan(&oid)
 		if err != nil {
 			logger.Log(kv{"fn": "findProject", "msg": err})
-			return nil, err
+			return nil, mysqlError("find oids", err)
 		}
 		oidList = append(oidList, oid)
 	}
@@ -98,6 +115,10 @@ Project finder - returns a []*MetaProject
 */
 func (m *MySQLMetaStore) findAllProjects() ([]*MetaProject, error) {
 	count, err := m.client.Query("select count(*) as count from projects")
+	if err != nil {
+		return nil, mysqlError("list projects", err)
+	}
+	defer count.Close()
 	var c int
 	for count.Next() {
 		err = count.Scan(&c)
@@ -107,26 +128,28 @@ func (m *MySQLMetaStore) findAllProjects() ([]*MetaProject, error) {
 		}
 	}
 
-	rows, err := m.client.Query("select id, name from projects")
+	rows, err := m.client.Query("select id, namespace, name from projects")
+	if err != nil {
+		return nil, mysqlError("list projects", err)
+	}
+	defer rows.Close()
 
-	var name string
+	var namespace, name string
 	var id int64
 
 	var projectList []*MetaProject
 
 	for rows.Next() {
-		err = rows.Scan(&id, &name)
+		err = rows.Scan(&id, &namespace, &name)
 
 		if err != nil {
 			logger.Log(kv{"fn": "findProject", "msg": err})
 		}
 
 		oid, _ := m.mapOid(id)
-		projectList = append(projectList, &MetaProject{Name: name, Oids: oid})
+		projectList = append(projectList, &MetaProject{Namespace: namespace, Name: name, Oids: oid})
 	}
 
-	defer rows.Close()
-
 	if len(projectList) == 0 {
 		return nil, errProjectNotFound
 	}
@@ -134,11 +157,15 @@ func (m *MySQLMetaStore) findAllProjects() ([]*MetaProject, error) {
 }
 
 // Create project
-func (m *MySQLMetaStore) createProject(name string) error {
-	_, err := m.client.Exec("insert into projects (name) values (?)", name)
+func (m *MySQLMetaStore) createProject(namespace, name string) error {
+	_, err := m.client.Exec("insert into projects (namespace, name) values (?, ?)", namespace, name)
+	if me, ok := err.(*mysql.MySQLError); ok && me.Number == mysqlErrDuplicateEntry {
+		// created by a concurrent upload
+		return nil
+	}
 	if err != nil {
 		logger.Log(kv{"fn": "createProject", "msg": fmt.Sprintf("MySQL insert query failed with error %s", err)})
-		return err
+		return mysqlError("create project", err)
 	}
 	return nil
 }
@@ -146,17 +173,20 @@ func (m *MySQLMetaStore) createProject(name string) error {
 // Create oid
 func (m *MySQLMetaStore) createOid(oid string, size int64) error {
 	_, err := m.client.Exec("insert into oids (oid, size) values (?, ?)", oid, size)
-
+	if me, ok := err.(*mysql.MySQLError); ok && me.Number == mysqlErrDuplicateEntry {
+		// created by a concurrent upload
+		return nil
+	}
 	if err != nil {
 		logger.Log(kv{"fn": "createOid", "msg": fmt.Sprintf("MySQL insert query failed with error %s", err)})
-		return nil
+		return mysqlError("create oid", err)
 	}
 	return nil
 }
 
 // Find project
-func (m *MySQLMetaStore) findProject(projectName string) (*MetaProject, error) {
-	if projectName == "" {
+func (m *MySQLMetaStore) findProject(namespace, name string) (*MetaProject, error) {
+	if name == "" {
 		return nil, errProjectNotFound
 	}
 
@@ -167,11 +197,15 @@ func (m *MySQLMetaStore) findProject(projectName string) (*MetaProject, error) {
 	)
 
 	// Get projectname and its ids
-	err := m.client.QueryRow("select * from projects where name = ?", projectName).Scan(&id, &project.Name)
+	err := m.client.QueryRow("select id, namespace, name from projects where namespace = ? and name = ?",
+		namespace, name).Scan(&id, &project.Namespace, &project.Name)
 
+	if err == sql.ErrNoRows {
+		return nil, errProjectNotFound
+	}
 	if err != nil {
 		logger.Log(kv{"fn": "findProject", "msg": fmt.Sprintf("Project not found %s", err)})
-		return nil, err
+		return nil, mysqlError("find project", err)
 	}
 
 	// get oids
@@ -179,6 +213,7 @@ func (m *MySQLMetaStore) findProject(projectName string) (*MetaProject, error) {
 
 	if err != nil {
 		logger.Log(kv{"fn": "findProject", "msg": fmt.Sprintf("Oid not found %s", err)})
+		return nil, mysqlError("find project", err)
 	}
 
 	defer rows.Close()
@@ -203,26 +238,33 @@ func (m *MySQLMetaStore) findProject(projectName string) (*MetaProject, error) {
 	return &project, nil
 }
 
-// Add oid to project
-func (m *MySQLMetaStore) addOidToProject(oid string, project string) error {
-	// Cannot bind on collections
-	var (
-		id   int64
-		name string
-	)
-	err := m.client.QueryRow("select * from projects where name = ?", project).Scan(&id, &name)
+// Add oid to project, unless it is already there
+func (m *MySQLMetaStore) addOidToProject(oid string, namespace, name string) error {
+	var id int64
+	err := m.client.QueryRow("select id from projects where namespace = ? and name = ?", namespace, name).Scan(&id)
+	if err != nil {
+		return mysqlError("add oid to project", err)
+	}
+	var count int64
+	err = m.client.QueryRow("select count(*) from oid_maps where oid = ? and projectID = ?", oid, id).Scan(&count)
+	if err != nil || count > 0 {
+		return mysqlError("add oid to project", err)
+	}
 	_, err = m.client.Exec("insert into oid_maps (oid, projectID) values (?, ?)", oid, id)
 	logger.Log(kv{"fn": "addOidToProject", "msg": err})
-	return err
+	return mysqlError("add oid to project", err)
 }
 
 // Find oid
 func (m *MySQLMetaStore) findOid(oid string) (*MetaObject, error) {
 	var mo MetaObject
-	err := m.client.QueryRow("select * from oids where oid = ?", oid).Scan(&mo.Oid, &mo.Size)
+	err := m.client.QueryRow("select oid, size from oids where oid = ?", oid).Scan(&mo.Oid, &mo.Size)
 
+	if err == sql.ErrNoRows {
+		return nil, errObjectNotFound
+	}
 	if err != nil {
-		return nil, err
+		return nil, mysqlError("find oid", err)
 	}
 
 	if mo.Oid == "" {
@@ -237,90 +279,212 @@ Put (HTTP PUT handler)
 create OID and map to projects
 */
 func (m *MySQLMetaStore) Put(v *RequestVars) (*MetaObject, error) {
-	if !m.authenticate(v.Authorization) {
-		logger.Log(kv{"fn": "mysql_meta_store", "msg": "Unauthorized"})
-		return nil, newAuthError()
+	if v.Repo != "" {
+		// find or create project
+		if _, err := m.findProject(v.Namespace, v.Repo); err == errProjectNotFound {
+			if err := m.createProject(v.Namespace, v.Repo); err != nil {
+				return nil, err
+			}
+		} else if err != nil {
+			return nil, err
+		}
 	}
 
-	if meta, err := m.Get(v); err == nil {
+	meta, err := m.findOid(v.Oid)
+	if err == nil {
 		meta.Existing = true
-		return meta, nil
+	} else if err == errObjectNotFound {
+		meta = &MetaObject{Oid: v.Oid, Size: v.Size, Existing: false}
+		if err := m.createOid(v.Oid, v.Size); err != nil {
+			return nil, err
+		}
+	} else {
+		return nil, err
 	}
-	var buf bytes.Buffer
-	enc := gob.NewEncoder(&buf)
-	meta := MetaObject{Oid: v.Oid, Size: v.Size, Existing: false}
-	err := enc.Encode(meta)
-	perror(m.createOid(v.Oid, v.Size))
 	if v.Repo != "" {
-		// find or create project
-		_, ferr := m.findProject(v.Repo)
-
-		if ferr != nil {
-			// project does not exist, create it
-			return nil, errProjectNotFound
+		// links existing oids into the project as well
+		if err := m.addOidToProject(v.Oid, v.Namespace, v.Repo); err != nil {
+			return nil, err
 		}
-		perror(m.addOidToProject(v.Oid, v.Repo))
-	}
-	if err != nil {
-		return nil, err
 	}
-	return &meta, nil
+	return meta, nil
 }
 
 /*
 Get (HTTP Get handler)
 */
 func (m *MySQLMetaStore) Get(v *RequestVars) (*MetaObject, error) {
-	if !m.authenticate(v.Authorization) {
-		logger.Log(kv{"fn": "mysql_meta_store", "msg": "Unauthorized"})
-		return nil, newAuthError()
-	}
-	r, err := m.findOid(v.Oid)
-
-	if err != nil {
-		return nil, err
+	// oids are only visible through the projects they belong to
+	var meta MetaObject
+	err := m.client.QueryRow(
+		"select oids.oid, oids.size from oids "+
+			"join oid_maps on oid_maps.oid = oids.oid "+
+			"join projects on projects.id = oid_maps.projectID "+
+			"where oids.oid = ? and projects.namespace = ? and projects.name = ? limit 1", v.Oid, v.Namespace, v.Repo).Scan(&meta.Oid, &meta.Size)
+	if err == sql.ErrNoRows {
+		return nil, errObjectNotFound
 	}
-	var buf bytes.Buffer
-	enc := gob.NewEncoder(&buf)
-	logger.Log(kv{"fn": "Get", "msg": r})
-	meta := MetaObject{Oid: r.Oid, Size: r.Size}
-	err = enc.Encode(meta)
 	if err != nil {
-		return nil, err
+		return nil, mysqlError("get", err)
 	}
+	logger.Log(kv{"fn": "Get", "msg": meta})
+	meta.ProjectNames = []string{v.Project()}
 	return &meta, nil
 }
 
+/*
+FindObject (find an oid, whatever projects it belongs to)
+*/
+func (m *MySQLMetaStore) FindObject(oid string) (*MetaObject, error) {
+	return m.findOid(oid)
+}
+
 /*
 AddUser (Add a new user)
-Not implemented in mysql_meta_store
+the password is stored as a bcrypt hash, existing users are left untouched
 */
 func (m *MySQLMetaStore) AddUser(user, pass string) error {
-	return errNotImplemented
+	if Config.Ldap.Enabled {
+		return errNotImplemented
+	}
+	if _, err := m.findUser(user); err == nil {
+		return nil
+	}
+	encryptedPass, err := encryptPass([]byte(pass))
+	if err != nil {
+		return err
+	}
+	_, err = m.client.Exec("insert into users (name, password) values (?, ?)", user, encryptedPass)
+	if err != nil {
+		logger.Log(kv{"fn": "AddUser", "msg": fmt.Sprintf("MySQL insert query failed with error %s", err)})
+	}
+	return mysqlError("add user", err)
 }
 
 /*
 AddProject (Add a new project)
+fails with errProjectExists when the project is already there
+*/
+func (m *MySQLMetaStore) AddProject(namespace, name string) error {
+	if _, err := m.projectID(namespace, name); err != errProjectNotFound {
+		if err == nil {
+			return errProjectExists
+		}
+		return err
+	}
+	return m.createProject(namespace, name)
+}
+
+/*
+RenameProject (move a project and its locks to newNamespace/newName)
+oid mappings follow the project id
 */
-func (m *MySQLMetaStore) AddProject(name string) error {
-	err := m.createProject(name)
-	return err
+func (m *MySQLMetaStore) RenameProject(namespace, name, newNamespace, newName string) error {
+	id, err := m.projectID(namespace, name)
+	if err != nil {
+		return err
+	}
+	if _, err := m.projectID(newNamespace, newName); err != errProjectNotFound {
+		if err == nil {
+			return errProjectExists
+		}
+		return err
+	}
+
+	tx, err := m.client.Begin()
+	if err != nil {
+		return mysqlError("rename project", err)
+	}
+	if _, err := tx.Exec("update projects set namespace = ?, name = ? where id = ?", newNamespace, newName, id); err != nil {
+		tx.Rollback()
+		logger.Log(kv{"fn": "RenameProject", "msg": fmt.Sprintf("MySQL update query failed with error %s", err)})
+		return mysqlError("rename project", err)
+	}
+	if _, err := tx.Exec("update locks set project = ? where project = ?", projectKey(newNamespace, newName), projectKey(namespace, name)); err != nil {
+		tx.Rollback()
+		logger.Log(kv{"fn": "RenameProject", "msg": fmt.Sprintf("MySQL update query failed with error %s", err)})
+		return mysqlError("rename project", err)
+	}
+	return mysqlError("rename project", tx.Commit())
+}
+
+// projectID returns the id of the project, or errProjectNotFound
+func (m *MySQLMetaStore) projectID(namespace, name string) (int64, error) {
+	var id int64
+	err := m.client.QueryRow("select id from projects where namespace = ? and name = ?", namespace, name).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, errProjectNotFound
+	}
+	return id, mysqlError("find project", err)
 }
 
 /*
 DeleteUser (Delete a user)
-Not implemented
 */
 func (m *MySQLMetaStore) DeleteUser(user string) error {
-	return errNotImplemented
+	if Config.Ldap.Enabled {
+		return errNotImplemented
+	}
+	// revoke the tokens first, so they stop working with the user gone
+	if _, err := m.client.Exec("delete from tokens where username = ?", user); err != nil {
+		return mysqlError("delete user", err)
+	}
+	_, err := m.client.Exec("delete from users where name = ?", user)
+	return mysqlError("delete user", err)
 }
 
 /*
 Users (get list of users)
-Not implemented
 */
 func (m *MySQLMetaStore) Users() ([]*MetaUser, error) {
-	return []*MetaUser{}, errNotImplemented
+	if Config.Ldap.Enabled {
+		return []*MetaUser{}, errNotImplemented
+	}
+	rows, err := m.client.Query("select name from users order by name")
+	if err != nil {
+		logger.Log(kv{"fn": "Users", "msg": fmt.Sprintf("MySQL select query failed with error %s", err)})
+		return nil, mysqlError("list users", err)
+	}
+	defer rows.Close()
+
+	users := make([]*MetaUser, 0)
+	for rows.Next() {
+		var user MetaUser
+		if err := rows.Scan(&user.Name); err != nil {
+			return nil, err
+		}
+		users = append(users, &user)
+	}
+	return users, rows.Err()
+}
+
+/*
+CheckPassword (report whether password is the password of the local user)
+*/
+func (m *MySQLMetaStore) CheckPassword(user, password string) (bool, error) {
+	mu, err := m.findUser(user)
+	if err == errUserNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return checkPass([]byte(mu.Password), []byte(password))
+}
+
+/*
+findUser (get a user and its password hash)
+*/
+func (m *MySQLMetaStore) findUser(user string) (*MetaUser, error) {
+	var mu MetaUser
+	err := m.client.QueryRow("select name, password from users where name = ?", user).Scan(&mu.Name, &mu.Password)
+	if err == sql.ErrNoRows {
+		return nil, errUserNotFound
+	}
+	if err != nil {
+		return nil, mysqlError("find user", err)
+	}
+	return &mu, nil
 }
 
 /*
@@ -348,39 +512,169 @@ func (m *MySQLMetaStore) Projects() ([]*MetaProject, error) {
 }
 
 /*
-Auth routine.  Requires an auth string like
-"Basic YWRtaW46YWRtaW4="
+DeleteObject (remove an oid and its project mappings)
+*/
+func (m *MySQLMetaStore) DeleteObject(oid string) error {
+	if _, err := m.findOid(oid); err != nil {
+		return err
+	}
+	if _, err := m.client.Exec("delete from oid_maps where oid = ?", oid); err != nil {
+		logger.Log(kv{"fn": "DeleteObject", "msg": fmt.Sprintf("MySQL delete query failed with error %s", err)})
+		return mysqlError("delete object", err)
+	}
+	_, err := m.client.Exec("delete from oids where oid = ?", oid)
+	return mysqlError("delete object", err)
+}
+
+/*
+DeleteProject (remove a project, its oid mappings and its locks)
+the oids are left for garbage collection
+*/
+func (m *MySQLMetaStore) DeleteProject(namespace, name string) error {
+	id, err := m.projectID(namespace, name)
+	if err != nil {
+		return err
+	}
+	for _, q := range []string{"delete from oid_maps where projectID = ?", "delete from projects where id = ?"} {
+		if _, err := m.client.Exec(q, id); err != nil {
+			logger.Log(kv{"fn": "DeleteProject", "msg": fmt.Sprintf("MySQL delete query failed with error %s", err)})
+			return mysqlError("delete project", err)
+		}
+	}
+	_, err = m.client.Exec("delete from locks where project = ?", projectKey(namespace, name))
+	return mysqlError("delete project", err)
+}
+
+/*
+AddLock (lock a path in a project)
+fails with errLockExists when the path is already locked
 */
-func (m *MySQLMetaStore) authenticate(authorization string) bool {
-	if Config.IsPublic() {
-		return true
+func (m *MySQLMetaStore) AddLock(v *RequestVars, lock *MetaLock) error {
+	lock.Project = v.Project()
+	_, err := m.client.Exec("insert into locks (id, project, path, owner, lockedAt) values (?, ?, ?, ?, ?)",
+		lock.Id, lock.Project, lock.Path, lock.Owner, lock.LockedAt)
+	if me, ok := err.(*mysql.MySQLError); ok && me.Number == mysqlErrDuplicateEntry {
+		return errLockExists
 	}
+	if err != nil {
+		logger.Log(kv{"fn": "AddLock", "msg": fmt.Sprintf("MySQL insert query failed with error %s", err)})
+	}
+	return mysqlError("add lock", err)
+}
 
-	if authorization == "" {
-		return false
+/*
+Locks (get all locks of a project)
+*/
+func (m *MySQLMetaStore) Locks(v *RequestVars) ([]*MetaLock, error) {
+	rows, err := m.client.Query("select id, path, owner, lockedAt from locks where project = ?", v.Project())
+	if err != nil {
+		logger.Log(kv{"fn": "Locks", "msg": fmt.Sprintf("MySQL select query failed with error %s", err)})
+		return nil, mysqlError("list locks", err)
 	}
+	defer rows.Close()
 
-	if !strings.HasPrefix(authorization, "Basic ") {
-		return false
+	var lockList []*MetaLock
+	for rows.Next() {
+		lock := &MetaLock{Project: v.Project()}
+		if err := rows.Scan(&lock.Id, &lock.Path, &lock.Owner, &lock.LockedAt); err != nil {
+			return nil, mysqlError("list locks", err)
+		}
+		lockList = append(lockList, lock)
 	}
+	return lockList, mysqlError("list locks", rows.Err())
+}
 
-	c, err := base64.URLEncoding.DecodeString(strings.TrimPrefix(authorization, "Basic "))
+/*
+DeleteLock (remove a lock from a project)
+*/
+func (m *MySQLMetaStore) DeleteLock(v *RequestVars, id string) error {
+	res, err := m.client.Exec("delete from locks where project = ? and id = ?", v.Project(), id)
 	if err != nil {
-		logger.Log(kv{"fn": "mysql_meta_store.authenticate", "msg": err.Error()})
-		return false
+		return mysqlError("delete lock", err)
 	}
-	cs := string(c)
-	i := strings.IndexByte(cs, ':')
-	if i < 0 {
-		return false
+	if n, _ := res.RowsAffected(); n == 0 {
+		return errLockNotFound
 	}
-	user, password := cs[:i], cs[i+1:]
+	return nil
+}
 
-	if Config.Ldap.Enabled {
-		return authenticateLdap(user, password)
+/*
+AddToken (store a personal access token, keyed by its hash)
+*/
+func (m *MySQLMetaStore) AddToken(token *MetaToken) error {
+	_, err := m.client.Exec("insert into tokens (hash, id, username, name, scope, projects, createdAt) values (?, ?, ?, ?, ?, ?, ?)",
+		token.Hash, token.Id, token.User, token.Name, token.Scope, strings.Join(token.Projects, ","), token.CreatedAt)
+	if err != nil {
+		logger.Log(kv{"fn": "AddToken", "msg": fmt.Sprintf("MySQL insert query failed with error %s", err)})
+	}
+	return mysqlError("add token", err)
+}
+
+/*
+Tokens (get the personal access tokens of a user, or of all users when user is empty)
+*/
+func (m *MySQLMetaStore) Tokens(user string) ([]*MetaToken, error) {
+	query := "select hash, id, username, name, scope, projects, createdAt from tokens"
+	var args []interface{}
+	if user != "" {
+		query += " where username = ?"
+		args = append(args, user)
+	}
+	rows, err := m.client.Query(query, args...)
+	if err != nil {
+		logger.Log(kv{"fn": "Tokens", "msg": fmt.Sprintf("MySQL select query failed with error %s", err)})
+		return nil, mysqlError("list tokens", err)
 	}
+	defer rows.Close()
+
+	var tokenList []*MetaToken
+	for rows.Next() {
+		token, err := scanToken(rows)
+		if err != nil {
+			return nil, err
+		}
+		tokenList = append(tokenList, token)
+	}
+	return tokenList, rows.Err()
+}
+
+/*
+FindToken (get the personal access token with the given hash)
+*/
+func (m *MySQLMetaStore) FindToken(hash string) (*MetaToken, error) {
+	row := m.client.QueryRow("select hash, id, username, name, scope, projects, createdAt from tokens where hash = ?", hash)
+	token, err := scanToken(row)
+	if err == sql.ErrNoRows {
+		return nil, errTokenNotFound
+	}
+	return token, mysqlError("find token", err)
+}
 
-	logger.Log(kv{"fn": "mysql_meta_store", "msg": "Authentication failed, please make sure LDAP is set to true"})
-	return false
+/*
+DeleteToken (revoke the personal access token with the given id)
+*/
+func (m *MySQLMetaStore) DeleteToken(id string) error {
+	res, err := m.client.Exec("delete from tokens where id = ?", id)
+	if err != nil {
+		return mysqlError("delete token", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return errTokenNotFound
+	}
+	return nil
+}
 
+/*
+scanToken (read a tokens row)
+*/
+func scanToken(row interface {
+	Scan(dest ...interface{}) error
+}) (*MetaToken, error) {
+	var token MetaToken
+	var projects string
+	if err := row.Scan(&token.Hash, &token.Id, &token.User, &token.Name, &token.Scope, &projects, &token.CreatedAt); err != nil {
+		return nil, err
+	}
+	token.Projects = splitProjects(projects)
+	return &token, nil
 }
@@ -18,8 +18,8 @@ func TestMySQLConfiguration(t *testing.T) {
 	}
 
 	mysqlStore, err := NewMySQLMetaStore()
-	if mysqlStore != nil {
-		t.Errorf("expected MySQL configration validation error, got : %s", err)
+	if mysqlStore != nil || err != errMissingParams {
+		t.Errorf("expected MySQL configration validation error, got : %v", err)
 	}
 }
 
@@ -29,7 +29,7 @@ func TestMySQLAddProjects(t *testing.T) {
 		t.Errorf(serr.Error())
 	}
 
-	err := metaStoreTestMySQL.AddProject(testRepo)
+	err := metaStoreTestMySQL.AddProject(testNamespace, testRepo)
 
 	if err != nil {
 		metaStoreTestMySQL.Close()
@@ -39,7 +39,7 @@ func TestMySQLAddProjects(t *testing.T) {
 
 func TestMySQLPutWithAuth(t *testing.T) {
 
-	meta, err := metaStoreTestMySQL.Put(&RequestVars{Authorization: testAuth, Oid: contentOid, Size: 42, Repo: testRepo})
+	meta, err := metaStoreTestMySQL.Put(&RequestVars{Authorization: testAuth, Oid: contentOid, Size: 42, Namespace: testNamespace, Repo: testRepo})
 	if err != nil {
 		metaStoreTestMySQL.Close()
 		t.Errorf("expected put to succeed, got : %s", err)
@@ -50,7 +50,7 @@ func TestMySQLPutWithAuth(t *testing.T) {
 		t.Errorf("expected meta to not have existed")
 	}
 
-	meta, err = metaStoreTestMySQL.Get(&RequestVars{Authorization: testAuth, Oid: contentOid})
+	meta, err = metaStoreTestMySQL.Get(&RequestVars{Authorization: testAuth, Oid: contentOid, Namespace: testNamespace, Repo: testRepo})
 	if err != nil {
 		metaStoreTestMySQL.Close()
 		t.Errorf("expected to be able to retreive new put, got : %s", err)
@@ -67,25 +67,15 @@ func TestMySQLPutWithAuth(t *testing.T) {
 	}
 }
 
-func TestMySQLPutWithoutAuth(t *testing.T) {
-
-	_, err := metaStoreTestMySQL.Put(&RequestVars{Authorization: badAuth, User: testUser, Oid: contentOid, Size: 42, Repo: testRepo})
-	if !isAuthError(err) {
-		metaStoreTestMySQL.Close()
-		t.Errorf("expected auth error, got: %s", err)
-	}
-
-}
-
 func TestMySQLGetWithAuth(t *testing.T) {
 
-	metaFail, err := metaStoreTestMySQL.Get(&RequestVars{Authorization: testAuth, Oid: noAuthOid})
+	metaFail, err := metaStoreTestMySQL.Get(&RequestVars{Authorization: testAuth, Oid: noAuthOid, Namespace: testNamespace, Repo: testRepo})
 	if err == nil {
 		metaStoreTestMySQL.Close()
 		t.Fatalf("Error Should not have access to OID: %s", metaFail.Oid)
 	}
 
-	meta, err := metaStoreTestMySQL.Get(&RequestVars{Authorization: testAuth, Oid: contentOid})
+	meta, err := metaStoreTestMySQL.Get(&RequestVars{Authorization: testAuth, Oid: contentOid, Namespace: testNamespace, Repo: testRepo})
 	if err != nil {
 		metaStoreTestMySQL.Close()
 		t.Fatalf("Error retreiving meta: %s", err)
@@ -102,12 +92,168 @@ func TestMySQLGetWithAuth(t *testing.T) {
 	}
 }
 
-func TestMySQLGetWithoutAuth(t *testing.T) {
+func TestMySQLGetFromOtherProject(t *testing.T) {
+	if err := metaStoreTestMySQL.AddProject(testNamespace, extraRepo); err != nil {
+		t.Fatalf("expected AddProject to succeed, got : %s", err)
+	}
 
-	_, err := metaStoreTestMySQL.Get(&RequestVars{Authorization: badAuth, Oid: noAuthOid})
-	if !isAuthError(err) {
-		metaStoreTestMySQL.Close()
-		t.Errorf("expected auth error, got: %s", err)
+	_, err := metaStoreTestMySQL.Get(&RequestVars{Authorization: testAuth, Oid: contentOid, Namespace: testNamespace, Repo: extraRepo})
+	if err != errObjectNotFound {
+		t.Errorf("expected object not found in %s, got: %v", extraRepo, err)
+	}
+
+	meta, err := metaStoreTestMySQL.Put(&RequestVars{Authorization: testAuth, Oid: contentOid, Size: 42, Namespace: testNamespace, Repo: extraRepo})
+	if err != nil {
+		t.Fatalf("expected put to succeed, got : %s", err)
+	}
+
+	if !meta.Existing {
+		t.Errorf("expected meta to exist")
+	}
+
+	if _, err := metaStoreTestMySQL.Get(&RequestVars{Authorization: testAuth, Oid: contentOid, Namespace: testNamespace, Repo: extraRepo}); err != nil {
+		t.Errorf("expected object to be linked into %s, got: %s", extraRepo, err)
+	}
+}
+
+func TestMySQLLocks(t *testing.T) {
+	rv := &RequestVars{Authorization: testAuth, Namespace: testNamespace, Repo: testRepo}
+	err := metaStoreTestMySQL.AddLock(rv, &MetaLock{Id: "lock1", Path: "a/b.bin", Owner: testUser})
+	if err != nil {
+		t.Errorf("expected AddLock to succeed, got : %s", err)
+	}
+
+	err = metaStoreTestMySQL.AddLock(rv, &MetaLock{Id: "lock2", Path: "a/b.bin", Owner: testUser})
+	if err != errLockExists {
+		t.Errorf("expected errLockExists, got : %v", err)
+	}
+
+	locks, err := metaStoreTestMySQL.Locks(rv)
+	if err != nil || len(locks) != 1 {
+		t.Errorf("expected 1 lock, got : %d %v", len(locks), err)
+	}
+
+	err = metaStoreTestMySQL.DeleteLock(rv, "lock1")
+	if err != nil {
+		t.Errorf("expected DeleteLock to succeed, got : %s", err)
+	}
+
+	err = metaStoreTestMySQL.DeleteLock(rv, "lock1")
+	if err != errLockNotFound {
+		t.Errorf("expected errLockNotFound, got : %v", err)
+	}
+}
+
+func TestMySQLDeleteProject(t *testing.T) {
+	if err := metaStoreTestMySQL.DeleteProject(testNamespace, extraRepo); err != nil {
+		t.Fatalf("expected DeleteProject to succeed, got : %s", err)
+	}
+
+	if _, err := metaStoreTestMySQL.Get(&RequestVars{Authorization: testAuth, Oid: contentOid, Namespace: testNamespace, Repo: extraRepo}); err != errObjectNotFound {
+		t.Errorf("expected object not found in %s, got: %v", extraRepo, err)
+	}
+
+	if _, err := metaStoreTestMySQL.Get(&RequestVars{Authorization: testAuth, Oid: contentOid, Namespace: testNamespace, Repo: testRepo}); err != nil {
+		t.Errorf("expected object to stay in %s, got: %s", testRepo, err)
+	}
+
+	if err := metaStoreTestMySQL.DeleteProject(testNamespace, extraRepo); err != errProjectNotFound {
+		t.Errorf("expected errProjectNotFound, got: %v", err)
+	}
+}
+
+func TestMySQLTokens(t *testing.T) {
+	testTokenStore(t, metaStoreTestMySQL)
+}
+
+func TestMySQLProjectConformance(t *testing.T) {
+	serr := setupMySQLMeta()
+	if serr != nil {
+		t.Fatalf(serr.Error())
+	}
+
+	testProjectStore(t, metaStoreTestMySQL)
+}
+
+func TestMySQLUsers(t *testing.T) {
+	serr := setupMySQLMeta()
+	if serr != nil {
+		t.Fatalf(serr.Error())
+	}
+	Config.Ldap.Enabled = false
+	defer func() { Config.Ldap.Enabled = true }()
+
+	if err := metaStoreTestMySQL.AddUser(testUser, testPass); err != nil {
+		t.Fatalf("expected AddUser to succeed, got: %s", err)
+	}
+	// adding an existing user keeps its password
+	if err := metaStoreTestMySQL.AddUser(testUser, "otherpass"); err != nil {
+		t.Errorf("expected AddUser of an existing user to succeed, got: %s", err)
+	}
+
+	if ok, err := metaStoreTestMySQL.CheckPassword(testUser, testPass); !ok || err != nil {
+		t.Errorf("expected the password to match, got: %t %v", ok, err)
+	}
+	if ok, _ := metaStoreTestMySQL.CheckPassword(testUser, "otherpass"); ok {
+		t.Errorf("expected a wrong password not to match")
+	}
+	if ok, err := metaStoreTestMySQL.CheckPassword("nobody", testPass); ok || err != nil {
+		t.Errorf("expected an unknown user not to match, got: %t %v", ok, err)
+	}
+
+	users, err := metaStoreTestMySQL.Users()
+	if err != nil || len(users) != 1 || users[0].Name != testUser {
+		t.Errorf("expected to list %s, got: %v %v", testUser, users, err)
+	}
+
+	if err := metaStoreTestMySQL.DeleteUser(testUser); err != nil {
+		t.Errorf("expected DeleteUser to succeed, got: %s", err)
+	}
+	if users, _ := metaStoreTestMySQL.Users(); len(users) != 0 {
+		t.Errorf("expected no users after delete, got: %d", len(users))
+	}
+}
+
+func TestMySQLUpgradeLegacyTables(t *testing.T) {
+	serr := setupMySQLMeta()
+	if serr != nil {
+		t.Fatalf(serr.Error())
+	}
+	defer func(namespace string) { Config.LegacyNamespace = namespace }(Config.LegacyNamespace)
+	Config.LegacyNamespace = testNamespace
+
+	// the tables gorp created before the schema was versioned
+	for _, q := range []string{
+		"drop table if exists oid_maps, oids, projects, locks, tokens, users, schema_version",
+		"create table projects (id bigint(20) not null auto_increment primary key, name varchar(255) unique) engine=InnoDB default charset=UTF8",
+		"create table oids (oid varchar(255) not null primary key, size bigint(20)) engine=InnoDB default charset=UTF8",
+		"create table oid_maps (oid varchar(255), projectID bigint(20)) engine=InnoDB default charset=UTF8",
+		"insert into projects (id, name) values (7, '" + testRepo + "')",
+		"insert into oids (oid, size) values ('" + contentOid + "', 42)",
+		"insert into oid_maps (oid, projectID) values ('" + contentOid + "', 7)",
+	} {
+		if _, err := metaStoreTestMySQL.client.Exec(q); err != nil {
+			t.Fatalf("expected %q to succeed, got: %s", q, err)
+		}
+	}
+	metaStoreTestMySQL.Close()
+
+	store, err := NewMySQLMetaStore()
+	if err != nil {
+		t.Fatalf("expected the legacy tables to be upgraded, got: %s", err)
+	}
+	metaStoreTestMySQL = store
+
+	meta, err := store.Get(&RequestVars{Authorization: testAuth, Oid: contentOid, Namespace: testNamespace, Repo: testRepo})
+	if err != nil || meta.Size != 42 {
+		t.Errorf("expected the object to be found in %s/%s, got: %v %v", testNamespace, testRepo, meta, err)
+	}
+	// the same repo name in another namespace is another project
+	if _, err := store.Put(&RequestVars{Authorization: testAuth, Oid: contentOid, Size: 42, Namespace: "other", Repo: testRepo}); err != nil {
+		t.Errorf("expected put to another namespace to succeed, got: %s", err)
+	}
+	if version, _ := schemaVersion(store.client); version != len(sqlMigrations(mysqlSerial, mysqlCatalog)) {
+		t.Errorf("expected the schema to be up to date, got version %d", version)
 	}
 }
 
@@ -135,6 +281,8 @@ func setupMySQLMeta() error {
 	mysqlStore.client.Exec("TRUNCATE TABLE oid_maps")
 	mysqlStore.client.Exec("TRUNCATE TABLE oids")
 	mysqlStore.client.Exec("TRUNCATE TABLE projects")
+	mysqlStore.client.Exec("TRUNCATE TABLE locks")
+	mysqlStore.client.Exec("TRUNCATE TABLE users")
 
 	return nil
 }
@@ -4,7 +4,6 @@ import (
 	"database/sql"
 	"fmt"
 	_ "github.com/go-sql-driver/mysql"
-	"gopkg.in/gorp.v1"
 	"strings"
 )
 
@@ -13,72 +12,58 @@ MySQLService struct
 */
 type MySQLService struct {
 	Client *sql.DB
-	Fail   bool
 }
 
-/*
-Projects table struct
-*/
-type Projects struct {
-	id   int64
-	name string
-}
+// mysqlSerial is the column definition of the generated project ids
+const mysqlSerial = "bigint not null primary key auto_increment"
 
-/*
-Oids table struct
-*/
-type Oids struct {
-	oid  string
-	size int64
-}
-
-/*
-OidMaps table struct
-*/
-type OidMaps struct {
-	oid       string
-	projectID int64
+// mysqlCatalog reads the schema of the database the connection uses
+var mysqlCatalog = sqlCatalog{
+	columns: "select column_name, is_nullable = 'YES' from information_schema.columns where table_schema = database() and table_name = ?",
+	indexes: "select distinct index_name from information_schema.statistics where table_schema = database() and table_name = ?",
 }
 
 /*
 NewMySQLSession (method used in mysql_meta_store.go)
-create requeired table and return sql client object
+bring the schema up to date and return sql client object
+fails with errMissingParams when the configuration is incomplete
 */
-func NewMySQLSession() *MySQLService {
-
-	validate := validateConfig()
-
-	if validate {
-		// Create MySQL Client
-		dqs := fmt.Sprintf("%s:%s@tcp(%s)/%s",
-			Config.MySQL.Username,
-			Config.MySQL.Password,
-			Config.MySQL.Host,
-			Config.MySQL.Database)
-
-		// Open connection
-		db, err := sql.Open("mysql", dqs)
-		dbMap := &gorp.DbMap{Db: db, Dialect: gorp.MySQLDialect{"InnoDB", "UTF8"}}
-		perror(createTables(dbMap))
-		perror(err)
-		return &MySQLService{Client: db}
+func NewMySQLSession() (*MySQLService, error) {
+	db, err := openMySQL()
+	if err != nil {
+		return nil, err
 	}
-
-	logger.Log(kv{"fn": "NewMySQLSession", "msg": "MySQL configuration validation failed"})
-	return &MySQLService{Fail: true}
+	if _, err := migrate(db, sqlMigrations(mysqlSerial, mysqlCatalog), questionMark); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &MySQLService{Client: db}, nil
 }
 
-func createTables(client *gorp.DbMap) error {
-	client.AddTableWithName(Projects{}, "projects").SetKeys(true, "id").ColMap("name").SetUnique(true)
-	client.AddTableWithName(Oids{}, "oids").SetKeys(false, "oid")
-	client.AddTableWithName(OidMaps{}, "oid_maps")
-	// dbmap.AddTableWithName(users{}, "users").SetKeys(false, "name")
-	err := client.CreateTablesIfNotExists()
+// openMySQL connects to the configured database, leaving its schema as it is
+func openMySQL() (*sql.DB, error) {
+	if !validateConfig() {
+		logger.Log(kv{"fn": "NewMySQLSession", "msg": "MySQL configuration validation failed"})
+		return nil, errMissingParams
+	}
+
+	// Create MySQL Client
+	dqs := fmt.Sprintf("%s:%s@tcp(%s)/%s?parseTime=true",
+		Config.MySQL.Username,
+		Config.MySQL.Password,
+		Config.MySQL.Host,
+		Config.MySQL.Database)
 
+	// Open connection
+	db, err := sql.Open("mysql", dqs)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, mysqlError("connect", err)
 	}
-	return nil
+	return db, nil
 }
 
 func validateConfig() bool {
@@ -0,0 +1,446 @@
+package main
+
+import (
+	"database/sql"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// postgresErrUniqueViolation is the PostgreSQL error code for a unique key violation
+const postgresErrUniqueViolation = "23505"
+
+/*
+PostgresMetaStore struct.
+*/
+type PostgresMetaStore struct {
+	postgresService *PostgresService
+	client          *sql.DB
+}
+
+/*
+NewPostgresMetaStore (method update the PostgresMetaStore struct)
+*/
+func NewPostgresMetaStore(postgresService ...*PostgresService) (*PostgresMetaStore, error) {
+	if len(postgresService) == 0 {
+		session, err := NewPostgresSession()
+		if err != nil {
+			return nil, err
+		}
+		postgresService = append(postgresService, session)
+	}
+
+	postgres := postgresService[0]
+	return &PostgresMetaStore{postgresService: postgres, client: postgres.Client}, nil
+}
+
+/*
+postgresError (wrap err in a store error, unavailable when PostgreSQL can't be reached or
+is starting or shutting down, so requests get a 503)
+*/
+func postgresError(op string, err error) error {
+	if err == nil || isStoreError(err) {
+		return err
+	}
+	// class 08 are connection exceptions, 57P01-57P03 the server going down or starting
+	if pe, ok := err.(*pq.Error); ok && (strings.HasPrefix(string(pe.Code), "08") || strings.HasPrefix(string(pe.Code), "57P0")) {
+		return newUnavailableError("postgres", op, err)
+	}
+	return newStoreError("postgres", op, err)
+}
+
+/*
+isUniqueViolation (report whether err is a duplicate key)
+*/
+func isUniqueViolation(err error) bool {
+	pe, ok := err.(*pq.Error)
+	return ok && pe.Code == postgresErrUniqueViolation
+}
+
+/*
+Close (method close postgres connection)
+*/
+func (p *PostgresMetaStore) Close() {
+	p.client.Close()
+}
+
+/*
+Put (create the oid, unless it exists, and link it into the project)
+projects are created on the first push
+*/
+func (p *PostgresMetaStore) Put(v *RequestVars) (*MetaObject, error) {
+	meta, err := p.findOid(v.Oid)
+	if err == errObjectNotFound {
+		meta = &MetaObject{Oid: v.Oid, Size: v.Size, Existing: false}
+		_, err = p.client.Exec("insert into oids (oid, size) values ($1, $2) on conflict (oid) do nothing", v.Oid, v.Size)
+		if err != nil {
+			return nil, postgresError("create oid", err)
+		}
+	} else if err != nil {
+		return nil, err
+	} else {
+		meta.Existing = true
+	}
+
+	if v.Repo != "" {
+		_, err := p.client.Exec("insert into projects (namespace, name) values ($1, $2) on conflict (namespace, name) do nothing", v.Namespace, v.Repo)
+		if err != nil {
+			return nil, postgresError("create project", err)
+		}
+		// links existing oids into the project as well
+		_, err = p.client.Exec("insert into oid_maps (oid, project_id) select $1, id from projects where namespace = $2 and name = $3 on conflict do nothing",
+			v.Oid, v.Namespace, v.Repo)
+		if err != nil {
+			return nil, postgresError("add oid to project", err)
+		}
+	}
+	return meta, nil
+}
+
+/*
+Get (find an oid through the project it belongs to)
+*/
+func (p *PostgresMetaStore) Get(v *RequestVars) (*MetaObject, error) {
+	var meta MetaObject
+	err := p.client.QueryRow(
+		"select oids.oid, oids.size from oids "+
+			"join oid_maps on oid_maps.oid = oids.oid "+
+			"join projects on projects.id = oid_maps.project_id "+
+			"where oids.oid = $1 and projects.namespace = $2 and projects.name = $3", v.Oid, v.Namespace, v.Repo).Scan(&meta.Oid, &meta.Size)
+	if err == sql.ErrNoRows {
+		return nil, errObjectNotFound
+	}
+	if err != nil {
+		return nil, postgresError("get", err)
+	}
+	meta.ProjectNames = []string{v.Project()}
+	return &meta, nil
+}
+
+/*
+FindObject (find an oid, whatever projects it belongs to)
+*/
+func (p *PostgresMetaStore) FindObject(oid string) (*MetaObject, error) {
+	return p.findOid(oid)
+}
+
+/*
+findOid (get an oid and its size)
+*/
+func (p *PostgresMetaStore) findOid(oid string) (*MetaObject, error) {
+	var meta MetaObject
+	err := p.client.QueryRow("select oid, size from oids where oid = $1", oid).Scan(&meta.Oid, &meta.Size)
+	if err == sql.ErrNoRows {
+		return nil, errObjectNotFound
+	}
+	if err != nil {
+		return nil, postgresError("find oid", err)
+	}
+	return &meta, nil
+}
+
+/*
+Objects (get all oids)
+*/
+func (p *PostgresMetaStore) Objects() ([]*MetaObject, error) {
+	rows, err := p.client.Query("select oid, size from oids order by oid")
+	if err != nil {
+		return nil, postgresError("list oids", err)
+	}
+	defer rows.Close()
+
+	objects := make([]*MetaObject, 0)
+	for rows.Next() {
+		var meta MetaObject
+		if err := rows.Scan(&meta.Oid, &meta.Size); err != nil {
+			return nil, postgresError("list oids", err)
+		}
+		objects = append(objects, &meta)
+	}
+	return objects, postgresError("list oids", rows.Err())
+}
+
+/*
+Projects (get all projects and their oids)
+*/
+func (p *PostgresMetaStore) Projects() ([]*MetaProject, error) {
+	rows, err := p.client.Query("select projects.namespace, projects.name, oid_maps.oid from projects " +
+		"left join oid_maps on oid_maps.project_id = projects.id order by projects.namespace, projects.name, oid_maps.oid")
+	if err != nil {
+		return nil, postgresError("list projects", err)
+	}
+	defer rows.Close()
+
+	projects := make([]*MetaProject, 0)
+	var project *MetaProject
+	for rows.Next() {
+		var namespace, name string
+		var oid sql.NullString
+		if err := rows.Scan(&namespace, &name, &oid); err != nil {
+			return nil, postgresError("list projects", err)
+		}
+		if project == nil || project.Namespace != namespace || project.Name != name {
+			project = &MetaProject{Namespace: namespace, Name: name}
+			projects = append(projects, project)
+		}
+		if oid.Valid {
+			project.Oids = append(project.Oids, oid.String)
+		}
+	}
+	return projects, postgresError("list projects", rows.Err())
+}
+
+/*
+DeleteObject (remove an oid, its project mappings go with it)
+*/
+func (p *PostgresMetaStore) DeleteObject(oid string) error {
+	res, err := p.client.Exec("delete from oids where oid = $1", oid)
+	if err != nil {
+		return postgresError("delete object", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return errObjectNotFound
+	}
+	return nil
+}
+
+/*
+AddProject (Add a new project)
+fails with errProjectExists when the project is already there
+*/
+func (p *PostgresMetaStore) AddProject(namespace, name string) error {
+	res, err := p.client.Exec("insert into projects (namespace, name) values ($1, $2) on conflict (namespace, name) do nothing", namespace, name)
+	if err != nil {
+		return postgresError("add project", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return errProjectExists
+	}
+	return nil
+}
+
+/*
+RenameProject (move a project and its locks to newNamespace/newName)
+oid mappings follow the project id
+*/
+func (p *PostgresMetaStore) RenameProject(namespace, name, newNamespace, newName string) error {
+	tx, err := p.client.Begin()
+	if err != nil {
+		return postgresError("rename project", err)
+	}
+	res, err := tx.Exec("update projects set namespace = $1, name = $2 where namespace = $3 and name = $4", newNamespace, newName, namespace, name)
+	if isUniqueViolation(err) {
+		tx.Rollback()
+		return errProjectExists
+	}
+	if err != nil {
+		tx.Rollback()
+		return postgresError("rename project", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		tx.Rollback()
+		return errProjectNotFound
+	}
+	if _, err := tx.Exec("update locks set project = $1 where project = $2", projectKey(newNamespace, newName), projectKey(namespace, name)); err != nil {
+		tx.Rollback()
+		return postgresError("rename project", err)
+	}
+	return postgresError("rename project", tx.Commit())
+}
+
+/*
+DeleteProject (remove a project, its oid mappings and its locks)
+the oids are left for garbage collection
+*/
+func (p *PostgresMetaStore) DeleteProject(namespace, name string) error {
+	res, err := p.client.Exec("delete from projects where namespace = $1 and name = $2", namespace, name)
+	if err != nil {
+		return postgresError("delete project", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return errProjectNotFound
+	}
+	_, err = p.client.Exec("delete from locks where project = $1", projectKey(namespace, name))
+	return postgresError("delete project", err)
+}
+
+/*
+AddUser (Add a new user)
+the password is stored as a bcrypt hash, existing users are left untouched
+*/
+func (p *PostgresMetaStore) AddUser(user, pass string) error {
+	if Config.Ldap.Enabled {
+		return errNotImplemented
+	}
+	encryptedPass, err := encryptPass([]byte(pass))
+	if err != nil {
+		return err
+	}
+	_, err = p.client.Exec("insert into users (name, password) values ($1, $2) on conflict (name) do nothing", user, encryptedPass)
+	return postgresError("add user", err)
+}
+
+/*
+DeleteUser (Delete a user)
+*/
+func (p *PostgresMetaStore) DeleteUser(user string) error {
+	if Config.Ldap.Enabled {
+		return errNotImplemented
+	}
+	// revoke the tokens first, so they stop working with the user gone
+	if _, err := p.client.Exec("delete from tokens where username = $1", user); err != nil {
+		return postgresError("delete user", err)
+	}
+	_, err := p.client.Exec("delete from users where name = $1", user)
+	return postgresError("delete user", err)
+}
+
+/*
+Users (get list of users)
+*/
+func (p *PostgresMetaStore) Users() ([]*MetaUser, error) {
+	if Config.Ldap.Enabled {
+		return []*MetaUser{}, errNotImplemented
+	}
+	rows, err := p.client.Query("select name from users order by name")
+	if err != nil {
+		return nil, postgresError("list users", err)
+	}
+	defer rows.Close()
+
+	users := make([]*MetaUser, 0)
+	for rows.Next() {
+		var user MetaUser
+		if err := rows.Scan(&user.Name); err != nil {
+			return nil, postgresError("list users", err)
+		}
+		users = append(users, &user)
+	}
+	return users, postgresError("list users", rows.Err())
+}
+
+/*
+CheckPassword (report whether password is the password of the local user)
+*/
+func (p *PostgresMetaStore) CheckPassword(user, password string) (bool, error) {
+	var hash string
+	err := p.client.QueryRow("select password from users where name = $1", user).Scan(&hash)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, postgresError("find user", err)
+	}
+	return checkPass([]byte(hash), []byte(password))
+}
+
+/*
+AddLock (lock a path in a project)
+fails with errLockExists when the path is already locked
+*/
+func (p *PostgresMetaStore) AddLock(v *RequestVars, lock *MetaLock) error {
+	lock.Project = v.Project()
+	_, err := p.client.Exec("insert into locks (id, project, path, owner, locked_at) values ($1, $2, $3, $4, $5)",
+		lock.Id, lock.Project, lock.Path, lock.Owner, lock.LockedAt)
+	if isUniqueViolation(err) {
+		return errLockExists
+	}
+	return postgresError("add lock", err)
+}
+
+/*
+Locks (get all locks of a project)
+*/
+func (p *PostgresMetaStore) Locks(v *RequestVars) ([]*MetaLock, error) {
+	rows, err := p.client.Query("select id, path, owner, locked_at from locks where project = $1 order by path", v.Project())
+	if err != nil {
+		return nil, postgresError("list locks", err)
+	}
+	defer rows.Close()
+
+	var lockList []*MetaLock
+	for rows.Next() {
+		lock := &MetaLock{Project: v.Project()}
+		if err := rows.Scan(&lock.Id, &lock.Path, &lock.Owner, &lock.LockedAt); err != nil {
+			return nil, postgresError("list locks", err)
+		}
+		lockList = append(lockList, lock)
+	}
+	return lockList, postgresError("list locks", rows.Err())
+}
+
+/*
+DeleteLock (remove a lock from a project)
+*/
+func (p *PostgresMetaStore) DeleteLock(v *RequestVars, id string) error {
+	res, err := p.client.Exec("delete from locks where project = $1 and id = $2", v.Project(), id)
+	if err != nil {
+		return postgresError("delete lock", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return errLockNotFound
+	}
+	return nil
+}
+
+/*
+AddToken (store a personal access token, keyed by its hash)
+*/
+func (p *PostgresMetaStore) AddToken(token *MetaToken) error {
+	_, err := p.client.Exec("insert into tokens (hash, id, username, name, scope, projects, created_at) values ($1, $2, $3, $4, $5, $6, $7)",
+		token.Hash, token.Id, token.User, token.Name, token.Scope, strings.Join(token.Projects, ","), token.CreatedAt)
+	return postgresError("add token", err)
+}
+
+/*
+Tokens (get the personal access tokens of a user, or of all users when user is empty)
+*/
+func (p *PostgresMetaStore) Tokens(user string) ([]*MetaToken, error) {
+	query := "select hash, id, username, name, scope, projects, created_at from tokens"
+	var args []interface{}
+	if user != "" {
+		query += " where username = $1"
+		args = append(args, user)
+	}
+	rows, err := p.client.Query(query+" order by created_at", args...)
+	if err != nil {
+		return nil, postgresError("list tokens", err)
+	}
+	defer rows.Close()
+
+	var tokenList []*MetaToken
+	for rows.Next() {
+		token, err := scanToken(rows)
+		if err != nil {
+			return nil, postgresError("list tokens", err)
+		}
+		tokenList = append(tokenList, token)
+	}
+	return tokenList, postgresError("list tokens", rows.Err())
+}
+
+/*
+FindToken (get the personal access token with the given hash)
+*/
+func (p *PostgresMetaStore) FindToken(hash string) (*MetaToken, error) {
+	row := p.client.QueryRow("select hash, id, username, name, scope, projects, created_at from tokens where hash = $1", hash)
+	token, err := scanToken(row)
+	if err == sql.ErrNoRows {
+		return nil, errTokenNotFound
+	}
+	return token, postgresError("find token", err)
+}
+
+/*
+DeleteToken (revoke the personal access token with the given id)
+*/
+func (p *PostgresMetaStore) DeleteToken(id string) error {
+	res, err := p.client.Exec("delete from tokens where id = $1", id)
+	if err != nil {
+		return postgresError("delete token", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return errTokenNotFound
+	}
+	return nil
+}
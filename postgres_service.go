@@ -0,0 +1,86 @@
+package main
+
+import (
+	"database/sql"
+	"net/url"
+	"strings"
+
+	_ "github.com/lib/pq"
+)
+
+/*
+PostgresService struct
+*/
+type PostgresService struct {
+	Client *sql.DB
+}
+
+/*
+postgresMigrations (the schema of the postgres meta store)
+append new migrations, never change the ones already released
+*/
+var postgresMigrations = []migration{
+	{1, "create projects, oids and users", []string{
+		"create table projects (id serial primary key, namespace text not null, name text not null, unique (namespace, name))",
+		"create table oids (oid text primary key, size bigint not null)",
+		"create table oid_maps (oid text not null references oids (oid) on delete cascade, project_id integer not null references projects (id) on delete cascade, primary key (oid, project_id))",
+		"create table users (name text primary key, password text not null)",
+	}, nil},
+	{2, "create locks", []string{
+		"create table locks (id text primary key, project text not null, path text not null, owner text not null, locked_at timestamp with time zone not null, unique (project, path))",
+	}, nil},
+	{3, "create access tokens", []string{
+		"create table tokens (hash text primary key, id text not null unique, username text not null, name text not null, scope text not null, projects text not null, created_at timestamp with time zone not null)",
+		"create index tokens_username on tokens (username)",
+	}, nil},
+}
+
+/*
+NewPostgresSession (method used in postgres_meta_store.go)
+connect and bring the schema up to date
+fails with errMissingParams when the configuration is incomplete
+*/
+func NewPostgresSession() (*PostgresService, error) {
+	db, err := openPostgres()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := migrate(db, postgresMigrations, dollarNumber); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &PostgresService{Client: db}, nil
+}
+
+// openPostgres connects to the configured database, leaving its schema as it is
+func openPostgres() (*sql.DB, error) {
+	if strings.TrimSpace(Config.Postgres.Host) == "" || strings.TrimSpace(Config.Postgres.Database) == "" {
+		logger.Log(kv{"fn": "NewPostgresSession", "msg": "Require Host and Database to connect PostgreSQL"})
+		return nil, errMissingParams
+	}
+
+	db, err := sql.Open("postgres", postgresDSN())
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, postgresError("connect", err)
+	}
+	return db, nil
+}
+
+/*
+postgresDSN (connection url built from Config.Postgres)
+*/
+func postgresDSN() string {
+	config := Config.Postgres
+	dsn := url.URL{Scheme: "postgres", Host: config.Host, Path: "/" + config.Database}
+	if config.Username != "" {
+		dsn.User = url.UserPassword(config.Username, config.Password)
+	}
+	if config.SSLMode != "" {
+		dsn.RawQuery = url.Values{"sslmode": {config.SSLMode}}.Encode()
+	}
+	return dsn.String()
+}
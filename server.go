@@ -2,12 +2,17 @@ package main
 
 import (
 	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net"
 	"net/http"
+	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gorilla/context"
 	"github.com/gorilla/mux"
@@ -26,29 +31,79 @@ type RequestVars struct {
 	Authorization string
 }
 
+// BatchVars contains the body of a batch api request.
 type BatchVars struct {
-	Objects []*RequestVars `json:"objects"`
+	Operation string         `json:"operation"`
+	Transfers []string       `json:"transfers,omitempty"`
+	Objects   []*RequestVars `json:"objects"`
+}
+
+// BatchResponse is the body of a batch api response.
+type BatchResponse struct {
+	Transfer string            `json:"transfer,omitempty"`
+	Objects  []*Representation `json:"objects"`
 }
 
 // MetaObject is object metadata as seen by the object and metadata stores.
 type MetaObject struct {
 	Oid          string   `json:"oid" cql:"oid"`
 	Size         int64    `json:"size "cql:"size"`
-	ProjectNames []string `json:"project_names"`
+	ProjectNames []string `json:"project_names"` // namespace/repo of each project holding the object
 	Existing     bool
 }
 
-// MetaProject is project metadata
+// MetaProject is project metadata. Projects are keyed by namespace and name.
 type MetaProject struct {
-	Name string   `json:"name" cql:"name"`
-	Oids []string `json:"oids" cql:"oids"`
+	Namespace string   `json:"namespace" cql:"namespace"`
+	Name      string   `json:"name" cql:"name"`
+	Oids      []string `json:"oids" cql:"oids"`
+}
+
+// Key returns the namespace/name the project is stored under
+func (p *MetaProject) Key() string {
+	return projectKey(p.Namespace, p.Name)
+}
+
+// projectKey joins a namespace and repo into the key a project is stored under
+func projectKey(namespace, repo string) string {
+	return fmt.Sprintf("%s/%s", namespace, repo)
+}
+
+// MetaLock is file lock metadata
+type MetaLock struct {
+	Id       string    `json:"id" cql:"id"`
+	Path     string    `json:"path" cql:"path"`
+	Owner    string    `json:"owner" cql:"owner"`
+	Project  string    `json:"project" cql:"project"`
+	LockedAt time.Time `json:"locked_at" cql:"locked_at"`
+}
+
+// MetaToken is a personal access token. Only the sha256 of the token is stored.
+type MetaToken struct {
+	Id        string    `json:"id" cql:"id"`
+	User      string    `json:"user" cql:"username"`
+	Name      string    `json:"name" cql:"name"`
+	Hash      string    `json:"-" cql:"hash"`
+	Scope     string    `json:"scope" cql:"scope"`       // read or write
+	Projects  []string  `json:"projects" cql:"projects"` // namespace/repo the token is limited to, all when empty
+	CreatedAt time.Time `json:"created_at" cql:"created_at"`
 }
 
 // Representation is object metadata as seen by clients of the lfs server.
+// The legacy api uses Links, the batch api uses Actions and Error.
 type Representation struct {
-	Oid   string           `json:"oid"`
-	Size  int64            `json:"size"`
-	Links map[string]*link `json:"_links"`
+	Oid           string           `json:"oid"`
+	Size          int64            `json:"size"`
+	Authenticated bool             `json:"authenticated,omitempty"`
+	Links         map[string]*link `json:"_links,omitempty"`
+	Actions       map[string]*link `json:"actions,omitempty"`
+	Error         *ObjectError     `json:"error,omitempty"`
+}
+
+// ObjectError is an error for a single object in a batch response.
+type ObjectError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
 }
 
 // MetaUser encapsulates information about a meta store user
@@ -61,19 +116,52 @@ type MetaUser struct {
 type GenericMetaStore interface {
 	Put(v *RequestVars) (*MetaObject, error)
 	Get(v *RequestVars) (*MetaObject, error)
+	FindObject(oid string) (*MetaObject, error)
 	Close()
 	DeleteUser(user string) error
 	AddUser(user, pass string) error
-	AddProject(projectName string) error
+	AddProject(namespace, name string) error
 	Users() ([]*MetaUser, error)
 	Objects() ([]*MetaObject, error)
 	Projects() ([]*MetaProject, error)
+	DeleteObject(oid string) error
+	DeleteProject(namespace, name string) error
+	RenameProject(namespace, name, newNamespace, newName string) error
+	AddLock(v *RequestVars, lock *MetaLock) error
+	Locks(v *RequestVars) ([]*MetaLock, error)
+	DeleteLock(v *RequestVars, id string) error
+	AddToken(token *MetaToken) error
+	Tokens(user string) ([]*MetaToken, error)
+	FindToken(hash string) (*MetaToken, error)
+	DeleteToken(id string) error
 }
 
 type GenericContentStore interface {
 	Get(meta *MetaObject) (io.Reader, error)
+	GetRange(meta *MetaObject, offset, length int64) (io.Reader, error)
 	Put(meta *MetaObject, r io.Reader) error
+	PutChunk(meta *MetaObject, offset int64, r io.Reader) (int64, error)
+	UploadOffset(meta *MetaObject) (int64, error)
 	Exists(meta *MetaObject) bool
+	Size(meta *MetaObject) (int64, error)
+	ModTime(meta *MetaObject) (time.Time, error)
+	Delete(meta *MetaObject) error
+}
+
+// PresigningContentStore is implemented by content stores that can hand out
+// time limited URLs for clients to transfer content without going through the server
+type PresigningContentStore interface {
+	PresignGet(meta *MetaObject, expires time.Time) (string, error)
+	PresignPut(meta *MetaObject, expires time.Time) (string, error)
+}
+
+// Project returns the namespace/repo key of the requested project, or an empty
+// string when the request is not for a project.
+func (v *RequestVars) Project() string {
+	if v.Repo == "" {
+		return ""
+	}
+	return projectKey(v.Namespace, v.Repo)
 }
 
 // ObjectLink builds a URL linking to the object.
@@ -87,33 +175,80 @@ func (v *RequestVars) ObjectLink() string {
 	return fmt.Sprintf("http://%s%s", Config.Host, path)
 }
 
+// RepoLink builds a URL linking to the lfs api of the repo.
+func (v *RequestVars) RepoLink() string {
+	path := fmt.Sprintf("/%s/%s", v.Namespace, v.Repo)
+
+	if Config.IsHTTPS() {
+		return fmt.Sprintf("%s://%s%s", Config.Scheme, Config.Host, path)
+	}
+
+	return fmt.Sprintf("http://%s%s", Config.Host, path)
+}
+
+// VerifyLink builds a URL linking to the verify endpoint of the repo.
+func (v *RequestVars) VerifyLink() string {
+	path := fmt.Sprintf("/%s/%s/objects/verify", v.Namespace, v.Repo)
+
+	if Config.IsHTTPS() {
+		return fmt.Sprintf("%s://%s%s", Config.Scheme, Config.Host, path)
+	}
+
+	return fmt.Sprintf("http://%s%s", Config.Host, path)
+}
+
+// TusLink builds a URL linking to the tus upload endpoint of the object.
+func (v *RequestVars) TusLink() string {
+	return v.ObjectLink() + "/tus"
+}
+
 // link provides a structure used to build a hypermedia representation of an HTTP link.
 type link struct {
-	Href   string            `json:"href"`
-	Header map[string]string `json:"header,omitempty"`
+	Href      string            `json:"href"`
+	Header    map[string]string `json:"header,omitempty"`
+	ExpiresAt *time.Time        `json:"expires_at,omitempty"`
 }
 
 // App links a Router, ContentStore, and MetaStore to provide the LFS server.
 type App struct {
-	router       *mux.Router
-	contentStore GenericContentStore
-	metaStore    GenericMetaStore
+	router        *mux.Router
+	contentStore  GenericContentStore
+	metaStore     GenericMetaStore
+	authenticator Authenticator
 }
 
 // NewApp creates a new App using the ContentStore and MetaStore provided
 func NewApp(content GenericContentStore, meta GenericMetaStore) *App {
 	app := &App{contentStore: content, metaStore: meta}
+	auth, err := newAuthenticator(Config.AuthProviders, meta)
+	if err != nil {
+		logger.Fatal(kv{"fn": "NewApp", "error": err.Error()})
+	}
+	if Config.AuthCacheTTL > 0 {
+		auth = newAuthCache(auth, Config.AuthCacheTTL)
+	}
+	app.authenticator = auth
+	if ldapGroupsEnabled() {
+		if _, err := parseLdapGroups(Config.Ldap.Groups); err != nil {
+			logger.Fatal(kv{"fn": "NewApp", "error": err.Error()})
+		}
+	}
 
 	r := mux.NewRouter()
 
 	r.HandleFunc("/{namespace}/{repo}/objects/batch", app.BatchHandler).Methods("POST").MatcherFunc(MetaMatcher)
+	r.HandleFunc("/{namespace}/{repo}/objects/verify", app.VerifyHandler).Methods("POST").MatcherFunc(MetaMatcher)
 	route := "/{namespace}/{repo}/objects/{oid}"
 	r.HandleFunc(route, app.GetContentHandler).Methods("GET", "HEAD").MatcherFunc(ContentMatcher)
 	r.HandleFunc(route, app.GetMetaHandler).Methods("GET", "HEAD").MatcherFunc(MetaMatcher)
 	r.HandleFunc("/search/{oid}", app.GetSearchHandler).Methods("GET")
 	r.HandleFunc(route, app.PutHandler).Methods("PUT").MatcherFunc(ContentMatcher)
+	r.HandleFunc(route+"/upload", app.UploadStatusHandler).Methods("GET").MatcherFunc(MetaMatcher)
+	app.addTus(r)
 
 	r.HandleFunc("/{namespace}/{repo}/objects", app.PostHandler).Methods("POST").MatcherFunc(MetaMatcher)
+	app.addLocks(r)
+	app.addTokens(r)
 	app.addMgmt(r)
 	app.router = r
 
@@ -138,35 +273,139 @@ func (a *App) Serve(l net.Listener) error {
 // GetContentHandler gets the content from the content store
 func (a *App) GetContentHandler(w http.ResponseWriter, r *http.Request) {
 	rv := unpack(r)
+	if !a.authorize(w, r, rv, "download") {
+		return
+	}
 	meta, err := a.metaStore.Get(rv)
 	if err != nil {
 		logger.Log(kv{"fn": "GetContentHandler", "error": err.Error()})
 		if isAuthError(err) {
 			requireAuth(w, r)
+		} else if isUnavailable(err) {
+			writeUnavailable(w, r, err)
 		} else {
 			writeStatus(w, r, 404)
 		}
 		return
 	}
 
-	content, err := a.contentStore.Get(meta)
+	etag := fmt.Sprintf(`"%s"`, meta.Oid)
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Accept-Ranges", "bytes")
+
+	start, length, status := int64(0), meta.Size, 200
+	if rh := r.Header.Get("Range"); rh != "" && (r.Header.Get("If-Range") == "" || r.Header.Get("If-Range") == etag) {
+		var ok bool
+		start, length, ok = parseRange(rh, meta.Size)
+		if !ok {
+			w.Header().Set("Content-Range", fmt.Sprintf("bytes */%d", meta.Size))
+			writeStatus(w, r, 416)
+			return
+		}
+		if length != meta.Size {
+			status = 206
+			w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, start+length-1, meta.Size))
+		}
+	}
+
+	if r.Method == "HEAD" {
+		if !a.contentStore.Exists(meta) {
+			writeStatus(w, r, 404)
+			return
+		}
+		w.Header().Set("Content-Length", strconv.FormatInt(length, 10))
+		w.WriteHeader(status)
+		logRequest(r, status)
+		return
+	}
+
+	var content io.Reader
+	if status == 206 {
+		content, err = a.contentStore.GetRange(meta, start, length)
+	} else {
+		content, err = a.contentStore.Get(meta)
+	}
 	if err != nil {
 		writeStatus(w, r, 404)
 		return
 	}
+	if c, ok := content.(io.Closer); ok {
+		defer c.Close()
+	}
 
+	w.Header().Set("Content-Length", strconv.FormatInt(length, 10))
+	w.WriteHeader(status)
 	io.Copy(w, content)
-	logRequest(r, 200)
+	logRequest(r, status)
+}
+
+// parseRange parses a single byte range request header for content of size bytes,
+// returning the offset and length to serve. A header asking for multiple ranges
+// is answered with the whole content. ok is false when the range is unsatisfiable.
+func parseRange(header string, size int64) (start, length int64, ok bool) {
+	if !strings.HasPrefix(header, "bytes=") {
+		return 0, size, true
+	}
+	spec := strings.TrimSpace(strings.TrimPrefix(header, "bytes="))
+	if strings.Contains(spec, ",") {
+		return 0, size, true
+	}
+
+	parts := strings.SplitN(spec, "-", 2)
+	if len(parts) != 2 {
+		return 0, 0, false
+	}
+	first, last := strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+
+	if first == "" {
+		// suffix range, the last n bytes
+		n, err := strconv.ParseInt(last, 10, 64)
+		if err != nil || n <= 0 {
+			return 0, 0, false
+		}
+		if n > size {
+			n = size
+		}
+		return size - n, n, true
+	}
+
+	start, err := strconv.ParseInt(first, 10, 64)
+	if err != nil || start < 0 || start >= size {
+		return 0, 0, false
+	}
+	end := size - 1
+	if last != "" {
+		end, err = strconv.ParseInt(last, 10, 64)
+		if err != nil || end < start {
+			return 0, 0, false
+		}
+		if end >= size {
+			end = size - 1
+		}
+	}
+	return start, end - start + 1, true
 }
 
 // GetSearchHandler (search handler used by pre-push hooks)
+// The project to search is given as ?project=namespace/repo
 func (a *App) GetSearchHandler(w http.ResponseWriter, r *http.Request) {
 	rv := unpack(r)
+	project := strings.SplitN(r.URL.Query().Get("project"), "/", 2)
+	if len(project) != 2 {
+		writeStatus(w, r, 404)
+		return
+	}
+	rv.Namespace, rv.Repo = project[0], project[1]
+	if !a.authorize(w, r, rv, "download") {
+		return
+	}
 	meta, err := a.metaStore.Get(rv)
 	logger.Log(kv{"fn": "GetSearchHandler", "meta": err})
 	if err != nil {
 		if isAuthError(err) {
 			requireAuth(w, r)
+		} else if isUnavailable(err) {
+			writeUnavailable(w, r, err)
 		} else {
 			writeStatus(w, r, 404)
 		}
@@ -200,10 +439,15 @@ func readDir(path string) []string {
 // GetMetaHandler retrieves metadata about the object
 func (a *App) GetMetaHandler(w http.ResponseWriter, r *http.Request) {
 	rv := unpack(r)
+	if !a.authorize(w, r, rv, "download") {
+		return
+	}
 	meta, err := a.metaStore.Get(rv)
 	if err != nil {
 		if isAuthError(err) {
 			requireAuth(w, r)
+		} else if isUnavailable(err) {
+			writeUnavailable(w, r, err)
 		} else {
 			writeStatus(w, r, 404)
 		}
@@ -223,11 +467,16 @@ func (a *App) GetMetaHandler(w http.ResponseWriter, r *http.Request) {
 // PostHandler instructs the client how to upload data
 func (a *App) PostHandler(w http.ResponseWriter, r *http.Request) {
 	rv := unpack(r)
+	if !a.authorize(w, r, rv, "push") {
+		return
+	}
 	meta, err := a.metaStore.Put(rv)
 
 	if err != nil {
 		if isAuthError(err) {
 			requireAuth(w, r)
+		} else if isUnavailable(err) {
+			writeUnavailable(w, r, err)
 		} else {
 			writeStatus(w, r, 404)
 		}
@@ -251,54 +500,232 @@ func (a *App) PostHandler(w http.ResponseWriter, r *http.Request) {
 func (a *App) BatchHandler(w http.ResponseWriter, r *http.Request) {
 	bv := unpackbatch(r)
 
+	if bv.Operation != "upload" && bv.Operation != "download" {
+		writeStatus(w, r, 422)
+		return
+	}
+
+	action := "download"
+	if bv.Operation == "upload" {
+		action = "push"
+	}
+	if !a.authorize(w, r, unpackVars(r), action) {
+		return
+	}
+
+	transfer := a.transfer(bv)
 	var responseObjects []*Representation
 
 	// Create a response object
 	for _, object := range bv.Objects {
-		meta, err := a.metaStore.Get(object)
-		if err == nil { // Object is found
-			responseObjects = append(responseObjects, a.Represent(object, meta, true, false))
-			continue
+		var rep *Representation
+		var err error
+		if bv.Operation == "upload" {
+			rep, err = a.batchUpload(object, transfer)
+		} else {
+			rep, err = a.batchDownload(object)
 		}
-
 		if isAuthError(err) {
 			requireAuth(w, r)
 			return
 		}
-
-		// Object is not found
-		meta, err = a.metaStore.Put(object)
-		if err == nil {
-			responseObjects = append(responseObjects, a.Represent(object, meta, meta.Existing, true))
+		if isUnavailable(err) {
+			writeUnavailable(w, r, err)
+			return
 		}
+		responseObjects = append(responseObjects, rep)
 	}
 
 	w.Header().Set("Content-Type", metaMediaType)
 
-	type ro struct {
-		Objects []*Representation `json:"objects"`
-	}
-
-	respobj := &ro{responseObjects}
+	respobj := &BatchResponse{Transfer: transfer, Objects: responseObjects}
 
 	enc := json.NewEncoder(w)
 	enc.Encode(respobj)
 	logRequest(r, 200)
 }
 
+// batchDownload builds the batch representation for an object that is to be downloaded.
+// Only auth and unavailable store errors are returned, all other errors are reported on the object.
+func (a *App) batchDownload(rv *RequestVars) (*Representation, error) {
+	meta, err := a.metaStore.Get(rv)
+	if err != nil {
+		if isAuthError(err) || isUnavailable(err) {
+			return nil, err
+		}
+		return objectError(rv, 404, "Object does not exist"), nil
+	}
+
+	if !a.contentStore.Exists(meta) {
+		return objectError(rv, 410, "Object content is no longer available"), nil
+	}
+
+	return a.RepresentBatch(rv, meta, true, false), nil
+}
+
+// transfer picks the transfer adapter for a batch request. The client's list is in
+// order of preference; tus is only offered for uploads that go through this server.
+func (a *App) transfer(bv *BatchVars) string {
+	if bv.Operation != "upload" || a.directLinks() {
+		return "basic"
+	}
+	for _, t := range bv.Transfers {
+		if t == "tus" || t == "basic" {
+			return t
+		}
+	}
+	return "basic"
+}
+
+// batchUpload builds the batch representation for an object that is to be uploaded.
+// Objects that are already in the project and the content store have no actions.
+// Objects other projects hold are pending, they are only linked into the project
+// once their content has been uploaded again, see receivePending.
+// Only auth and unavailable store errors are returned, all other errors are reported on the object.
+func (a *App) batchUpload(rv *RequestVars, transfer string) (*Representation, error) {
+	if !validOid(rv.Oid) {
+		return objectError(rv, 422, "Invalid oid"), nil
+	}
+	if rv.Size < 0 {
+		return objectError(rv, 422, "Invalid size"), nil
+	}
+
+	meta, pending, err := a.uploadMeta(rv)
+	if err == errObjectNotFound {
+		// new objects are created first, another project may create the same oid meanwhile
+		meta, err = a.metaStore.Put(&RequestVars{Oid: rv.Oid, Size: rv.Size})
+		if err == nil && meta.Existing {
+			pending = true
+		} else if err == nil {
+			meta, err = a.metaStore.Put(rv)
+		}
+	}
+	if err != nil {
+		if isAuthError(err) || isUnavailable(err) {
+			return nil, err
+		}
+		logger.Log(kv{"fn": "batchUpload", "error": err.Error()})
+		return objectError(rv, 500, err.Error()), nil
+	}
+
+	if !pending && a.contentStore.Exists(meta) {
+		return a.RepresentBatch(rv, meta, false, false), nil
+	}
+	rep := a.RepresentBatch(rv, meta, false, true)
+	if transfer == "tus" {
+		rep.Actions["upload"].Href = rv.TusLink()
+	} else if pending && a.directLinks() {
+		// the server has to see the content of objects other projects hold
+		rep.Actions["upload"] = &link{Href: rv.ObjectLink(), Header: objectHeader(rv, contentMediaType)}
+	}
+	return rep, nil
+}
+
+// VerifyHandler confirms that an uploaded object made it into the content store
+// with the size recorded in the meta store
+func (a *App) VerifyHandler(w http.ResponseWriter, r *http.Request) {
+	rv := unpack(r)
+	if !a.authorize(w, r, rv, "push") {
+		return
+	}
+	meta, err := a.metaStore.Get(rv)
+	if err != nil {
+		if isAuthError(err) {
+			requireAuth(w, r)
+		} else if isUnavailable(err) {
+			writeUnavailable(w, r, err)
+		} else {
+			writeStatus(w, r, 404)
+		}
+		return
+	}
+
+	if !a.contentStore.Exists(meta) {
+		writeStatus(w, r, 404)
+		return
+	}
+
+	size, err := a.contentStore.Size(meta)
+	if err != nil {
+		logger.Log(kv{"fn": "VerifyHandler", "error": err.Error()})
+		writeStatus(w, r, 404)
+		return
+	}
+
+	if size != meta.Size || rv.Size != meta.Size {
+		logger.Log(kv{"fn": "VerifyHandler", "oid": meta.Oid, "msg": errSizeMismatch.Error()})
+		writeStatus(w, r, 422)
+		return
+	}
+
+	// presigned uploads go straight to S3, so the content is only hashed here
+	if a.directLinks() {
+		if err := a.verifyContent(meta); err == errHashMismatch {
+			writeStatusMessage(w, r, 422, err.Error())
+			return
+		} else if err != nil {
+			logger.Log(kv{"fn": "VerifyHandler", "oid": meta.Oid, "error": err.Error()})
+			writeStatus(w, r, 500)
+			return
+		}
+	}
+
+	writeStatus(w, r, 200)
+}
+
+// verifyContent hashes the stored content of meta, deleting it when it doesn't match the
+// oid so the client can upload it again
+func (a *App) verifyContent(meta *MetaObject) error {
+	content, err := a.contentStore.Get(meta)
+	if err != nil {
+		return err
+	}
+	if c, ok := content.(io.Closer); ok {
+		defer c.Close()
+	}
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, content); err != nil {
+		return err
+	}
+	if hex.EncodeToString(hash.Sum(nil)) == meta.Oid {
+		return nil
+	}
+
+	logger.Log(kv{"fn": "verifyContent", "oid": meta.Oid, "msg": errHashMismatch.Error()})
+	if err := a.contentStore.Delete(meta); err != nil {
+		logger.Log(kv{"fn": "verifyContent", "oid": meta.Oid, "error": err.Error()})
+	}
+	return errHashMismatch
+}
+
 // PutHandler receives data from the client and puts it into the content store
 func (a *App) PutHandler(w http.ResponseWriter, r *http.Request) {
 	rv := unpack(r)
-	meta, err := a.metaStore.Get(rv)
+	if !a.authorize(w, r, rv, "push") {
+		return
+	}
+	meta, pending, err := a.uploadMeta(rv)
 	if err != nil {
 		if isAuthError(err) {
 			requireAuth(w, r)
+		} else if isUnavailable(err) {
+			writeUnavailable(w, r, err)
 		} else {
 			writeStatus(w, r, 404)
 		}
 		return
 	}
 
+	if pending {
+		a.putPending(w, r, rv, meta)
+		return
+	}
+	if r.Header.Get("Content-Range") != "" {
+		a.putChunk(w, r, meta)
+		return
+	}
+
 	if err := a.contentStore.Put(meta, r.Body); err != nil {
 		w.WriteHeader(500)
 		fmt.Fprintf(w, `{"message":"%s"}`, err)
@@ -311,25 +738,103 @@ func (a *App) PutHandler(w http.ResponseWriter, r *http.Request) {
 // Represent takes a RequestVars and Meta and turns it into a Representation suitable
 // for json encoding
 func (a *App) Represent(rv *RequestVars, meta *MetaObject, download, upload bool) *Representation {
-	rep := &Representation{
+	return &Representation{
 		Oid:   meta.Oid,
 		Size:  meta.Size,
-		Links: make(map[string]*link),
+		Links: a.links(rv, meta, download, upload),
+	}
+}
+
+// RepresentBatch takes a RequestVars and Meta and turns it into a batch api Representation
+// suitable for json encoding
+func (a *App) RepresentBatch(rv *RequestVars, meta *MetaObject, download, upload bool) *Representation {
+	rep := &Representation{
+		Oid:           meta.Oid,
+		Size:          meta.Size,
+		Authenticated: !Config.IsPublic(),
+	}
+	if download || upload {
+		rep.Actions = a.links(rv, meta, download, upload)
+	}
+	return rep
+}
+
+func (a *App) links(rv *RequestVars, meta *MetaObject, download, upload bool) map[string]*link {
+	links := make(map[string]*link)
+
+	header := objectHeader(rv, contentMediaType)
+	if download {
+		links["download"] = &link{Href: rv.ObjectLink(), Header: header}
+		if l := a.directLink(meta, "download"); l != nil {
+			links["download"] = l
+		}
+	}
+
+	if upload {
+		links["upload"] = &link{Href: rv.ObjectLink(), Header: header}
+		if l := a.directLink(meta, "upload"); l != nil {
+			links["upload"] = l
+		}
+
+		links["verify"] = &link{Href: rv.VerifyLink(), Header: objectHeader(rv, metaMediaType)}
 	}
+	return links
+}
 
+// objectHeader returns the headers of a link back to this server
+func objectHeader(rv *RequestVars, accept string) map[string]string {
 	header := make(map[string]string)
-	header["Accept"] = contentMediaType
+	header["Accept"] = accept
 	if !Config.IsPublic() {
 		header["Authorization"] = rv.Authorization
 	}
-	if download {
-		rep.Links["download"] = &link{Href: rv.ObjectLink(), Header: header}
+	return header
+}
+
+// directLink returns a presigned link straight to the content store when
+// Aws.DirectLinks is enabled and the store supports it, or nil
+func (a *App) directLink(meta *MetaObject, action string) *link {
+	if !a.directLinks() {
+		return nil
 	}
+	store := a.contentStore.(PresigningContentStore)
 
-	if upload {
-		rep.Links["upload"] = &link{Href: rv.ObjectLink(), Header: header}
+	expires := time.Now().Add(Config.Aws.LinkTTL).UTC()
+	var href string
+	var err error
+	header := make(map[string]string)
+	if action == "upload" {
+		href, err = store.PresignPut(meta, expires)
+		header["Content-Type"] = ContentType
+	} else {
+		href, err = store.PresignGet(meta, expires)
 	}
-	return rep
+	if err != nil {
+		logger.Log(kv{"fn": "directLink", "error": err.Error()})
+		return nil
+	}
+	return &link{Href: href, Header: header, ExpiresAt: &expires}
+}
+
+// directLinks reports whether clients are linked straight to the content store
+func (a *App) directLinks() bool {
+	_, ok := a.contentStore.(PresigningContentStore)
+	return ok && Config.Aws.DirectLinks
+}
+
+func objectError(rv *RequestVars, code int, message string) *Representation {
+	return &Representation{
+		Oid:   rv.Oid,
+		Size:  rv.Size,
+		Error: &ObjectError{Code: code, Message: message},
+	}
+}
+
+var oidPattern = regexp.MustCompile("^[0-9a-f]{64}$")
+
+// validOid returns true if the oid is a lowercase hex encoded sha256
+func validOid(oid string) bool {
+	return oidPattern.MatchString(oid)
 }
 
 // ContentMatcher provides a mux.MatcherFunc that only allows requests that contain
@@ -356,6 +861,7 @@ func unpack(r *http.Request) *RequestVars {
 		Oid:           vars["oid"],
 		Authorization: r.Header.Get("Authorization"),
 	}
+	rv.User, rv.Password, _ = r.BasicAuth()
 
 	if r.Method == "POST" { // Maybe also check if +json
 		var p RequestVars
@@ -372,6 +878,18 @@ func unpack(r *http.Request) *RequestVars {
 	return rv
 }
 
+// unpackVars reads the routing variables and credentials, leaving the body alone
+func unpackVars(r *http.Request) *RequestVars {
+	vars := mux.Vars(r)
+	rv := &RequestVars{
+		Namespace:     vars["namespace"],
+		Repo:          vars["repo"],
+		Authorization: r.Header.Get("Authorization"),
+	}
+	rv.User, rv.Password, _ = r.BasicAuth()
+	return rv
+}
+
 // TODO cheap hack, unify with unpack
 func unpackbatch(r *http.Request) *BatchVars {
 	vars := mux.Vars(r)
@@ -388,18 +906,22 @@ func unpackbatch(r *http.Request) *BatchVars {
 		bv.Objects[i].Namespace = vars["namespace"]
 		bv.Objects[i].Repo = vars["repo"]
 		bv.Objects[i].Authorization = r.Header.Get("Authorization")
+		bv.Objects[i].User, bv.Objects[i].Password, _ = r.BasicAuth()
 	}
 
 	return &bv
 }
 
 func writeStatus(w http.ResponseWriter, r *http.Request, status int) {
-	message := http.StatusText(status)
+	writeStatusMessage(w, r, status, http.StatusText(status))
+}
 
+func writeStatusMessage(w http.ResponseWriter, r *http.Request, status int, message string) {
 	mediaParts := strings.Split(r.Header.Get("Accept"), ";")
 	mt := mediaParts[0]
 	if strings.HasSuffix(mt, "+json") {
-		message = `{"message":"` + message + `"}`
+		b, _ := json.Marshal(map[string]string{"message": message})
+		message = string(b)
 	}
 
 	w.WriteHeader(status)
@@ -421,6 +943,27 @@ func isAuthError(err error) bool {
 	return false
 }
 
+func isUnavailable(err error) bool {
+	type unavailable interface {
+		Unavailable() bool
+	}
+	if ue, ok := err.(unavailable); ok {
+		return ue.Unavailable()
+	}
+	return false
+}
+
+// writeUnavailable answers a request that failed because the meta store is down with a
+// 503 and a JSON message, whatever the client accepts
+func writeUnavailable(w http.ResponseWriter, r *http.Request, err error) {
+	logger.Log(kv{"fn": "writeUnavailable", "url": r.URL, "error": err.Error()})
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Retry-After", "5")
+	w.WriteHeader(503)
+	json.NewEncoder(w).Encode(map[string]string{"message": "Meta store unavailable, try again later"})
+	logRequest(r, 503)
+}
+
 func requireAuth(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Lfs-Authenticate", "Basic realm=lfs-server-go")
 	writeStatus(w, r, 401)
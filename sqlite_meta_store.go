@@ -0,0 +1,473 @@
+package main
+
+import (
+	"database/sql"
+	"strings"
+
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+/*
+SQLiteMetaStore struct.
+*/
+type SQLiteMetaStore struct {
+	sqliteService *SQLiteService
+	client        *sql.DB
+}
+
+/*
+NewSQLiteMetaStore (method update the SQLiteMetaStore struct)
+*/
+func NewSQLiteMetaStore(sqliteService ...*SQLiteService) (*SQLiteMetaStore, error) {
+	if len(sqliteService) == 0 {
+		session, err := NewSQLiteSession()
+		if err != nil {
+			return nil, err
+		}
+		sqliteService = append(sqliteService, session)
+	}
+
+	sqlite := sqliteService[0]
+	return &SQLiteMetaStore{sqliteService: sqlite, client: sqlite.Client}, nil
+}
+
+/*
+sqliteError (wrap err in a store error, unavailable when the database is busy or locked,
+so requests get a 503)
+*/
+func sqliteError(op string, err error) error {
+	if err == nil || isStoreError(err) {
+		return err
+	}
+	if se, ok := err.(*sqlite.Error); ok {
+		// extended codes keep the primary code in the low byte
+		if code := se.Code() & 0xff; code == sqlite3.SQLITE_BUSY || code == sqlite3.SQLITE_LOCKED {
+			return newUnavailableError("sqlite", op, err)
+		}
+	}
+	return newStoreError("sqlite", op, err)
+}
+
+/*
+isSQLiteConstraint (report whether err is a duplicate key)
+*/
+func isSQLiteConstraint(err error) bool {
+	se, ok := err.(*sqlite.Error)
+	return ok && (se.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE || se.Code() == sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY)
+}
+
+/*
+Close (method close the database file)
+*/
+func (s *SQLiteMetaStore) Close() {
+	s.client.Close()
+}
+
+/*
+Put (create the oid, unless it exists, and link it into the project)
+projects are created on the first push
+*/
+func (s *SQLiteMetaStore) Put(v *RequestVars) (*MetaObject, error) {
+	meta, err := s.findOid(v.Oid)
+	if err == errObjectNotFound {
+		meta = &MetaObject{Oid: v.Oid, Size: v.Size, Existing: false}
+		_, err = s.client.Exec("insert into oids (oid, size) values (?, ?) on conflict (oid) do nothing", v.Oid, v.Size)
+		if err != nil {
+			return nil, sqliteError("create oid", err)
+		}
+	} else if err != nil {
+		return nil, err
+	} else {
+		meta.Existing = true
+	}
+
+	if v.Repo != "" {
+		_, err := s.client.Exec("insert into projects (namespace, name) values (?, ?) on conflict (namespace, name) do nothing", v.Namespace, v.Repo)
+		if err != nil {
+			return nil, sqliteError("create project", err)
+		}
+		// links existing oids into the project as well
+		_, err = s.client.Exec("insert into oid_maps (oid, projectID) select ?, id from projects where namespace = ? and name = ? "+
+			"and not exists (select 1 from oid_maps where oid_maps.oid = ? and oid_maps.projectID = projects.id)",
+			v.Oid, v.Namespace, v.Repo, v.Oid)
+		if err != nil {
+			return nil, sqliteError("add oid to project", err)
+		}
+	}
+	return meta, nil
+}
+
+/*
+Get (find an oid through the project it belongs to)
+*/
+func (s *SQLiteMetaStore) Get(v *RequestVars) (*MetaObject, error) {
+	var meta MetaObject
+	err := s.client.QueryRow(
+		"select oids.oid, oids.size from oids "+
+			"join oid_maps on oid_maps.oid = oids.oid "+
+			"join projects on projects.id = oid_maps.projectID "+
+			"where oids.oid = ? and projects.namespace = ? and projects.name = ? limit 1", v.Oid, v.Namespace, v.Repo).Scan(&meta.Oid, &meta.Size)
+	if err == sql.ErrNoRows {
+		return nil, errObjectNotFound
+	}
+	if err != nil {
+		return nil, sqliteError("get", err)
+	}
+	meta.ProjectNames = []string{v.Project()}
+	return &meta, nil
+}
+
+/*
+FindObject (find an oid, whatever projects it belongs to)
+*/
+func (s *SQLiteMetaStore) FindObject(oid string) (*MetaObject, error) {
+	return s.findOid(oid)
+}
+
+/*
+findOid (get an oid and its size)
+*/
+func (s *SQLiteMetaStore) findOid(oid string) (*MetaObject, error) {
+	var meta MetaObject
+	err := s.client.QueryRow("select oid, size from oids where oid = ?", oid).Scan(&meta.Oid, &meta.Size)
+	if err == sql.ErrNoRows {
+		return nil, errObjectNotFound
+	}
+	if err != nil {
+		return nil, sqliteError("find oid", err)
+	}
+	return &meta, nil
+}
+
+/*
+Objects (get all oids)
+*/
+func (s *SQLiteMetaStore) Objects() ([]*MetaObject, error) {
+	rows, err := s.client.Query("select oid, size from oids order by oid")
+	if err != nil {
+		return nil, sqliteError("list oids", err)
+	}
+	defer rows.Close()
+
+	objects := make([]*MetaObject, 0)
+	for rows.Next() {
+		var meta MetaObject
+		if err := rows.Scan(&meta.Oid, &meta.Size); err != nil {
+			return nil, sqliteError("list oids", err)
+		}
+		objects = append(objects, &meta)
+	}
+	return objects, sqliteError("list oids", rows.Err())
+}
+
+/*
+Projects (get all projects and their oids)
+*/
+func (s *SQLiteMetaStore) Projects() ([]*MetaProject, error) {
+	rows, err := s.client.Query("select projects.namespace, projects.name, oid_maps.oid from projects " +
+		"left join oid_maps on oid_maps.projectID = projects.id order by projects.namespace, projects.name, oid_maps.oid")
+	if err != nil {
+		return nil, sqliteError("list projects", err)
+	}
+	defer rows.Close()
+
+	projects := make([]*MetaProject, 0)
+	var project *MetaProject
+	for rows.Next() {
+		var namespace, name string
+		var oid sql.NullString
+		if err := rows.Scan(&namespace, &name, &oid); err != nil {
+			return nil, sqliteError("list projects", err)
+		}
+		if project == nil || project.Namespace != namespace || project.Name != name {
+			project = &MetaProject{Namespace: namespace, Name: name}
+			projects = append(projects, project)
+		}
+		if oid.Valid {
+			project.Oids = append(project.Oids, oid.String)
+		}
+	}
+	return projects, sqliteError("list projects", rows.Err())
+}
+
+/*
+DeleteObject (remove an oid and its project mappings)
+*/
+func (s *SQLiteMetaStore) DeleteObject(oid string) error {
+	tx, err := s.client.Begin()
+	if err != nil {
+		return sqliteError("delete object", err)
+	}
+	res, err := tx.Exec("delete from oids where oid = ?", oid)
+	if err != nil {
+		tx.Rollback()
+		return sqliteError("delete object", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		tx.Rollback()
+		return errObjectNotFound
+	}
+	if _, err := tx.Exec("delete from oid_maps where oid = ?", oid); err != nil {
+		tx.Rollback()
+		return sqliteError("delete object", err)
+	}
+	return sqliteError("delete object", tx.Commit())
+}
+
+/*
+AddProject (Add a new project)
+fails with errProjectExists when the project is already there
+*/
+func (s *SQLiteMetaStore) AddProject(namespace, name string) error {
+	res, err := s.client.Exec("insert into projects (namespace, name) values (?, ?) on conflict (namespace, name) do nothing", namespace, name)
+	if err != nil {
+		return sqliteError("add project", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return errProjectExists
+	}
+	return nil
+}
+
+/*
+RenameProject (move a project and its locks to newNamespace/newName)
+oid mappings follow the project id
+*/
+func (s *SQLiteMetaStore) RenameProject(namespace, name, newNamespace, newName string) error {
+	tx, err := s.client.Begin()
+	if err != nil {
+		return sqliteError("rename project", err)
+	}
+	res, err := tx.Exec("update projects set namespace = ?, name = ? where namespace = ? and name = ?", newNamespace, newName, namespace, name)
+	if isSQLiteConstraint(err) {
+		tx.Rollback()
+		return errProjectExists
+	}
+	if err != nil {
+		tx.Rollback()
+		return sqliteError("rename project", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		tx.Rollback()
+		return errProjectNotFound
+	}
+	if _, err := tx.Exec("update locks set project = ? where project = ?", projectKey(newNamespace, newName), projectKey(namespace, name)); err != nil {
+		tx.Rollback()
+		return sqliteError("rename project", err)
+	}
+	return sqliteError("rename project", tx.Commit())
+}
+
+/*
+DeleteProject (remove a project, its oid mappings and its locks)
+the oids are left for garbage collection
+*/
+func (s *SQLiteMetaStore) DeleteProject(namespace, name string) error {
+	tx, err := s.client.Begin()
+	if err != nil {
+		return sqliteError("delete project", err)
+	}
+	var id int64
+	err = tx.QueryRow("select id from projects where namespace = ? and name = ?", namespace, name).Scan(&id)
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		return errProjectNotFound
+	}
+	if err != nil {
+		tx.Rollback()
+		return sqliteError("delete project", err)
+	}
+	for _, q := range []string{"delete from oid_maps where projectID = ?", "delete from projects where id = ?"} {
+		if _, err := tx.Exec(q, id); err != nil {
+			tx.Rollback()
+			return sqliteError("delete project", err)
+		}
+	}
+	if _, err := tx.Exec("delete from locks where project = ?", projectKey(namespace, name)); err != nil {
+		tx.Rollback()
+		return sqliteError("delete project", err)
+	}
+	return sqliteError("delete project", tx.Commit())
+}
+
+/*
+AddUser (Add a new user)
+the password is stored as a bcrypt hash, existing users are left untouched
+*/
+func (s *SQLiteMetaStore) AddUser(user, pass string) error {
+	if Config.Ldap.Enabled {
+		return errNotImplemented
+	}
+	encryptedPass, err := encryptPass([]byte(pass))
+	if err != nil {
+		return err
+	}
+	_, err = s.client.Exec("insert into users (name, password) values (?, ?) on conflict (name) do nothing", user, encryptedPass)
+	return sqliteError("add user", err)
+}
+
+/*
+DeleteUser (Delete a user)
+*/
+func (s *SQLiteMetaStore) DeleteUser(user string) error {
+	if Config.Ldap.Enabled {
+		return errNotImplemented
+	}
+	// revoke the tokens first, so they stop working with the user gone
+	if _, err := s.client.Exec("delete from tokens where username = ?", user); err != nil {
+		return sqliteError("delete user", err)
+	}
+	_, err := s.client.Exec("delete from users where name = ?", user)
+	return sqliteError("delete user", err)
+}
+
+/*
+Users (get list of users)
+*/
+func (s *SQLiteMetaStore) Users() ([]*MetaUser, error) {
+	if Config.Ldap.Enabled {
+		return []*MetaUser{}, errNotImplemented
+	}
+	rows, err := s.client.Query("select name from users order by name")
+	if err != nil {
+		return nil, sqliteError("list users", err)
+	}
+	defer rows.Close()
+
+	users := make([]*MetaUser, 0)
+	for rows.Next() {
+		var user MetaUser
+		if err := rows.Scan(&user.Name); err != nil {
+			return nil, sqliteError("list users", err)
+		}
+		users = append(users, &user)
+	}
+	return users, sqliteError("list users", rows.Err())
+}
+
+/*
+CheckPassword (report whether password is the password of the local user)
+*/
+func (s *SQLiteMetaStore) CheckPassword(user, password string) (bool, error) {
+	var hash string
+	err := s.client.QueryRow("select password from users where name = ?", user).Scan(&hash)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, sqliteError("find user", err)
+	}
+	return checkPass([]byte(hash), []byte(password))
+}
+
+/*
+AddLock (lock a path in a project)
+fails with errLockExists when the path is already locked
+*/
+func (s *SQLiteMetaStore) AddLock(v *RequestVars, lock *MetaLock) error {
+	lock.Project = v.Project()
+	_, err := s.client.Exec("insert into locks (id, project, path, owner, lockedAt) values (?, ?, ?, ?, ?)",
+		lock.Id, lock.Project, lock.Path, lock.Owner, lock.LockedAt)
+	if isSQLiteConstraint(err) {
+		return errLockExists
+	}
+	return sqliteError("add lock", err)
+}
+
+/*
+Locks (get all locks of a project)
+*/
+func (s *SQLiteMetaStore) Locks(v *RequestVars) ([]*MetaLock, error) {
+	rows, err := s.client.Query("select id, path, owner, lockedAt from locks where project = ? order by path", v.Project())
+	if err != nil {
+		return nil, sqliteError("list locks", err)
+	}
+	defer rows.Close()
+
+	var lockList []*MetaLock
+	for rows.Next() {
+		lock := &MetaLock{Project: v.Project()}
+		if err := rows.Scan(&lock.Id, &lock.Path, &lock.Owner, &lock.LockedAt); err != nil {
+			return nil, sqliteError("list locks", err)
+		}
+		lockList = append(lockList, lock)
+	}
+	return lockList, sqliteError("list locks", rows.Err())
+}
+
+/*
+DeleteLock (remove a lock from a project)
+*/
+func (s *SQLiteMetaStore) DeleteLock(v *RequestVars, id string) error {
+	res, err := s.client.Exec("delete from locks where project = ? and id = ?", v.Project(), id)
+	if err != nil {
+		return sqliteError("delete lock", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return errLockNotFound
+	}
+	return nil
+}
+
+/*
+AddToken (store a personal access token, keyed by its hash)
+*/
+func (s *SQLiteMetaStore) AddToken(token *MetaToken) error {
+	_, err := s.client.Exec("insert into tokens (hash, id, username, name, scope, projects, createdAt) values (?, ?, ?, ?, ?, ?, ?)",
+		token.Hash, token.Id, token.User, token.Name, token.Scope, strings.Join(token.Projects, ","), token.CreatedAt)
+	return sqliteError("add token", err)
+}
+
+/*
+Tokens (get the personal access tokens of a user, or of all users when user is empty)
+*/
+func (s *SQLiteMetaStore) Tokens(user string) ([]*MetaToken, error) {
+	query := "select hash, id, username, name, scope, projects, createdAt from tokens"
+	var args []interface{}
+	if user != "" {
+		query += " where username = ?"
+		args = append(args, user)
+	}
+	rows, err := s.client.Query(query+" order by createdAt", args...)
+	if err != nil {
+		return nil, sqliteError("list tokens", err)
+	}
+	defer rows.Close()
+
+	var tokenList []*MetaToken
+	for rows.Next() {
+		token, err := scanToken(rows)
+		if err != nil {
+			return nil, sqliteError("list tokens", err)
+		}
+		tokenList = append(tokenList, token)
+	}
+	return tokenList, sqliteError("list tokens", rows.Err())
+}
+
+/*
+FindToken (get the personal access token with the given hash)
+*/
+func (s *SQLiteMetaStore) FindToken(hash string) (*MetaToken, error) {
+	row := s.client.QueryRow("select hash, id, username, name, scope, projects, createdAt from tokens where hash = ?", hash)
+	token, err := scanToken(row)
+	if err == sql.ErrNoRows {
+		return nil, errTokenNotFound
+	}
+	return token, sqliteError("find token", err)
+}
+
+/*
+DeleteToken (revoke the personal access token with the given id)
+*/
+func (s *SQLiteMetaStore) DeleteToken(id string) error {
+	res, err := s.client.Exec("delete from tokens where id = ?", id)
+	if err != nil {
+		return sqliteError("delete token", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return errTokenNotFound
+	}
+	return nil
+}
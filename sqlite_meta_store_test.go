@@ -0,0 +1,382 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var (
+	metaStoreTestSQLite *SQLiteMetaStore
+	sqliteTestDir       string
+)
+
+func TestSQLiteConfiguration(t *testing.T) {
+	defer func(path string) { Config.SQLiteDB = path }(Config.SQLiteDB)
+	Config.SQLiteDB = " "
+
+	sqliteStore, err := NewSQLiteMetaStore()
+	if sqliteStore != nil || err != errMissingParams {
+		t.Errorf("expected SQLite configration validation error, got : %v", err)
+	}
+}
+
+func TestSQLiteMigrations(t *testing.T) {
+	if err := setupSQLiteMeta(); err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer teardownSQLiteMeta()
+
+	migrations := sqlMigrations(sqliteSerial, sqliteCatalog)
+	version, err := schemaVersion(metaStoreTestSQLite.client)
+	if err != nil || version != len(migrations) {
+		t.Errorf("expected schema version %d, got: %d %v", len(migrations), version, err)
+	}
+
+	applied, err := migrate(metaStoreTestSQLite.client, migrations, questionMark)
+	if err != nil || applied != 0 {
+		t.Errorf("expected an up to date schema to apply no migrations, got: %d %v", applied, err)
+	}
+}
+
+func TestSQLiteMigrationsAdoptExistingTables(t *testing.T) {
+	if err := setupSQLiteMeta(); err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer teardownSQLiteMeta()
+	metaStoreTestSQLite.Close()
+
+	// a database created before the schema was versioned
+	path := filepath.Join(sqliteTestDir, "unversioned.sqlite")
+	db, err := sql.Open("sqlite", sqliteDSN(path))
+	if err != nil {
+		t.Fatalf("expected to open %s, got: %s", path, err)
+	}
+	for _, q := range []string{
+		"create table projects (id integer not null primary key, namespace varchar(255), name varchar(255), unique (namespace, name))",
+		"create table oids (oid varchar(255) not null primary key, size bigint)",
+		"create table oid_maps (oid varchar(255), projectID bigint)",
+		"insert into projects (namespace, name) values ('" + testNamespace + "', '" + testRepo + "')",
+		"insert into oids (oid, size) values ('" + contentOid + "', 42)",
+		"insert into oid_maps (oid, projectID) values ('" + contentOid + "', 1)",
+	} {
+		if _, err := db.Exec(q); err != nil {
+			t.Fatalf("expected %q to succeed, got: %s", q, err)
+		}
+	}
+	db.Close()
+
+	Config.SQLiteDB = path
+	store, err := NewSQLiteMetaStore()
+	if err != nil {
+		t.Fatalf("expected the existing tables to be migrated, got: %s", err)
+	}
+	metaStoreTestSQLite = store
+
+	meta, err := store.Get(&RequestVars{Authorization: testAuth, Oid: contentOid, Namespace: testNamespace, Repo: testRepo})
+	if err != nil || meta.Size != 42 {
+		t.Errorf("expected to keep the existing objects, got: %v %v", meta, err)
+	}
+	if version, _ := schemaVersion(store.client); version != len(sqlMigrations(sqliteSerial, sqliteCatalog)) {
+		t.Errorf("expected the schema to be up to date, got version %d", version)
+	}
+}
+
+func TestSQLiteUpgradeLegacyTables(t *testing.T) {
+	if err := setupSQLiteMeta(); err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer teardownSQLiteMeta()
+	metaStoreTestSQLite.Close()
+	defer func(namespace string) { Config.LegacyNamespace = namespace }(Config.LegacyNamespace)
+	Config.LegacyNamespace = testNamespace
+
+	// the tables gorp created before namespaces
+	store := openLegacySQLite(t, []string{
+		"create table projects (id integer not null primary key autoincrement, name varchar(255) unique)",
+		"create table oids (oid varchar(255) not null primary key, size bigint)",
+		"create table oid_maps (oid varchar(255), projectID bigint)",
+		"insert into projects (id, name) values (7, '" + testRepo + "')",
+		"insert into oids (oid, size) values ('" + contentOid + "', 42)",
+		"insert into oid_maps (oid, projectID) values ('" + contentOid + "', 7)",
+		"insert into oid_maps (oid, projectID) values ('" + contentOid + "', 7)",
+	})
+
+	meta, err := store.Get(&RequestVars{Authorization: testAuth, Oid: contentOid, Namespace: testNamespace, Repo: testRepo})
+	if err != nil || meta.Size != 42 {
+		t.Errorf("expected the object to be found in %s/%s, got: %v %v", testNamespace, testRepo, meta, err)
+	}
+	// the same repo name in another namespace is another project
+	if _, err := store.Put(&RequestVars{Authorization: testAuth, Oid: contentOid, Size: 42, Namespace: "other", Repo: testRepo}); err != nil {
+		t.Errorf("expected put to another namespace to succeed, got: %s", err)
+	}
+	projects, err := store.Projects()
+	if err != nil || len(projects) != 2 {
+		t.Fatalf("expected two projects, got: %v %v", projects, err)
+	}
+	for _, p := range projects {
+		if p.Name != testRepo || len(p.Oids) != 1 {
+			t.Errorf("expected %s holding the object once, got: %+v", testRepo, p)
+		}
+	}
+	if version, _ := schemaVersion(store.client); version != len(sqlMigrations(sqliteSerial, sqliteCatalog)) {
+		t.Errorf("expected the schema to be up to date, got version %d", version)
+	}
+}
+
+func TestSQLiteUpgradeInterruptedLegacyTables(t *testing.T) {
+	if err := setupSQLiteMeta(); err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer teardownSQLiteMeta()
+	metaStoreTestSQLite.Close()
+	defer func(namespace string) { Config.LegacyNamespace = namespace }(Config.LegacyNamespace)
+	Config.LegacyNamespace = testNamespace
+
+	// a MySQL upgrade that stopped after dropping projects and creating oids_upgraded
+	store := openLegacySQLite(t, []string{
+		"create table projects_namespaced (id integer not null primary key, namespace varchar(255) not null, name varchar(255) not null, unique (namespace, name))",
+		"create table oids (oid varchar(255) not null primary key, size bigint)",
+		"create table oids_upgraded (oid varchar(255) not null primary key, size bigint not null)",
+		"create table oid_maps (oid varchar(255), projectID bigint)",
+		"insert into projects_namespaced (id, namespace, name) values (7, '" + testNamespace + "', '" + testRepo + "')",
+		"insert into oids (oid, size) values ('" + contentOid + "', 42)",
+		"insert into oids_upgraded (oid, size) values ('" + contentOid + "', 1)",
+		"insert into oid_maps (oid, projectID) values ('" + contentOid + "', 7)",
+		"create index oid_maps_project on oid_maps (projectID)",
+		createSchemaVersion,
+		"insert into schema_version (version, description, applied_at) values (1, 'create', current_timestamp), (2, 'index', current_timestamp)",
+	})
+
+	meta, err := store.Get(&RequestVars{Authorization: testAuth, Oid: contentOid, Namespace: testNamespace, Repo: testRepo})
+	if err != nil || meta.Size != 42 {
+		t.Errorf("expected the object to be found in %s/%s, got: %v %v", testNamespace, testRepo, meta, err)
+	}
+	for _, table := range []string{"projects_namespaced", "oids_upgraded", "oid_maps_upgraded"} {
+		var count int
+		if err := store.client.QueryRow("select count(*) from sqlite_master where name = ?", table).Scan(&count); err != nil || count != 0 {
+			t.Errorf("expected %s to be gone, got: %d %v", table, count, err)
+		}
+	}
+	var indexes int
+	if err := store.client.QueryRow("select count(*) from pragma_index_list('oid_maps')").Scan(&indexes); err != nil || indexes != 2 {
+		t.Errorf("expected oid_maps to be indexed, got: %d %v", indexes, err)
+	}
+}
+
+// openLegacySQLite runs statements on a new database file and opens it as the meta store
+func openLegacySQLite(t *testing.T, statements []string) *SQLiteMetaStore {
+	path := filepath.Join(sqliteTestDir, "legacy.sqlite")
+	db, err := sql.Open("sqlite", sqliteDSN(path))
+	if err != nil {
+		t.Fatalf("expected to open %s, got: %s", path, err)
+	}
+	for _, q := range statements {
+		if _, err := db.Exec(q); err != nil {
+			t.Fatalf("expected %q to succeed, got: %s", q, err)
+		}
+	}
+	db.Close()
+
+	Config.SQLiteDB = path
+	store, err := NewSQLiteMetaStore()
+	if err != nil {
+		t.Fatalf("expected the legacy tables to be upgraded, got: %s", err)
+	}
+	metaStoreTestSQLite = store
+	return store
+}
+
+func TestSQLitePutGet(t *testing.T) {
+	if err := setupSQLiteMeta(); err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer teardownSQLiteMeta()
+
+	meta, err := metaStoreTestSQLite.Put(&RequestVars{Authorization: testAuth, Oid: contentOid, Size: 42, Namespace: testNamespace, Repo: testRepo})
+	if err != nil {
+		t.Fatalf("expected put to succeed, got : %s", err)
+	}
+	if meta.Existing {
+		t.Errorf("expected meta to not have existed")
+	}
+
+	meta, err = metaStoreTestSQLite.Put(&RequestVars{Authorization: testAuth, Oid: contentOid, Size: 42, Namespace: testNamespace, Repo: testRepo})
+	if err != nil || !meta.Existing {
+		t.Errorf("expected a second put to find the object, got : %v %v", meta, err)
+	}
+
+	meta, err = metaStoreTestSQLite.Get(&RequestVars{Authorization: testAuth, Oid: contentOid, Namespace: testNamespace, Repo: testRepo})
+	if err != nil || meta.Oid != contentOid || meta.Size != 42 {
+		t.Errorf("expected to be able to retreive new put, got : %v %v", meta, err)
+	}
+
+	if _, err := metaStoreTestSQLite.Get(&RequestVars{Authorization: testAuth, Oid: contentOid, Namespace: testNamespace, Repo: "other"}); err != errObjectNotFound {
+		t.Errorf("expected errObjectNotFound from another project, got : %v", err)
+	}
+
+	projects, err := metaStoreTestSQLite.Projects()
+	if err != nil || len(projects) != 1 || len(projects[0].Oids) != 1 {
+		t.Errorf("expected one project holding the object once, got : %v %v", projects, err)
+	}
+
+	if err := metaStoreTestSQLite.DeleteObject(contentOid); err != nil {
+		t.Errorf("expected DeleteObject to succeed, got : %s", err)
+	}
+	if _, err := metaStoreTestSQLite.Get(&RequestVars{Authorization: testAuth, Oid: contentOid, Namespace: testNamespace, Repo: testRepo}); err != errObjectNotFound {
+		t.Errorf("expected errObjectNotFound after delete, got : %v", err)
+	}
+	if err := metaStoreTestSQLite.DeleteObject(contentOid); err != errObjectNotFound {
+		t.Errorf("expected errObjectNotFound deleting twice, got : %v", err)
+	}
+}
+
+func TestSQLiteQueryErrorNotUnavailable(t *testing.T) {
+	if err := setupSQLiteMeta(); err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer teardownSQLiteMeta()
+
+	_, err := metaStoreTestSQLite.client.Exec("select * from nonsense")
+	if err = sqliteError("query", err); err == nil || isUnavailable(err) {
+		t.Errorf("expected a failing query not to make the store unavailable, got: %v", err)
+	}
+}
+
+func TestSQLiteReadersAlongsideWriter(t *testing.T) {
+	if err := setupSQLiteMeta(); err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer teardownSQLiteMeta()
+
+	if _, err := metaStoreTestSQLite.Put(&RequestVars{Authorization: testAuth, Oid: contentOid, Size: 42, Namespace: testNamespace, Repo: testRepo}); err != nil {
+		t.Fatalf("expected put to succeed, got : %s", err)
+	}
+
+	tx, err := metaStoreTestSQLite.client.Begin()
+	if err != nil {
+		t.Fatalf("expected to begin a write transaction, got: %s", err)
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec("insert into oids (oid, size) values ('uncommitted', 1)"); err != nil {
+		t.Fatalf("expected insert to succeed, got: %s", err)
+	}
+
+	// a separate process, like an offline tool, reads the committed data
+	reader, err := sql.Open("sqlite", sqliteDSN(Config.SQLiteDB))
+	if err != nil {
+		t.Fatalf("expected to open a reader, got: %s", err)
+	}
+	defer reader.Close()
+	var count int
+	if err := reader.QueryRow("select count(*) from oids").Scan(&count); err != nil || count != 1 {
+		t.Errorf("expected to read 1 committed oid while writing, got: %d %v", count, err)
+	}
+}
+
+func TestSQLiteLocks(t *testing.T) {
+	if err := setupSQLiteMeta(); err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer teardownSQLiteMeta()
+
+	v := &RequestVars{Authorization: testAuth, Namespace: testNamespace, Repo: testRepo}
+	lock := &MetaLock{Id: "lock-1", Path: "a/b.bin", Owner: testUser}
+	if err := metaStoreTestSQLite.AddLock(v, lock); err != nil {
+		t.Fatalf("expected AddLock to succeed, got: %s", err)
+	}
+	if err := metaStoreTestSQLite.AddLock(v, &MetaLock{Id: "lock-2", Path: "a/b.bin", Owner: testUser}); err != errLockExists {
+		t.Errorf("expected errLockExists, got: %v", err)
+	}
+
+	locks, err := metaStoreTestSQLite.Locks(v)
+	if err != nil || len(locks) != 1 || locks[0].Path != "a/b.bin" {
+		t.Errorf("expected one lock, got: %v %v", locks, err)
+	}
+
+	if err := metaStoreTestSQLite.DeleteLock(v, "lock-1"); err != nil {
+		t.Errorf("expected DeleteLock to succeed, got: %s", err)
+	}
+	if err := metaStoreTestSQLite.DeleteLock(v, "lock-1"); err != errLockNotFound {
+		t.Errorf("expected errLockNotFound, got: %v", err)
+	}
+}
+
+func TestSQLiteTokens(t *testing.T) {
+	if err := setupSQLiteMeta(); err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer teardownSQLiteMeta()
+
+	testTokenStore(t, metaStoreTestSQLite)
+}
+
+func TestSQLiteProjectConformance(t *testing.T) {
+	if err := setupSQLiteMeta(); err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer teardownSQLiteMeta()
+
+	testProjectStore(t, metaStoreTestSQLite)
+}
+
+func TestSQLiteUsers(t *testing.T) {
+	if err := setupSQLiteMeta(); err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer teardownSQLiteMeta()
+	defer func(enabled bool) { Config.Ldap.Enabled = enabled }(Config.Ldap.Enabled)
+	Config.Ldap.Enabled = false
+
+	if err := metaStoreTestSQLite.AddUser(testUser, testPass); err != nil {
+		t.Fatalf("expected AddUser to succeed, got: %s", err)
+	}
+	// adding an existing user keeps its password
+	if err := metaStoreTestSQLite.AddUser(testUser, "otherpass"); err != nil {
+		t.Errorf("expected AddUser of an existing user to succeed, got: %s", err)
+	}
+
+	if ok, err := metaStoreTestSQLite.CheckPassword(testUser, testPass); !ok || err != nil {
+		t.Errorf("expected the password to match, got: %t %v", ok, err)
+	}
+	if ok, _ := metaStoreTestSQLite.CheckPassword(testUser, "otherpass"); ok {
+		t.Errorf("expected a wrong password not to match")
+	}
+
+	users, err := metaStoreTestSQLite.Users()
+	if err != nil || len(users) != 1 || users[0].Name != testUser {
+		t.Errorf("expected to list %s, got: %v %v", testUser, users, err)
+	}
+
+	if err := metaStoreTestSQLite.DeleteUser(testUser); err != nil {
+		t.Errorf("expected DeleteUser to succeed, got: %s", err)
+	}
+	if users, _ := metaStoreTestSQLite.Users(); len(users) != 0 {
+		t.Errorf("expected no users after delete, got: %d", len(users))
+	}
+}
+
+func setupSQLiteMeta() error {
+	dir, err := ioutil.TempDir("", "lfs-sqlite")
+	if err != nil {
+		return err
+	}
+	sqliteTestDir = dir
+	Config.SQLiteDB = filepath.Join(dir, "lfs-test.sqlite")
+
+	sqliteStore, err := NewSQLiteMetaStore()
+	if err != nil {
+		os.RemoveAll(dir)
+		return fmt.Errorf("error initializing test meta store: %s", err)
+	}
+	metaStoreTestSQLite = sqliteStore
+	return nil
+}
+
+func teardownSQLiteMeta() {
+	metaStoreTestSQLite.Close()
+	os.RemoveAll(sqliteTestDir)
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"database/sql"
+	"net/url"
+	"strings"
+
+	_ "modernc.org/sqlite"
+)
+
+/*
+SQLiteService struct
+*/
+type SQLiteService struct {
+	Client *sql.DB
+}
+
+// sqliteSerial is the column definition of the generated project ids
+const sqliteSerial = "integer not null primary key"
+
+// sqliteCatalog reads the schema of the database file
+var sqliteCatalog = sqlCatalog{
+	columns: `select name, "notnull" = 0 from pragma_table_info(?)`,
+	indexes: "select name from pragma_index_list(?)",
+}
+
+/*
+NewSQLiteSession (method used in sqlite_meta_store.go)
+open the database file and bring the schema, shared with MySQL, up to date
+fails with errMissingParams when no file is configured
+*/
+func NewSQLiteSession() (*SQLiteService, error) {
+	db, err := openSQLite()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := migrate(db, sqlMigrations(sqliteSerial, sqliteCatalog), questionMark); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &SQLiteService{Client: db}, nil
+}
+
+// openSQLite opens the configured database file, leaving its schema as it is
+func openSQLite() (*sql.DB, error) {
+	if strings.TrimSpace(Config.SQLiteDB) == "" {
+		logger.Log(kv{"fn": "NewSQLiteSession", "msg": "Require SQLiteDB to open SQLite"})
+		return nil, errMissingParams
+	}
+
+	db, err := sql.Open("sqlite", sqliteDSN(Config.SQLiteDB))
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, sqliteError("open", err)
+	}
+	return db, nil
+}
+
+/*
+sqliteDSN (connection string of the database file at path)
+the write-ahead log lets readers, the sqlite3 shell included, work alongside the server,
+writers wait for each other instead of failing right away
+*/
+func sqliteDSN(path string) string {
+	query := url.Values{
+		"_pragma":      {"busy_timeout(5000)", "journal_mode(WAL)", "synchronous(NORMAL)"},
+		"_txlock":      {"immediate"},
+		"_time_format": {"sqlite"},
+	}
+	return "file:" + path + "?" + query.Encode()
+}
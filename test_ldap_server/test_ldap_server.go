@@ -1,9 +1,10 @@
 // Just returns success when username = user and password = password
-// Otherwise, the response is a failure
+// Otherwise, the response is a failure. Searches need a bound service account
 // used for testing
 package main
 
 import (
+	"errors"
 	"github.com/nmcclain/ldap"
 	"log"
 	"net"
@@ -57,6 +58,11 @@ func (h ldapHandler) Bind(bindDN, bindSimplePw string, conn net.Conn) (ldap.LDAP
 }
 
 func (s searchSimple) Search(boundDN string, searchReq ldap.SearchRequest, conn net.Conn) (ldap.ServerSearchResult, error) {
+	// like most directories, only bound clients may search
+	if boundDN == "" {
+		log.Println("Refusing anonymous search")
+		return ldap.ServerSearchResult{nil, []string{}, []ldap.Control{}, ldap.LDAPResultInsufficientAccessRights}, errors.New("anonymous search is not allowed")
+	}
 	log.Println("Searching")
 	entries := []*ldap.Entry{
 		&ldap.Entry{"cn=ned,o=testers,o=company", []*ldap.EntryAttribute{
@@ -76,6 +82,7 @@ func (s searchSimple) Search(boundDN string, searchReq ldap.SearchRequest, conn
 			&ldap.EntryAttribute{"uid", []string{"admin"}},
 			&ldap.EntryAttribute{"description", []string{"admin via sa"}},
 			&ldap.EntryAttribute{"objectclass", []string{"posixaccount", "user"}},
+			&ldap.EntryAttribute{"memberOf", []string{"cn=art-team,ou=groups,o=company", "cn=qa,ou=groups,o=company"}},
 		}},
 		&ldap.Entry{"cn=trent,o=testers,o=company", []*ldap.EntryAttribute{
 			&ldap.EntryAttribute{"cn", []string{"trent"}},
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// TokenClaims is what a Bearer token grants: operation on one namespace/repo until ExpiresAt.
+type TokenClaims struct {
+	User      string `json:"user"`
+	Project   string `json:"project"`
+	Operation string `json:"operation"`
+	ExpiresAt int64  `json:"expires_at"`
+}
+
+// SSHAuthResponse is the git-lfs-authenticate output git-lfs reads over ssh.
+type SSHAuthResponse struct {
+	Href      string            `json:"href"`
+	Header    map[string]string `json:"header"`
+	ExpiresIn int               `json:"expires_in"`
+}
+
+// issueToken signs claims with Config.TokenSecret. Tokens are the base64 json claims
+// and their HMAC-SHA256, joined by a dot.
+func issueToken(claims *TokenClaims) (string, error) {
+	if Config.TokenSecret == "" {
+		return "", errNoTokenSecret
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + signToken(encoded), nil
+}
+
+// verifyToken checks the signature and expiry of a token and returns its claims
+func verifyToken(token string) (*TokenClaims, error) {
+	if Config.TokenSecret == "" {
+		return nil, errNoTokenSecret
+	}
+	parts := strings.SplitN(token, ".", 2)
+	if len(parts) != 2 || !hmac.Equal([]byte(parts[1]), []byte(signToken(parts[0]))) {
+		return nil, errInvalidToken
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, errInvalidToken
+	}
+	var claims TokenClaims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, errInvalidToken
+	}
+	if time.Now().Unix() > claims.ExpiresAt {
+		return nil, errTokenExpired
+	}
+	return &claims, nil
+}
+
+func signToken(payload string) string {
+	mac := hmac.New(sha256.New, []byte(Config.TokenSecret))
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// bearerToken returns the token of a "Bearer <token>" Authorization header, or ""
+func bearerToken(authorization string) string {
+	if !strings.HasPrefix(authorization, "Bearer ") {
+		return ""
+	}
+	return strings.TrimPrefix(authorization, "Bearer ")
+}
+
+// tokenAllows reports whether a token issued for operation covers action. Upload
+// tokens also allow downloads, as git-lfs downloads and uploads during a push.
+func tokenAllows(claims *TokenClaims, project, action string) bool {
+	if claims.Project != project {
+		return false
+	}
+	if action == "push" {
+		return claims.Operation == "upload"
+	}
+	return true
+}
+
+// sshAuthenticate implements git-lfs-authenticate for ssh remotes. sshd runs it with
+// "<namespace>/<repo> <upload|download>", either as arguments or in SSH_ORIGINAL_COMMAND
+// when it is the forced command of an authorized key, e.g.
+//
+//	command="lfs-server-go git-lfs-authenticate -user alice" ssh-rsa ...
+func sshAuthenticate(args []string) int {
+	// git-lfs parses stdout, it must only ever hold the response
+	logger = NewKVLogger(os.Stderr)
+
+	flags := flag.NewFlagSet("git-lfs-authenticate", flag.ExitOnError)
+	user := flags.String("user", "", "the user the token is issued to, required")
+	flags.Parse(args)
+	if *user == "" {
+		// the account sshd runs the command as is shared by every key, never issue tokens to it
+		fmt.Fprintln(os.Stderr, "git-lfs-authenticate needs the user the token is issued to: -user <name>")
+		return 1
+	}
+
+	args = flags.Args()
+	if len(args) == 0 {
+		// git-lfs-authenticate 'namespace/repo.git' download
+		args = strings.Fields(strings.Replace(os.Getenv("SSH_ORIGINAL_COMMAND"), "'", "", -1))
+		if len(args) > 0 && args[0] == "git-lfs-authenticate" {
+			args = args[1:]
+		}
+	}
+	if len(args) != 2 {
+		fmt.Fprintln(os.Stderr, "usage: git-lfs-authenticate <namespace>/<repo> <upload|download>")
+		return 1
+	}
+
+	path, operation := strings.Trim(strings.TrimSuffix(args[0], ".git"), "/"), args[1]
+	parts := strings.Split(path, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		fmt.Fprintf(os.Stderr, "Invalid repo %q, expected <namespace>/<repo>\n", args[0])
+		return 1
+	}
+	if operation != "upload" && operation != "download" {
+		fmt.Fprintf(os.Stderr, "Invalid operation %q, expected upload or download\n", operation)
+		return 1
+	}
+
+	resp, err := sshAuthResponse(*user, &RequestVars{Namespace: parts[0], Repo: parts[1]}, operation)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	logger.Log(kv{"fn": "sshAuthenticate", "user": *user, "project": path, "operation": operation})
+	json.NewEncoder(os.Stdout).Encode(resp)
+	return 0
+}
+
+// sshAuthResponse issues a token for user to perform operation on the repo of rv,
+// checking the LDAP groups and the user service first when they are enabled
+func sshAuthResponse(user string, rv *RequestVars, operation string) (*SSHAuthResponse, error) {
+	action := "download"
+	if operation == "upload" {
+		action = "push"
+	}
+	if ldapGroupsEnabled() {
+		if access, message := ldapGroupsCan(user, rv.Project(), action); !access {
+			return nil, fmt.Errorf("Access denied: %s", message)
+		}
+	}
+	if Config.UserService.Enabled {
+		if access, message := userCan(user, rv.Project(), action); !access {
+			return nil, fmt.Errorf("Access denied: %s", message)
+		}
+	}
+
+	expires := time.Now().Add(Config.TokenTTL)
+	token, err := issueToken(&TokenClaims{User: user, Project: rv.Project(), Operation: operation, ExpiresAt: expires.Unix()})
+	if err != nil {
+		return nil, err
+	}
+	return &SSHAuthResponse{
+		Href:      rv.RepoLink(),
+		Header:    map[string]string{"Authorization": "Bearer " + token},
+		ExpiresIn: int(Config.TokenTTL.Seconds()),
+	}, nil
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTokenRoundTrip(t *testing.T) {
+	defer withTokenSecret("s3cret")()
+
+	token, err := issueToken(&TokenClaims{User: testUser, Project: "namespace/repo", Operation: "upload", ExpiresAt: time.Now().Add(time.Minute).Unix()})
+	if err != nil {
+		t.Fatalf("expected token to be issued, got: %s", err)
+	}
+
+	claims, err := verifyToken(token)
+	if err != nil {
+		t.Fatalf("expected token to verify, got: %s", err)
+	}
+	if claims.User != testUser || claims.Project != "namespace/repo" || claims.Operation != "upload" {
+		t.Errorf("expected claims to round trip, got %+v", claims)
+	}
+}
+
+func TestTokenTampered(t *testing.T) {
+	defer withTokenSecret("s3cret")()
+
+	token, _ := issueToken(&TokenClaims{User: testUser, Project: "namespace/repo", Operation: "download", ExpiresAt: time.Now().Add(time.Minute).Unix()})
+	other, _ := issueToken(&TokenClaims{User: testUser, Project: "namespace/other", Operation: "upload", ExpiresAt: time.Now().Add(time.Minute).Unix()})
+	forged := strings.Split(other, ".")[0] + "." + strings.Split(token, ".")[1]
+
+	if _, err := verifyToken(forged); err != errInvalidToken {
+		t.Errorf("expected a forged token to be rejected, got: %v", err)
+	}
+
+	Config.TokenSecret = "another secret"
+	if _, err := verifyToken(token); err != errInvalidToken {
+		t.Errorf("expected a token signed with another secret to be rejected, got: %v", err)
+	}
+}
+
+func TestTokenExpired(t *testing.T) {
+	defer withTokenSecret("s3cret")()
+
+	token, _ := issueToken(&TokenClaims{User: testUser, Project: "namespace/repo", Operation: "download", ExpiresAt: time.Now().Add(-time.Minute).Unix()})
+	if _, err := verifyToken(token); err != errTokenExpired {
+		t.Errorf("expected an expired token to be rejected, got: %v", err)
+	}
+}
+
+func TestTokenAllows(t *testing.T) {
+	download := &TokenClaims{Project: "namespace/repo", Operation: "download"}
+	upload := &TokenClaims{Project: "namespace/repo", Operation: "upload"}
+
+	if !tokenAllows(download, "namespace/repo", "download") || tokenAllows(download, "namespace/repo", "push") {
+		t.Errorf("expected download tokens to only allow downloads")
+	}
+	if !tokenAllows(upload, "namespace/repo", "push") || !tokenAllows(upload, "namespace/repo", "download") {
+		t.Errorf("expected upload tokens to allow uploads and downloads")
+	}
+	if tokenAllows(upload, "namespace/other", "download") {
+		t.Errorf("expected tokens to be limited to their project")
+	}
+}
+
+func TestSSHAuthResponse(t *testing.T) {
+	defer withTokenSecret("s3cret")()
+
+	resp, err := sshAuthResponse(testUser, &RequestVars{Namespace: testNamespace, Repo: testRepo}, "download")
+	if err != nil {
+		t.Fatalf("expected a response, got: %s", err)
+	}
+	if !strings.HasSuffix(resp.Href, "/namespace/repo") {
+		t.Errorf("expected href to point at the repo, got %s", resp.Href)
+	}
+	claims, err := verifyToken(bearerToken(resp.Header["Authorization"]))
+	if err != nil || claims.User != testUser {
+		t.Errorf("expected a Bearer token for %s, got %s", testUser, resp.Header["Authorization"])
+	}
+}
+
+func TestSSHAuthenticateWritesOnlyJSON(t *testing.T) {
+	defer withTokenSecret("s3cret")()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("expected a pipe, got: %s", err)
+	}
+	stdout, stdoutLogger := os.Stdout, logger
+	os.Stdout, logger = w, NewKVLogger(w)
+	code := sshAuthenticate([]string{"-user", testUser, "namespace/repo.git", "download"})
+	os.Stdout, logger = stdout, stdoutLogger
+	w.Close()
+
+	out, err := ioutil.ReadAll(r)
+	if err != nil || code != 0 {
+		t.Fatalf("expected git-lfs-authenticate to succeed, got: %d %v", code, err)
+	}
+	var resp SSHAuthResponse
+	dec := json.NewDecoder(bytes.NewReader(out))
+	if err := dec.Decode(&resp); err != nil || resp.Href == "" {
+		t.Fatalf("expected stdout to hold the response, got %q: %v", out, err)
+	}
+	if rest := out[dec.InputOffset():]; len(bytes.TrimSpace(rest)) != 0 {
+		t.Errorf("expected stdout to hold only the response, got %q", out)
+	}
+}
+
+func TestSSHAuthenticateRequiresUser(t *testing.T) {
+	defer withTokenSecret("s3cret")()
+	defer func(old string) { os.Setenv("USER", old) }(os.Getenv("USER"))
+	os.Setenv("USER", testUser)
+
+	testLogger := logger
+	code := sshAuthenticate([]string{"namespace/repo.git", "download"})
+	logger = testLogger
+	if code == 0 {
+		t.Fatalf("expected git-lfs-authenticate to fail without -user")
+	}
+}
+
+// withTokenSecret sets Config.TokenSecret and returns a func restoring it
+func withTokenSecret(secret string) func() {
+	old := Config.TokenSecret
+	Config.TokenSecret = secret
+	return func() { Config.TokenSecret = old }
+}
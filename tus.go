@@ -0,0 +1,140 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// tusVersion is the version of the tus resumable upload protocol spoken by the tus endpoints,
+// see http://tus.io/protocols/resumable-upload.html
+const tusVersion = "1.0.0"
+
+func (a *App) addTus(r *mux.Router) {
+	route := "/{namespace}/{repo}/objects/{oid}/tus"
+	r.HandleFunc(route, a.TusOptionsHandler).Methods("OPTIONS")
+	r.HandleFunc(route, a.TusHeadHandler).Methods("HEAD")
+	r.HandleFunc(route, a.TusPatchHandler).Methods("PATCH")
+}
+
+// TusOptionsHandler tells tus clients which protocol versions the server supports
+func (a *App) TusOptionsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Tus-Resumable", tusVersion)
+	w.Header().Set("Tus-Version", tusVersion)
+	w.WriteHeader(204)
+	logRequest(r, 204)
+}
+
+// TusHeadHandler reports how much of the object the server holds, so the client
+// knows where to resume
+func (a *App) TusHeadHandler(w http.ResponseWriter, r *http.Request) {
+	_, meta, pending, ok := a.tusMeta(w, r)
+	if !ok {
+		return
+	}
+
+	var offset int64
+	var err error
+	if !pending {
+		offset, err = a.contentStore.UploadOffset(meta)
+	}
+	if err != nil {
+		logger.Log(kv{"fn": "TusHeadHandler", "oid": meta.Oid, "error": err.Error()})
+		writeTusStatus(w, r, 500)
+		return
+	}
+
+	w.Header().Set("Upload-Offset", strconv.FormatInt(offset, 10))
+	w.Header().Set("Upload-Length", strconv.FormatInt(meta.Size, 10))
+	w.Header().Set("Cache-Control", "no-store")
+	writeTusStatus(w, r, 200)
+}
+
+// TusPatchHandler appends the request body to the upload at Upload-Offset. The
+// object is verified and committed once its last byte arrives.
+func (a *App) TusPatchHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Content-Type") != "application/offset+octet-stream" {
+		writeTusStatus(w, r, 415)
+		return
+	}
+	rv, meta, pending, ok := a.tusMeta(w, r)
+	if !ok {
+		return
+	}
+	offset, err := strconv.ParseInt(r.Header.Get("Upload-Offset"), 10, 64)
+	if err != nil || offset < 0 {
+		writeTusStatus(w, r, 400)
+		return
+	}
+
+	if pending {
+		// pending objects are sent whole, see receivePending
+		if offset != 0 {
+			offset, err = 0, errOffsetMismatch
+		} else if err = a.receivePending(rv, meta, r.Body); err == nil {
+			offset = meta.Size
+		}
+	} else {
+		offset, err = a.putTusBody(meta, offset, r.Body)
+	}
+	w.Header().Set("Upload-Offset", strconv.FormatInt(offset, 10))
+	switch err {
+	case nil:
+		writeTusStatus(w, r, 204)
+	case errOffsetMismatch:
+		writeTusStatus(w, r, 409)
+	case errHashMismatch, errSizeMismatch, errChunkTooSmall:
+		logger.Log(kv{"fn": "TusPatchHandler", "oid": meta.Oid, "msg": err.Error()})
+		writeTusStatus(w, r, 422)
+	default:
+		logger.Log(kv{"fn": "TusPatchHandler", "oid": meta.Oid, "error": err.Error()})
+		writeTusStatus(w, r, 500)
+	}
+}
+
+// putTusBody writes body to the content store in chunks of the S3 part size, so a
+// dropped connection only loses the chunk in flight. It returns the offset reached.
+func (a *App) putTusBody(meta *MetaObject, offset int64, body io.Reader) (int64, error) {
+	for offset < meta.Size {
+		next, err := a.contentStore.PutChunk(meta, offset, io.LimitReader(body, partSize()))
+		if err != nil || next == offset {
+			return next, err
+		}
+		offset = next
+	}
+	return offset, nil
+}
+
+// tusMeta checks the protocol version and push access, and finds the object being uploaded
+func (a *App) tusMeta(w http.ResponseWriter, r *http.Request) (rv *RequestVars, meta *MetaObject, pending, ok bool) {
+	if r.Header.Get("Tus-Resumable") != tusVersion {
+		w.Header().Set("Tus-Version", tusVersion)
+		writeTusStatus(w, r, 412)
+		return nil, nil, false, false
+	}
+	rv = unpack(r)
+	if !a.authorize(w, r, rv, "push") {
+		return nil, nil, false, false
+	}
+	meta, pending, err := a.uploadMeta(rv)
+	if err != nil {
+		if isAuthError(err) {
+			requireAuth(w, r)
+		} else if isUnavailable(err) {
+			w.Header().Set("Tus-Resumable", tusVersion)
+			writeUnavailable(w, r, err)
+		} else {
+			writeTusStatus(w, r, 404)
+		}
+		return nil, nil, false, false
+	}
+	return rv, meta, pending, true
+}
+
+func writeTusStatus(w http.ResponseWriter, r *http.Request, status int) {
+	w.Header().Set("Tus-Resumable", tusVersion)
+	w.WriteHeader(status)
+	logRequest(r, status)
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// UploadStatus is the body of an upload status response.
+type UploadStatus struct {
+	Oid    string `json:"oid"`
+	Size   int64  `json:"size"`
+	Offset int64  `json:"offset"`
+}
+
+// UploadStatusHandler tells the client how much of a resumable upload the server holds
+func (a *App) UploadStatusHandler(w http.ResponseWriter, r *http.Request) {
+	rv := unpack(r)
+	if !a.authorize(w, r, rv, "push") {
+		return
+	}
+	meta, pending, err := a.uploadMeta(rv)
+	if err != nil {
+		if isAuthError(err) {
+			requireAuth(w, r)
+		} else if isUnavailable(err) {
+			writeUnavailable(w, r, err)
+		} else {
+			writeStatus(w, r, 404)
+		}
+		return
+	}
+
+	var offset int64
+	if !pending {
+		offset, err = a.contentStore.UploadOffset(meta)
+	}
+	if err != nil {
+		logger.Log(kv{"fn": "UploadStatusHandler", "oid": meta.Oid, "error": err.Error()})
+		writeStatus(w, r, 500)
+		return
+	}
+
+	w.Header().Set("Content-Type", metaMediaType)
+	w.Header().Set("Upload-Offset", strconv.FormatInt(offset, 10))
+	enc := json.NewEncoder(w)
+	enc.Encode(&UploadStatus{Oid: meta.Oid, Size: meta.Size, Offset: offset})
+	logRequest(r, 200)
+}
+
+// uploadMeta finds the object an upload is for. Objects that are not in the project of
+// rv but held by other projects are pending: they are linked into the project by
+// receivePending, once their content has been uploaded again.
+func (a *App) uploadMeta(rv *RequestVars) (meta *MetaObject, pending bool, err error) {
+	meta, err = a.metaStore.Get(rv)
+	if err != errObjectNotFound {
+		return meta, false, err
+	}
+	meta, err = a.metaStore.FindObject(rv.Oid)
+	if err != nil {
+		return nil, false, err
+	}
+	return meta, true, nil
+}
+
+// receivePending reads the whole content of a pending object from r and links the
+// object into the project of rv once the content matches. Content that is already
+// in the content store is only hashed, so it cannot be overwritten. Pending uploads
+// are not resumable, they always start at offset 0.
+func (a *App) receivePending(rv *RequestVars, meta *MetaObject, r io.Reader) error {
+	var err error
+	if a.contentStore.Exists(meta) {
+		err = verifyContent(meta, r)
+	} else {
+		err = a.contentStore.Put(meta, r)
+	}
+	if err != nil {
+		logger.Log(kv{"fn": "receivePending", "oid": meta.Oid, "msg": err.Error()})
+		return err
+	}
+	_, err = a.metaStore.Put(rv)
+	return err
+}
+
+// putPending answers the upload of a pending object, which has to be sent in one
+// request. A Content-Range, if any, must cover the whole object.
+func (a *App) putPending(w http.ResponseWriter, r *http.Request, rv *RequestVars, meta *MetaObject) {
+	if header := r.Header.Get("Content-Range"); header != "" {
+		start, total, ok := parseContentRange(header)
+		if !ok || total != meta.Size {
+			writeStatusMessage(w, r, 400, "Invalid Content-Range")
+			return
+		}
+		if start != 0 {
+			w.Header().Set("Upload-Offset", "0")
+			writeStatusMessage(w, r, 409, errOffsetMismatch.Error())
+			return
+		}
+	}
+
+	err := a.receivePending(rv, meta, r.Body)
+	switch {
+	case err == nil:
+		if r.Header.Get("Content-Range") != "" {
+			w.Header().Set("Upload-Offset", strconv.FormatInt(meta.Size, 10))
+		}
+		logRequest(r, 200)
+	case err == errHashMismatch || err == errSizeMismatch:
+		writeStatusMessage(w, r, 422, err.Error())
+	case isAuthError(err):
+		requireAuth(w, r)
+	case isUnavailable(err):
+		writeUnavailable(w, r, err)
+	default:
+		writeStatusMessage(w, r, 500, err.Error())
+	}
+}
+
+// putChunk writes one chunk of a resumable upload, sent as a PUT with a
+// Content-Range of "bytes start-end/size". It answers 202 while the upload is
+// incomplete and 200 once the object is verified and committed. The
+// Upload-Offset header always carries the offset to resume from.
+func (a *App) putChunk(w http.ResponseWriter, r *http.Request, meta *MetaObject) {
+	start, total, ok := parseContentRange(r.Header.Get("Content-Range"))
+	if !ok || total != meta.Size {
+		writeStatusMessage(w, r, 400, "Invalid Content-Range")
+		return
+	}
+
+	offset, err := a.contentStore.PutChunk(meta, start, r.Body)
+	w.Header().Set("Upload-Offset", strconv.FormatInt(offset, 10))
+	switch err {
+	case nil:
+		if offset < meta.Size {
+			writeStatus(w, r, 202)
+			return
+		}
+		logRequest(r, 200)
+	case errOffsetMismatch:
+		writeStatusMessage(w, r, 409, err.Error())
+	case errHashMismatch, errSizeMismatch, errChunkTooSmall:
+		writeStatusMessage(w, r, 422, err.Error())
+	default:
+		logger.Log(kv{"fn": "putChunk", "oid": meta.Oid, "error": err.Error()})
+		writeStatusMessage(w, r, 500, err.Error())
+	}
+}
+
+// parseContentRange reads the start offset and total size from a Content-Range
+// header of the form "bytes start-end/size"
+func parseContentRange(header string) (start, total int64, ok bool) {
+	if !strings.HasPrefix(header, "bytes ") {
+		return 0, 0, false
+	}
+	spec := strings.SplitN(strings.TrimPrefix(header, "bytes "), "/", 2)
+	if len(spec) != 2 {
+		return 0, 0, false
+	}
+	total, err := strconv.ParseInt(spec[1], 10, 64)
+	if err != nil || total < 0 {
+		return 0, 0, false
+	}
+	bounds := strings.SplitN(spec[0], "-", 2)
+	if len(bounds) != 2 {
+		return 0, 0, false
+	}
+	start, err = strconv.ParseInt(bounds[0], 10, 64)
+	if err != nil || start < 0 || start > total {
+		return 0, 0, false
+	}
+	end, err := strconv.ParseInt(bounds[1], 10, 64)
+	if err != nil || end < start || end >= total {
+		return 0, 0, false
+	}
+	return start, total, true
+}
+
+// oidLocks serialises the chunks of resumable uploads per object, so two
+// clients resuming the same upload cannot interleave their writes
+type oidLocks struct {
+	mu     sync.Mutex
+	active map[string]chan struct{}
+}
+
+var uploadLocks = &oidLocks{active: make(map[string]chan struct{})}
+
+// lock blocks until no other chunk of oid is being written, and returns the unlock func
+func (l *oidLocks) lock(oid string) func() {
+	for {
+		l.mu.Lock()
+		done, busy := l.active[oid]
+		if !busy {
+			done = make(chan struct{})
+			l.active[oid] = done
+			l.mu.Unlock()
+			return func() {
+				l.mu.Lock()
+				delete(l.active, oid)
+				close(done)
+				l.mu.Unlock()
+			}
+		}
+		l.mu.Unlock()
+		<-done
+	}
+}
@@ -10,6 +10,9 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/url"
+	"sync"
+	"time"
 )
 
 // declare the type of UserAccessGetter
@@ -35,7 +38,8 @@ type UserService struct {
 	UserAccessResponse        *UserAccessResponse
 }
 
-var AllowedActions = []string{"download", "push", "force_push", "admin"}
+// login checks the credentials sent as Basic auth instead of access to a project
+var AllowedActions = []string{"download", "push", "force_push", "admin", "login"}
 
 func (us *UserService) vetAction() bool {
 	for _, b := range AllowedActions {
@@ -47,8 +51,11 @@ func (us *UserService) vetAction() bool {
 }
 
 func NewUserService(base string, username string, project string, action string) *UserService {
-	url := fmt.Sprintf("%s?username=%s&project=%s&action=%s", base, username, project, action)
-	us := &UserService{Downloader: NewDownloader(url), Username: username, Project: project, Action: action}
+	q := url.Values{}
+	q.Set("username", username)
+	q.Set("project", project)
+	q.Set("action", action)
+	us := &UserService{Downloader: NewDownloader(base + "?" + q.Encode()), Username: username, Project: project, Action: action}
 	// This is only here for testing until i figure a better way
 	// TODO: Find a way to stub this without the ghetto "Filled" hack
 	us.UserAccessResponse = &UserAccessResponse{Filled: false}
@@ -61,7 +68,9 @@ func NewUserService(base string, username string, project string, action string)
 
 // fills UserAccessResponse.RawResponse and pushes json into struct
 func (us *UserService) GetResponse() error {
-	us.Downloader.GetPage()
+	if err := us.Downloader.GetPage(); err != nil {
+		return err
+	}
 	buf := bytes.NewBuffer(us.Downloader.Response)
 	us.UserAccessResponse.RawResponse = buf.Bytes()
 	rdr := bytes.NewReader(us.Downloader.Response)
@@ -82,3 +91,79 @@ func (us *UserService) Can() bool {
 	}
 	return us.UserAccessResponse.Access
 }
+
+type userAccess struct {
+	access  bool
+	message string
+	expires time.Time
+}
+
+// userAccessCache remembers user service responses for Config.UserService.CacheTTL
+type userAccessCache struct {
+	mu      sync.Mutex
+	entries map[string]*userAccess
+}
+
+var accessCache = &userAccessCache{entries: make(map[string]*userAccess)}
+
+func (c *userAccessCache) get(key string) (*userAccess, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ua, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(ua.expires) {
+		delete(c.entries, key)
+		return nil, false
+	}
+	return ua, true
+}
+
+func (c *userAccessCache) set(key string, ua *userAccess) {
+	c.mu.Lock()
+	c.entries[key] = ua
+	c.mu.Unlock()
+}
+
+func (c *userAccessCache) clear() {
+	c.mu.Lock()
+	c.entries = make(map[string]*userAccess)
+	c.mu.Unlock()
+}
+
+// userCan asks the configured user service whether username may perform action on project.
+// Returns the access and the message given by the user service.
+// Any failure to reach the user service denies access.
+func userCan(username, project, action string) (bool, string) {
+	key := fmt.Sprintf("%s\x00%s\x00%s", username, project, action)
+	if ua, ok := accessCache.get(key); ok {
+		return ua.access, ua.message
+	}
+
+	us := NewUserService(Config.UserService.Url, username, project, action)
+	us.Downloader.Timeout = Config.UserService.Timeout
+	if err := us.GetResponse(); err != nil {
+		logger.Log(kv{"fn": "userCan", "project": project, "action": action, "error": err.Error()})
+		return false, "Unable to reach user service"
+	}
+
+	ua := &userAccess{access: us.UserAccessResponse.Access, message: us.UserAccessResponse.Message}
+	if Config.UserService.CacheTTL > 0 {
+		ua.expires = time.Now().Add(Config.UserService.CacheTTL)
+		accessCache.set(key, ua)
+	}
+	return ua.access, ua.message
+}
+
+// userAuthenticates asks the user service whether password is the password of username,
+// sending them as Basic auth with the login action. Logins are never cached.
+func userAuthenticates(username, password string) (bool, error) {
+	us := NewUserService(Config.UserService.Url, username, "", "login")
+	us.Downloader.Auth = &UserServiceAuth{Username: username, Password: password}
+	us.Downloader.Timeout = Config.UserService.Timeout
+	if err := us.GetResponse(); err != nil {
+		return false, err
+	}
+	return us.UserAccessResponse.Access, nil
+}
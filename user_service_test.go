@@ -1,8 +1,13 @@
 package main
 
 import (
-	"github.com/bmizerany/assert"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
+
+	"github.com/bmizerany/assert"
 )
 
 func TestUserServiceTestLoads(t *testing.T) {
@@ -53,6 +58,44 @@ func TestDownloaderAccessWhenFalse(t *testing.T) {
 	assert.Equal(t, "Some Message", us.UserAccessResponse.Message)
 }
 
+func TestUserCanCachesResponses(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		access := r.URL.Query().Get("action") == "download"
+		fmt.Fprintf(w, `{"access": %t, "status": "yay", "message": "%s"}`, access, r.URL.Query().Get("project"))
+	}))
+	defer srv.Close()
+	defer setupUserServiceConfig(srv.URL)()
+
+	access, message := userCan("testuser", "namespace/repo", "download")
+	assert.Equal(t, true, access)
+	assert.Equal(t, "namespace/repo", message)
+
+	access, _ = userCan("testuser", "namespace/repo", "push")
+	assert.Equal(t, false, access)
+
+	userCan("testuser", "namespace/repo", "download")
+	assert.Equal(t, 2, calls)
+}
+
+func TestUserCanDeniesWhenUnreachable(t *testing.T) {
+	defer setupUserServiceConfig("http://127.0.0.1:1")()
+
+	access, _ := userCan("testuser", "namespace/repo", "download")
+	assert.Equal(t, false, access)
+}
+
+func setupUserServiceConfig(url string) func() {
+	old := Config.UserService
+	Config.UserService = &UserServiceConfig{Enabled: true, Url: url, Timeout: time.Second, CacheTTL: time.Minute}
+	accessCache.clear()
+	return func() {
+		Config.UserService = old
+		accessCache.clear()
+	}
+}
+
 func setupUs(access bool) *UserService {
 	d := &Downloader{Response: mock_get_page("http://somewhere.net", access)}
 	uar := &UserAccessResponse{RawResponse: d.Response}